@@ -2,11 +2,15 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/tracing"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -19,6 +23,25 @@ type Adapter struct {
 	logger      core.Logger
 	config      core.Config
 	middlewares []mux.MiddlewareFunc
+
+	// moduleAPIs HTTP маршруты API модулей (строятся при первом запросе к модулю)
+	moduleAPIs map[string]*moduleAPI
+	apiMu      sync.Mutex
+
+	// metrics метрики адаптера (adapter.send_errors)
+	metrics core.Metrics
+
+	// tracer трассировка выполнения команд
+	tracer *tracing.Tracer
+
+	// authorizer проверка доступа к API модулей (SetAuthorizer)
+	authorizer Authorizer
+}
+
+// moduleAPI маршруты API конкретного экземпляра модуля
+type moduleAPI struct {
+	module core.Module
+	router *mux.Router
 }
 
 // NewAdapter создает новый HTTP адаптер
@@ -28,6 +51,7 @@ func NewAdapter(logger core.Logger, config core.Config) *Adapter {
 		logger:      logger,
 		config:      config,
 		middlewares: make([]mux.MiddlewareFunc, 0),
+		moduleAPIs:  make(map[string]*moduleAPI),
 	}
 }
 
@@ -37,6 +61,23 @@ func (a *Adapter) UseRouter(router core.Router) {
 	a.registerRoutes()
 }
 
+// SetMetrics включает метрики адаптера: adapter.send_errors с меткой adapter="http"
+// Если metrics отдает себя по HTTP (metrics.Registry реализует http.Handler),
+// адаптер публикует метрики на GET /metrics в текстовом формате Prometheus
+func (a *Adapter) SetMetrics(metrics core.Metrics) {
+	a.metrics = metrics
+	if handler, ok := metrics.(http.Handler); ok {
+		a.httpRouter.Handle("/metrics", handler).Methods("GET")
+	}
+}
+
+// SetTracer включает трассировку: span "http.execute" на каждый запрос к
+// /api/v1/modules/{module}/execute и дочерний "http.send" на отправку ответа.
+// Заголовок traceparent клиента продолжает его трассу
+func (a *Adapter) SetTracer(tracer *tracing.Tracer) {
+	a.tracer = tracer
+}
+
 // Use добавляет HTTP middleware
 func (a *Adapter) Use(middleware mux.MiddlewareFunc) {
 	a.middlewares = append(a.middlewares, middleware)
@@ -64,27 +105,82 @@ func (a *Adapter) registerRoutes() {
 	// API endpoints для модулей
 	a.httpRouter.HandleFunc("/api/v1/modules", a.handleListModules).Methods("GET")
 	a.httpRouter.HandleFunc("/api/v1/modules/{module}/execute", a.handleExecute).Methods("POST")
+	a.httpRouter.HandleFunc("/api/v1/modules/{module}", a.handleDescribeModule).Methods("GET")
 
-	// Регистрируем специфичные API endpoints модулей
-	for _, module := range a.router.ListModules() {
-		if apiModule, ok := module.(core.APIModule); ok {
-			a.registerModuleAPI(apiModule)
-		}
-	}
+	// Описание бота: модули, маршруты, события, API, middleware и здоровье
+	a.httpRouter.HandleFunc("/api/v1/introspection", a.handleIntrospection).Methods("GET")
+
+	// Health check: отчет по модулям, liveness и readiness probes
+	a.httpRouter.HandleFunc("/health", a.handleLiveness).Methods("GET")
+	a.httpRouter.HandleFunc("/health/live", a.handleLiveness).Methods("GET")
+	a.httpRouter.HandleFunc("/health/ready", a.handleReadiness).Methods("GET")
 
-	// Health check
-	a.httpRouter.HandleFunc("/health", a.handleHealth).Methods("GET")
+	// API endpoints модулей /api/v1/{module}/...
+	// Модуль ищется в роутере на каждый запрос: модули, загруженные
+	// или выгруженные во время работы, сразу появляются и исчезают
+	a.httpRouter.PathPrefix("/api/v1/{module}/").HandlerFunc(a.handleModuleAPI)
 
 	// WebSocket endpoint
 	a.httpRouter.HandleFunc("/ws", a.handleWebSocket)
 }
 
+// handleModuleAPI передает запрос маршрутам API модуля
+func (a *Adapter) handleModuleAPI(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["module"]
+
+	module, ok := a.router.GetModule(name)
+	if !ok {
+		a.sendError(w, fmt.Errorf("module %s not found", name), http.StatusNotFound)
+		return
+	}
+
+	api := a.moduleAPI(module)
+	if api == nil {
+		a.sendError(w, fmt.Errorf("module %s has no API", name), http.StatusNotFound)
+		return
+	}
+
+	api.router.ServeHTTP(w, r)
+}
+
+// moduleAPI возвращает маршруты API модуля
+// Маршруты перестраиваются, если модуль был заменен новой версией
+func (a *Adapter) moduleAPI(module core.Module) *moduleAPI {
+	apiModule, ok := module.(core.APIModule)
+	if !ok {
+		return nil
+	}
+
+	a.apiMu.Lock()
+	defer a.apiMu.Unlock()
+
+	name := module.Name()
+	if api, ok := a.moduleAPIs[name]; ok && api.module == module {
+		return api
+	}
+
+	api := &moduleAPI{
+		module: module,
+		router: mux.NewRouter(),
+	}
+	a.registerModuleAPI(api.router, apiModule)
+	a.moduleAPIs[name] = api
+
+	return api
+}
+
 // registerModuleAPI регистрирует API endpoints модуля
-func (a *Adapter) registerModuleAPI(module core.APIModule) {
+func (a *Adapter) registerModuleAPI(router *mux.Router, module core.APIModule) {
 	for _, handler := range module.APIHandlers() {
+		handler := handler
 		path := fmt.Sprintf("/api/v1/%s%s", module.Name(), handler.Path)
 
-		a.httpRouter.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if status, err := a.authorize(r, module.Name(), handler); err != nil {
+				a.sendError(w, err, status)
+				return
+			}
+
 			// Создаем контекст
 			ctx := a.requestToContext(r)
 
@@ -101,7 +197,8 @@ func (a *Adapter) registerModuleAPI(module core.APIModule) {
 			// Парсим body если есть
 			if r.Body != nil {
 				defer r.Body.Close()
-				if err := json.NewDecoder(r.Body).Decode(&apiReq.Body); err != nil {
+				// Пустое тело (GET, DELETE) - не ошибка
+				if err := json.NewDecoder(r.Body).Decode(&apiReq.Body); err != nil && !errors.Is(err, io.EOF) {
 					a.sendError(w, err, http.StatusBadRequest)
 					return
 				}
@@ -137,6 +234,13 @@ func (a *Adapter) handleListModules(w http.ResponseWriter, r *http.Request) {
 			"version": module.Version(),
 		}
 
+		if provider, ok := module.(core.MetadataProvider); ok {
+			metadata := provider.Metadata()
+			info["description"] = metadata.Description
+			info["author"] = metadata.Author
+			info["tags"] = metadata.Tags
+		}
+
 		// Добавляем информацию о маршрутах
 		routes := module.Routes()
 		info["routes_count"] = len(routes)
@@ -158,6 +262,47 @@ func (a *Adapter) handleListModules(w http.ResponseWriter, r *http.Request) {
 	a.sendJSON(w, result)
 }
 
+// handleDescribeModule возвращает описание модуля: маршруты, события, API, зависимости
+func (a *Adapter) handleDescribeModule(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["module"]
+	if !a.authorizeDescribe(w, r, name) {
+		return
+	}
+
+	introspector, ok := a.router.(core.Introspector)
+	if !ok {
+		a.sendError(w, fmt.Errorf("router does not support introspection"), http.StatusNotImplemented)
+		return
+	}
+
+	// Проверяется здоровье только запрошенного модуля, а не всего бота
+	info, ok := introspector.DescribeModule(name)
+	if !ok {
+		a.sendError(w, fmt.Errorf("module %s not found", name), http.StatusNotFound)
+		return
+	}
+	if status, ok := introspector.ModuleHealth(r.Context(), name); ok {
+		info.Health = status
+	}
+
+	a.sendJSON(w, info)
+}
+
+// handleIntrospection возвращает полное описание бота
+func (a *Adapter) handleIntrospection(w http.ResponseWriter, r *http.Request) {
+	if !a.authorizeDescribe(w, r, "") {
+		return
+	}
+
+	introspector, ok := a.router.(core.Introspector)
+	if !ok {
+		a.sendError(w, fmt.Errorf("router does not support introspection"), http.StatusNotImplemented)
+		return
+	}
+
+	a.sendJSON(w, introspector.Introspect(r.Context()))
+}
+
 // handleExecute обрабатывает выполнение команды модуля
 func (a *Adapter) handleExecute(w http.ResponseWriter, r *http.Request) {
 	// vars := mux.Vars(r)
@@ -169,26 +314,99 @@ func (a *Adapter) handleExecute(w http.ResponseWriter, r *http.Request) {
 		a.sendError(w, err, http.StatusBadRequest)
 		return
 	}
+	for key := range req.Data {
+		if core.IsReservedKey(key) {
+			a.sendError(w, fmt.Errorf("data key %q is reserved", key), http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Создаем контекст из запроса
 	ctx := a.createContext(r, req)
 
+	parent := tracing.ContextWithTraceparent(ctx.Context(), r.Header.Get(tracing.TraceparentHeader))
+	spanCtx, span := a.tracer.Start(parent, "http.execute", tracing.WithAttributes(
+		tracing.Attr("http.path", r.URL.Path),
+		tracing.Attr("user_id", ctx.GetUserID()),
+		tracing.Attr("chat_id", ctx.GetChatID()),
+	))
+	ctx.WithContext(spanCtx)
+	defer span.End()
+
 	// Роутим через основной роутер
 	response := a.router.Route(ctx)
 
+	// Клиент отменил запрос - контекст r.Context() уже отменен, отвечать некому
+	if r.Context().Err() != nil {
+		span.SetStatus(tracing.StatusError, "client disconnected")
+		return
+	}
+
+	_, sendSpan := a.tracer.Start(ctx.Context(), "http.send",
+		tracing.WithAttributes(tracing.Attr("response.type", string(response.Type()))),
+	)
+	defer sendSpan.End()
+
+	// Потоковый ответ отдаем через SSE или chunked transfer
+	if response.Type() == core.ResponseTypeStream {
+		a.sendStreamResponse(w, r, response)
+		return
+	}
+
 	// Конвертируем ответ в HTTP response
 	a.sendModuleResponse(w, response)
 }
 
-// handleHealth обрабатывает health check
-func (a *Adapter) handleHealth(w http.ResponseWriter, r *http.Request) {
-	health := map[string]interface{}{
-		"status":  "healthy",
-		"time":    time.Now().Unix(),
-		"modules": len(a.router.ListModules()),
+// handleLiveness liveness probe (и /health): статус каждого модуля,
+// 503, если процесс нужно перезапустить (модуль не ответил на проверку)
+func (a *Adapter) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	report := a.healthReport(r)
+	status := http.StatusOK
+	if !report.Live {
+		status = http.StatusServiceUnavailable
+	}
+
+	a.sendHealth(w, report, status)
+}
+
+// handleReadiness readiness probe: 503, пока роутер не запущен или есть нездоровые модули
+func (a *Adapter) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	report := a.healthReport(r)
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	a.sendHealth(w, report, status)
+}
+
+// healthReport запрашивает отчет о здоровье у роутера
+// Роутер без core.HealthReporter считается здоровым
+func (a *Adapter) healthReport(r *http.Request) core.HealthReport {
+	if reporter, ok := a.router.(core.HealthReporter); ok {
+		return reporter.Health(r.Context())
 	}
 
-	a.sendJSON(w, health)
+	modules := make(map[string]core.HealthStatus)
+	for _, module := range a.router.ListModules() {
+		modules[module.Name()] = core.HealthStatus{Healthy: true, Message: "ok"}
+	}
+
+	return core.HealthReport{
+		Status:  "healthy",
+		Live:    true,
+		Ready:   true,
+		Modules: modules,
+		Time:    time.Now().Unix(),
+	}
+}
+
+// sendHealth отправляет отчет о здоровье
+func (a *Adapter) sendHealth(w http.ResponseWriter, report core.HealthReport, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
 }
 
 // handleWebSocket обрабатывает WebSocket соединения
@@ -233,6 +451,13 @@ func (a *Adapter) requestToContext(r *http.Request) core.UniversalContext {
 func (a *Adapter) createContext(r *http.Request, req ExecuteRequest) core.UniversalContext {
 	ctx := a.requestToContext(r)
 
+	// Дополнительные данные клиента - первыми, чтобы не перекрыть значения адаптера
+	for k, v := range req.Data {
+		if !core.IsReservedKey(k) {
+			ctx.Set(k, v)
+		}
+	}
+
 	// Устанавливаем данные из запроса
 	if baseCtx, ok := ctx.(*core.BaseContext); ok {
 		baseCtx.SetUserID(req.UserID)
@@ -240,6 +465,16 @@ func (a *Adapter) createContext(r *http.Request, req ExecuteRequest) core.Univer
 		baseCtx.SetText(req.Text)
 		baseCtx.SetMessageID(req.MessageID)
 
+		// Клиент повторяет POST с тем же Idempotency-Key
+		if key := r.Header.Get("Idempotency-Key"); key != "" {
+			baseCtx.Set(core.IdempotencyKey, fmt.Sprintf("http:%d:%s", req.UserID, key))
+		}
+
+		// Идентификатор запроса для логов (заголовок ставит RequestIDMiddleware или прокси)
+		if id := r.Header.Get("X-Request-ID"); id != "" {
+			baseCtx.Set(core.RequestIDKey, id)
+		}
+
 		// Устанавливаем тип
 		if req.IsCallback {
 			baseCtx.SetIsCallback(true)
@@ -253,11 +488,6 @@ func (a *Adapter) createContext(r *http.Request, req ExecuteRequest) core.Univer
 		ctx.SetParam(k, v)
 	}
 
-	// Устанавливаем дополнительные данные
-	for k, v := range req.Data {
-		ctx.Set(k, v)
-	}
-
 	return ctx
 }
 
@@ -299,6 +529,88 @@ func (a *Adapter) sendModuleResponse(w http.ResponseWriter, response core.Respon
 	a.sendJSON(w, result)
 }
 
+// sendStreamResponse отправляет потоковый ответ модуля.
+// Клиенты с Accept: text/event-stream получают Server-Sent Events,
+// остальные - текст частями через chunked transfer encoding
+func (a *Adapter) sendStreamResponse(w http.ResponseWriter, r *http.Request, response core.Response) {
+	streamResp, ok := response.(core.StreamResponse)
+	if !ok || streamResp.Stream() == nil {
+		a.sendError(w, fmt.Errorf("invalid stream response"), http.StatusInternalServerError)
+		return
+	}
+
+	stream := streamResp.Stream()
+	defer core.DrainStream(stream)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		// Потоковая передача не поддерживается - собираем ответ целиком
+		text := response.Content().Text
+		for chunk := range stream {
+			if chunk.Err != nil {
+				a.sendError(w, chunk.Err, http.StatusInternalServerError)
+				return
+			}
+			text += chunk.Text
+		}
+		a.sendModuleResponse(w, core.NewMessage(text).WithParseMode(response.Content().ParseMode))
+		return
+	}
+
+	sse := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	write := func(event string, data interface{}) {
+		if sse {
+			payload, _ := json.Marshal(data)
+			if event != "" {
+				fmt.Fprintf(w, "event: %s\n", event)
+			}
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+		} else if chunk, ok := data.(core.StreamChunk); ok {
+			fmt.Fprint(w, chunk.Text)
+		}
+		flusher.Flush()
+	}
+
+	if text := response.Content().Text; text != "" {
+		write("", core.StreamChunk{Text: text})
+	}
+
+	for {
+		select {
+		case chunk, ok := <-stream:
+			if !ok {
+				if sse {
+					write("end", map[string]interface{}{})
+				}
+				return
+			}
+
+			if chunk.Err != nil {
+				a.logger.Error("Stream failed", "error", chunk.Err, "path", r.URL.Path)
+				if sse {
+					write("error", ErrorResponse{Error: chunk.Err.Error()})
+				}
+				return
+			}
+
+			write("", chunk)
+
+		case <-r.Context().Done():
+			// Клиент отключился
+			return
+		}
+	}
+}
+
 // convertKeyboard конвертирует клавиатуру в DTO
 func (a *Adapter) convertKeyboard(kb core.Keyboard) interface{} {
 	buttons := kb.Buttons()
@@ -372,7 +684,9 @@ func (a *Adapter) recoveryMiddleware(next http.Handler) http.Handler {
 
 func (a *Adapter) sendJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		a.sendFailed(err)
+	}
 }
 
 func (a *Adapter) sendError(w http.ResponseWriter, err error, status int) {
@@ -397,7 +711,17 @@ func (a *Adapter) sendResponse(w http.ResponseWriter, resp core.APIResponse) {
 
 	// Send body
 	if resp.Body != nil {
-		json.NewEncoder(w).Encode(resp.Body)
+		if err := json.NewEncoder(w).Encode(resp.Body); err != nil {
+			a.sendFailed(err)
+		}
+	}
+}
+
+// sendFailed логирует ошибку отправки ответа и учитывает ее в метриках
+func (a *Adapter) sendFailed(err error) {
+	a.logger.Error("Failed to send response", "error", err)
+	if a.metrics != nil {
+		a.metrics.Counter("adapter.send_errors", 1, "adapter", "http")
 	}
 }
 
@@ -431,3 +755,10 @@ func (w *responseWriter) WriteHeader(code int) {
 	w.statusCode = code
 	w.ResponseWriter.WriteHeader(code)
 }
+
+// Flush поддерживает потоковую передачу через обертку
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
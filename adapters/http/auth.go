@@ -0,0 +1,91 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// ErrUnauthorized запрос без действительных учетных данных (HTTP 401)
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrForbidden учетные данные действительны, но доступа к endpoint нет (HTTP 403)
+var ErrForbidden = errors.New("forbidden")
+
+// Authorizer проверяет запрос к API модуля (/api/v1/{module}/...) до вызова handler.
+// Ошибка, оборачивающая ErrForbidden, отвечает 403, любая другая - 401.
+// Описание бота (/api/v1/introspection, /api/v1/modules/{module}) проверяется так же,
+// как admin endpoint: handler - describeAPI, module - описываемый модуль или пусто
+type Authorizer func(r *http.Request, module string, handler core.APIHandler) error
+
+// describeAPI endpoint описания бота: раскрывает маршруты, правила доступа,
+// лимиты и пути admin API, поэтому доступен только через Authorizer
+var describeAPI = core.APIHandler{
+	Method:      http.MethodGet,
+	Path:        "/introspection",
+	Description: "Описание модулей, маршрутов, API и middleware",
+	Admin:       true,
+}
+
+// SetAuthorizer включает проверку запросов к API модулей.
+// Без Authorizer endpoints с APIHandler.Admin отклоняются: адаптер не
+// открывает управление ботом без авторизации
+func (a *Adapter) SetAuthorizer(authorizer Authorizer) {
+	a.authorizer = authorizer
+}
+
+// TokenAuthorizer пропускает запросы с заголовком "Authorization: Bearer <token>",
+// где token - один из tokens. Токены сравниваются за постоянное время
+func TokenAuthorizer(tokens ...string) Authorizer {
+	allowed := make([][]byte, 0, len(tokens))
+	for _, token := range tokens {
+		if token != "" {
+			allowed = append(allowed, []byte(token))
+		}
+	}
+
+	return func(r *http.Request, module string, handler core.APIHandler) error {
+		header := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return ErrUnauthorized
+		}
+
+		for _, candidate := range allowed {
+			if subtle.ConstantTimeCompare([]byte(token), candidate) == 1 {
+				return nil
+			}
+		}
+		return ErrUnauthorized
+	}
+}
+
+// authorize проверяет доступ к API модуля и возвращает HTTP статус отказа
+func (a *Adapter) authorize(r *http.Request, module string, handler core.APIHandler) (int, error) {
+	if a.authorizer == nil {
+		if handler.Admin {
+			return http.StatusUnauthorized, errors.New("admin API is disabled: authorizer is not configured")
+		}
+		return 0, nil
+	}
+
+	if err := a.authorizer(r, module, handler); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return http.StatusForbidden, err
+		}
+		return http.StatusUnauthorized, err
+	}
+	return 0, nil
+}
+
+// authorizeDescribe проверяет доступ к описанию бота и отвечает ошибкой при отказе
+func (a *Adapter) authorizeDescribe(w http.ResponseWriter, r *http.Request, module string) bool {
+	if status, err := a.authorize(r, module, describeAPI); err != nil {
+		a.sendError(w, err, status)
+		return false
+	}
+	return true
+}
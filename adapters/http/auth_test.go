@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/gorilla/mux"
+)
+
+// nopLogger core.Logger без вывода для тестов
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, fields ...interface{})                {}
+func (nopLogger) Info(msg string, fields ...interface{})                 {}
+func (nopLogger) Warn(msg string, fields ...interface{})                 {}
+func (nopLogger) Error(msg string, fields ...interface{})                {}
+func (nopLogger) Fatal(msg string, fields ...interface{})                {}
+func (l nopLogger) WithField(key string, value interface{}) core.Logger  { return l }
+func (l nopLogger) WithFields(fields map[string]interface{}) core.Logger { return l }
+func (l nopLogger) WithError(err error) core.Logger                      { return l }
+
+// apiModule модуль с одним открытым и одним административным endpoint
+type apiModule struct{}
+
+func (apiModule) Name() string                      { return "shop" }
+func (apiModule) Version() string                   { return "1.0.0" }
+func (apiModule) Routes() []core.RoutePattern       { return nil }
+func (apiModule) Init(deps core.Dependencies) error { return nil }
+func (apiModule) Start(ctx context.Context) error   { return nil }
+func (apiModule) Stop(ctx context.Context) error    { return nil }
+func (apiModule) ok(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	return core.APIResponse{Status: http.StatusOK}, nil
+}
+
+func (m apiModule) APIHandlers() []core.APIHandler {
+	return []core.APIHandler{
+		{Method: "GET", Path: "/items", Handler: m.ok},
+		{Method: "POST", Path: "/prices", Handler: m.ok, Admin: true},
+	}
+}
+
+func TestModuleAPIAuthorization(t *testing.T) {
+	forbidPrices := func(r *http.Request, module string, handler core.APIHandler) error {
+		if handler.Admin {
+			return fmt.Errorf("%s%s: %w", module, handler.Path, ErrForbidden)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		authorizer Authorizer
+		method     string
+		path       string
+		token      string
+		want       int
+	}{
+		{name: "public without authorizer", method: "GET", path: "/items", want: http.StatusOK},
+		{name: "admin without authorizer", method: "POST", path: "/prices", token: "secret", want: http.StatusUnauthorized},
+		{name: "admin with token", authorizer: TokenAuthorizer("secret"), method: "POST", path: "/prices", token: "secret", want: http.StatusOK},
+		{name: "admin with wrong token", authorizer: TokenAuthorizer("secret"), method: "POST", path: "/prices", token: "guess", want: http.StatusUnauthorized},
+		{name: "admin without token", authorizer: TokenAuthorizer("secret"), method: "POST", path: "/prices", want: http.StatusUnauthorized},
+		{name: "public requires token", authorizer: TokenAuthorizer("secret"), method: "GET", path: "/items", want: http.StatusUnauthorized},
+		{name: "empty token is never valid", authorizer: TokenAuthorizer(""), method: "GET", path: "/items", want: http.StatusUnauthorized},
+		{name: "forbidden", authorizer: forbidPrices, method: "POST", path: "/prices", want: http.StatusForbidden},
+		{name: "custom authorizer allows public", authorizer: forbidPrices, method: "GET", path: "/items", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewAdapter(nopLogger{}, nil)
+			adapter.SetAuthorizer(tt.authorizer)
+
+			router := mux.NewRouter()
+			adapter.registerModuleAPI(router, apiModule{})
+
+			req := httptest.NewRequest(tt.method, "/api/v1/shop"+tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDescribeAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		authorizer Authorizer
+		path       string
+		token      string
+		want       int
+	}{
+		{name: "introspection without authorizer", path: "/api/v1/introspection", token: "secret", want: http.StatusUnauthorized},
+		{name: "introspection with wrong token", authorizer: TokenAuthorizer("secret"), path: "/api/v1/introspection", token: "guess", want: http.StatusUnauthorized},
+		// Без роутера с core.Introspector проверка пройдена, но описывать нечего
+		{name: "introspection with token", authorizer: TokenAuthorizer("secret"), path: "/api/v1/introspection", token: "secret", want: http.StatusNotImplemented},
+		{name: "module without authorizer", path: "/api/v1/modules/shop", want: http.StatusUnauthorized},
+		{name: "module with token", authorizer: TokenAuthorizer("secret"), path: "/api/v1/modules/shop", token: "secret", want: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewAdapter(nopLogger{}, nil)
+			adapter.SetAuthorizer(tt.authorizer)
+			adapter.registerRoutes()
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			adapter.httpRouter.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
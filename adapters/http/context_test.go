@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+func TestCreateContextKeepsAdapterValues(t *testing.T) {
+	adapter := NewAdapter(nopLogger{}, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/modules/shop/execute", nil)
+	req.Header.Set("Idempotency-Key", "order-42")
+	req.Header.Set("X-Request-ID", "req-1")
+
+	ctx := adapter.createContext(req, ExecuteRequest{
+		UserID: 7,
+		Text:   "/buy",
+		Data: map[string]interface{}{
+			core.IdempotencyKey: "http:8:order-42",
+			core.RequestIDKey:   "forged",
+			"coupon":            "SPRING",
+		},
+	})
+
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{key: core.IdempotencyKey, want: "http:7:order-42"},
+		{key: core.RequestIDKey, want: "req-1"},
+		{key: "coupon", want: "SPRING"},
+	}
+	for _, tt := range tests {
+		if got, _ := ctx.Get(tt.key); got != tt.want {
+			t.Errorf("ctx.Get(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteRejectsReservedData(t *testing.T) {
+	adapter := NewAdapter(nopLogger{}, nil)
+
+	body := `{"user_id": 7, "text": "/buy", "data": {"idempotency_key": "http:8:order-42"}}`
+	req := httptest.NewRequest("POST", "/api/v1/modules/shop/execute", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	adapter.handleExecute(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+	}
+}
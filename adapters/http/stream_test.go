@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+func TestSendStreamResponseReleasesProducer(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel bool
+		fail   bool
+	}{
+		{name: "client disconnected", cancel: true},
+		{name: "stream error", fail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := make(chan core.StreamChunk)
+			finished := make(chan struct{})
+
+			go func() {
+				defer close(finished)
+				defer close(stream)
+				if tt.fail {
+					stream <- core.StreamChunk{Err: errors.New("generation failed")}
+				}
+				for i := 0; i < 10; i++ {
+					stream <- core.StreamChunk{Text: "part"}
+				}
+			}()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancel {
+				cancel()
+			} else {
+				defer cancel()
+			}
+
+			req := httptest.NewRequest("POST", "/api/v1/modules/chat/execute", nil).WithContext(ctx)
+			req.Header.Set("Accept", "text/event-stream")
+
+			adapter := NewAdapter(nopLogger{}, nil)
+			adapter.sendStreamResponse(httptest.NewRecorder(), req, core.NewStreamResponse(stream))
+
+			select {
+			case <-finished:
+			case <-time.After(time.Second):
+				t.Fatal("producer is blocked after the adapter stopped reading")
+			}
+		})
+	}
+}
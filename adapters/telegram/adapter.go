@@ -4,26 +4,45 @@ import (
 	"context"
 	"fmt"
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/tracing"
 	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // Adapter адаптер для Telegram
 type Adapter struct {
-	bot    *tgbotapi.BotAPI
-	router core.Router
-	logger core.Logger
-	config core.Config
+	bot     *tgbotapi.BotAPI
+	router  core.Router
+	logger  core.Logger
+	config  core.Config
+	split   SplitConfig
+	tracker MessageTracker
+	admins  *adminCache
+
+	// streamInterval интервал редактирования потоковых сообщений
+	streamInterval time.Duration
+
+	// metrics метрики адаптера (adapter.send_errors)
+	metrics core.Metrics
+
+	// tracer трассировка обработки update
+	tracer *tracing.Tracer
 }
 
 // NewAdapter создает новый Telegram адаптер
 func NewAdapter(bot *tgbotapi.BotAPI, logger core.Logger, config core.Config) *Adapter {
 	return &Adapter{
-		bot:    bot,
-		logger: logger,
-		config: config,
+		bot:     bot,
+		logger:  logger,
+		config:  config,
+		split:   DefaultSplitConfig(),
+		tracker: NewMemoryMessageTracker(),
+		admins:  newAdminCache(DefaultAdminCacheTTL),
+
+		streamInterval: DefaultStreamInterval,
 	}
 }
 
@@ -32,6 +51,40 @@ func (a *Adapter) UseRouter(router core.Router) {
 	a.router = router
 }
 
+// SetSplitConfig устанавливает настройки разбиения длинных сообщений
+func (a *Adapter) SetSplitConfig(cfg SplitConfig) {
+	if cfg.MaxLength <= 0 || cfg.MaxLength > MaxMessageLength {
+		cfg.MaxLength = MaxMessageLength
+	}
+	if cfg.DocumentName == "" {
+		cfg.DocumentName = "message.txt"
+	}
+	a.split = cfg
+}
+
+// SetMessageTracker устанавливает трекер сообщений меню
+func (a *Adapter) SetMessageTracker(tracker MessageTracker) {
+	a.tracker = tracker
+}
+
+// SetMetrics включает метрики адаптера: adapter.send_errors с меткой adapter="telegram"
+func (a *Adapter) SetMetrics(metrics core.Metrics) {
+	a.metrics = metrics
+}
+
+// SetTracer включает трассировку: span "telegram.update" на каждый update
+// (родитель span роутера и обработчика) и дочерний "telegram.send" на отправку ответа
+func (a *Adapter) SetTracer(tracer *tracing.Tracer) {
+	a.tracer = tracer
+}
+
+// SetStreamInterval устанавливает интервал редактирования потоковых сообщений
+func (a *Adapter) SetStreamInterval(interval time.Duration) {
+	if interval > 0 {
+		a.streamInterval = interval
+	}
+}
+
 // HandleUpdate обрабатывает Telegram update
 func (a *Adapter) HandleUpdate(update tgbotapi.Update) {
 	if a.router == nil {
@@ -42,12 +95,41 @@ func (a *Adapter) HandleUpdate(update tgbotapi.Update) {
 	// Конвертируем update в UniversalContext
 	ctx := a.updateToContext(&update)
 
+	// Команда адресована другому боту в группе
+	if addressed, ok := ctx.Get(core.AddressedToOtherBotKey); ok && addressed.(bool) {
+		a.logger.Debug("Skipping command addressed to another bot", "chat", ctx.GetChatID())
+		return
+	}
+
+	spanCtx, span := a.tracer.Start(ctx.Context(), "telegram.update", tracing.WithAttributes(
+		tracing.Attr("update_id", update.UpdateID),
+		tracing.Attr("user_id", ctx.GetUserID()),
+		tracing.Attr("chat_id", ctx.GetChatID()),
+	))
+	ctx.WithContext(spanCtx)
+	defer span.End()
+
 	// Роутим через основной роутер
 	response := a.router.Route(ctx)
 
 	// Отправляем ответ
-	if err := a.sendResponse(ctx, response); err != nil {
-		a.logger.Error("Failed to send response", "error", err)
+	_, sendSpan := a.tracer.Start(ctx.Context(), "telegram.send",
+		tracing.WithAttributes(tracing.Attr("response.type", string(response.Type()))),
+	)
+	err := a.sendResponse(ctx, response)
+	sendSpan.RecordError(err)
+	sendSpan.End()
+
+	if err != nil {
+		core.LoggerFrom(ctx, a.logger).Error("Failed to send response", "error", err)
+		a.sendFailed()
+	}
+}
+
+// sendFailed учитывает ошибку отправки в метриках
+func (a *Adapter) sendFailed() {
+	if a.metrics != nil {
+		a.metrics.Counter("adapter.send_errors", 1, "adapter", "telegram")
 	}
 }
 
@@ -61,21 +143,42 @@ func (a *Adapter) updateToContext(update *tgbotapi.Update) core.UniversalContext
 	// Сохраняем оригинальный update
 	ctx.SetOriginal(update)
 
+	// Telegram повторно доставляет update после таймаута вебхука с тем же update_id
+	ctx.Set(core.IdempotencyKey, a.updateKey(update.UpdateID))
+
+	// Индикаторы действий через sendChatAction
+	ctx.SetChatActionSender(func(action core.ChatAction) error {
+		_, err := a.bot.Request(tgbotapi.NewChatAction(ctx.GetChatID(), string(action)))
+		return err
+	})
+
 	// Обрабатываем разные типы update
 	if update.Message != nil {
 		a.fillFromMessage(ctx, update.Message)
 		ctx.SetIsCommand(update.Message.IsCommand())
+		if !a.fillChatInfo(ctx, update.Message) {
+			ctx.Set(core.AddressedToOtherBotKey, true)
+		}
 	} else if update.CallbackQuery != nil {
 		a.fillFromCallbackQuery(ctx, update.CallbackQuery)
 		ctx.SetIsCallback(true)
 	} else if update.EditedMessage != nil {
 		a.fillFromMessage(ctx, update.EditedMessage)
+		a.fillChatInfo(ctx, update.EditedMessage)
 		ctx.Set("edited", true)
 	}
 
 	return ctx
 }
 
+// updateKey ключ идемпотентности update (update_id уникален в пределах бота)
+func (a *Adapter) updateKey(updateID int) string {
+	if a.bot != nil {
+		return fmt.Sprintf("telegram:%d:%d", a.bot.Self.ID, updateID)
+	}
+	return fmt.Sprintf("telegram:%d", updateID)
+}
+
 // fillFromMessage заполняет контекст из сообщения
 func (a *Adapter) fillFromMessage(ctx *core.BaseContext, msg *tgbotapi.Message) {
 	ctx.SetUserID(msg.From.ID)
@@ -90,6 +193,22 @@ func (a *Adapter) fillFromMessage(ctx *core.BaseContext, msg *tgbotapi.Message)
 
 	ctx.SetText(msg.Text)
 
+	// Пересланное сообщение
+	if msg.ForwardDate != 0 {
+		ctx.Set(core.ForwardedKey, true)
+	}
+
+	// Ссылки (включая скрытые text_link)
+	links := 0
+	for _, entity := range msg.Entities {
+		if entity.Type == "url" || entity.Type == "text_link" {
+			links++
+		}
+	}
+	if links > 0 {
+		ctx.Set(core.LinksKey, links)
+	}
+
 	// Обрабатываем медиа
 	if msg.Photo != nil && len(msg.Photo) > 0 {
 		media := make([]core.Media, 0, len(msg.Photo))
@@ -131,8 +250,17 @@ func (a *Adapter) fillFromCallbackQuery(ctx *core.BaseContext, query *tgbotapi.C
 		ctx.SetMessageID(strconv.Itoa(query.Message.MessageID))
 		// MessageThreadID доступен только в новых версиях API
 		// ctx.SetThreadID(strconv.Itoa(query.Message.MessageThreadID))
+		ctx.SetChatType(core.ChatType(query.Message.Chat.Type))
+
+		chatID, userID := query.Message.Chat.ID, query.From.ID
+		ctx.SetAdminChecker(func() (bool, error) {
+			return a.isChatAdmin(chatID, userID)
+		})
 	}
 
+	// Нажатие кнопки всегда адресовано боту
+	ctx.SetAddressed(true)
+
 	ctx.SetUsername(query.From.UserName)
 	ctx.SetFirstName(query.From.FirstName)
 	ctx.SetLastName(query.From.LastName)
@@ -162,6 +290,12 @@ func (a *Adapter) sendResponse(ctx core.UniversalContext, response core.Response
 	case core.ResponseTypeEdit:
 		return a.editMessage(ctx, response)
 
+	case core.ResponseTypeUpsert:
+		return a.upsertMessage(ctx, response)
+
+	case core.ResponseTypeStream:
+		return a.streamMessage(ctx, response)
+
 	case core.ResponseTypeDelete:
 		return a.deleteMessage(ctx, response)
 
@@ -183,56 +317,108 @@ func (a *Adapter) sendResponse(ctx core.UniversalContext, response core.Response
 
 // sendMessage отправляет новое сообщение
 func (a *Adapter) sendMessage(ctx core.UniversalContext, response core.Response) error {
-	content := response.Content()
-	options := response.Options()
+	_, err := a.sendText(ctx, response.Content(), response.Options())
+	return err
+}
 
-	msg := tgbotapi.NewMessage(ctx.GetChatID(), content.Text)
+// sendText отправляет текст и возвращает ID последнего отправленного сообщения
+// Длинный текст разбивается на части, клавиатура прикрепляется к последней.
+// Ответ с медиа отправляется через sendMedia
+func (a *Adapter) sendText(ctx core.UniversalContext, content core.MessageContent, options core.ResponseOptions) (int, error) {
+	if len(content.Media) > 0 {
+		return a.sendMedia(ctx, content, options)
+	}
 
-	// Устанавливаем parse mode
-	switch content.ParseMode {
-	case core.ParseModeHTML:
-		msg.ParseMode = tgbotapi.ModeHTML
-	case core.ParseModeMarkdown:
-		msg.ParseMode = tgbotapi.ModeMarkdownV2
+	chunks := SplitText(content.Text, a.split.MaxLength, content.ParseMode)
+	if len(chunks) == 0 {
+		return 0, fmt.Errorf("empty message text")
 	}
 
-	// Reply to message
+	// Слишком много частей - отправляем документом
+	if a.split.DocumentFallback && a.split.MaxChunks > 0 && len(chunks) > a.split.MaxChunks {
+		sent, err := a.sendAsDocument(ctx, content, options)
+		if err != nil {
+			return 0, err
+		}
+		a.deleteUserMessage(ctx, options)
+		return sent.MessageID, nil
+	}
+
+	var sent tgbotapi.Message
+	for i, chunk := range chunks {
+		msg := tgbotapi.NewMessage(ctx.GetChatID(), chunk)
+		msg.ParseMode = parseMode(content.ParseMode)
+
+		// Reply to message только для первой части
+		if i == 0 && options.ReplyToMessageID != "" {
+			if id, err := strconv.Atoi(options.ReplyToMessageID); err == nil {
+				msg.ReplyToMessageID = id
+			}
+		}
+
+		// Disable notification
+		msg.DisableNotification = options.DisableNotification
+
+		// Disable web preview
+		msg.DisableWebPagePreview = options.DisableWebPreview
+
+		// Клавиатура только у последней части
+		if i == len(chunks)-1 && content.Keyboard != nil {
+			msg.ReplyMarkup = a.buildKeyboard(content.Keyboard)
+		}
+
+		var err error
+		if sent, err = a.bot.Send(msg); err != nil {
+			if len(chunks) > 1 {
+				return 0, fmt.Errorf("failed to send chunk %d/%d: %w", i+1, len(chunks), err)
+			}
+			return 0, err
+		}
+	}
+
+	a.deleteUserMessage(ctx, options)
+
+	return sent.MessageID, nil
+}
+
+// sendAsDocument отправляет текст сообщения .txt документом
+func (a *Adapter) sendAsDocument(ctx core.UniversalContext, content core.MessageContent, options core.ResponseOptions) (tgbotapi.Message, error) {
+	doc := tgbotapi.NewDocument(ctx.GetChatID(), tgbotapi.FileBytes{
+		Name:  a.split.DocumentName,
+		Bytes: []byte(content.Text),
+	})
+
 	if options.ReplyToMessageID != "" {
 		if id, err := strconv.Atoi(options.ReplyToMessageID); err == nil {
-			msg.ReplyToMessageID = id
+			doc.ReplyToMessageID = id
 		}
 	}
 
-	// Disable notification
-	msg.DisableNotification = options.DisableNotification
-
-	// Disable web preview
-	msg.DisableWebPagePreview = options.DisableWebPreview
+	doc.DisableNotification = options.DisableNotification
 
-	// Добавляем клавиатуру
 	if content.Keyboard != nil {
-		msg.ReplyMarkup = a.buildKeyboard(content.Keyboard)
+		doc.ReplyMarkup = a.buildKeyboard(content.Keyboard)
 	}
 
-	// Отправляем
-	_, err := a.bot.Send(msg)
+	return a.bot.Send(doc)
+}
 
-	// Удаляем сообщение пользователя если нужно
-	if err == nil && options.DeleteUserMessage {
-		if msgID := ctx.GetMessageID(); msgID != "" {
-			if id, err := strconv.Atoi(msgID); err == nil {
-				deleteMsg := tgbotapi.NewDeleteMessage(ctx.GetChatID(), id)
-				a.bot.Send(deleteMsg)
-			}
-		}
+// deleteUserMessage удаляет сообщение пользователя если нужно
+func (a *Adapter) deleteUserMessage(ctx core.UniversalContext, options core.ResponseOptions) {
+	if !options.DeleteUserMessage {
+		return
 	}
 
-	return err
+	if msgID := ctx.GetMessageID(); msgID != "" {
+		if id, err := strconv.Atoi(msgID); err == nil {
+			deleteMsg := tgbotapi.NewDeleteMessage(ctx.GetChatID(), id)
+			a.bot.Send(deleteMsg)
+		}
+	}
 }
 
 // editMessage редактирует сообщение
 func (a *Adapter) editMessage(ctx core.UniversalContext, response core.Response) error {
-	content := response.Content()
 	options := response.Options()
 
 	msgID, err := strconv.Atoi(options.MessageToEditID)
@@ -240,14 +426,41 @@ func (a *Adapter) editMessage(ctx core.UniversalContext, response core.Response)
 		return fmt.Errorf("invalid message ID: %w", err)
 	}
 
-	edit := tgbotapi.NewEditMessageText(ctx.GetChatID(), msgID, content.Text)
+	err = a.editText(ctx, msgID, response.Content(), options)
+	if isNotModified(err) {
+		return nil
+	}
+	return err
+}
 
-	// Устанавливаем parse mode
-	switch content.ParseMode {
-	case core.ParseModeHTML:
-		edit.ParseMode = tgbotapi.ModeHTML
-	case core.ParseModeMarkdown:
-		edit.ParseMode = tgbotapi.ModeMarkdownV2
+// editText редактирует текст сообщения
+// Остаток длинного текста досылается новыми сообщениями
+func (a *Adapter) editText(ctx core.UniversalContext, msgID int, content core.MessageContent, options core.ResponseOptions) error {
+	chunks := SplitText(content.Text, a.split.MaxLength, content.ParseMode)
+	if len(chunks) == 0 {
+		return fmt.Errorf("empty message text")
+	}
+
+	edit := tgbotapi.NewEditMessageText(ctx.GetChatID(), msgID, chunks[0])
+	edit.ParseMode = parseMode(content.ParseMode)
+
+	if len(chunks) > 1 {
+		if _, err := a.bot.Send(edit); err != nil && !isNotModified(err) {
+			return err
+		}
+
+		for i, chunk := range chunks[1:] {
+			msg := tgbotapi.NewMessage(ctx.GetChatID(), chunk)
+			msg.ParseMode = edit.ParseMode
+			msg.DisableNotification = options.DisableNotification
+			if i == len(chunks)-2 && content.Keyboard != nil {
+				msg.ReplyMarkup = a.buildKeyboard(content.Keyboard)
+			}
+			if _, err := a.bot.Send(msg); err != nil {
+				return fmt.Errorf("failed to send chunk %d/%d: %w", i+2, len(chunks), err)
+			}
+		}
+		return nil
 	}
 
 	// Добавляем клавиатуру
@@ -258,10 +471,59 @@ func (a *Adapter) editMessage(ctx core.UniversalContext, response core.Response)
 		}
 	}
 
-	_, err = a.bot.Send(edit)
+	_, err := a.bot.Send(edit)
 	return err
 }
 
+// upsertMessage редактирует сообщение меню, а если это невозможно - отправляет новое
+func (a *Adapter) upsertMessage(ctx core.UniversalContext, response core.Response) error {
+	content := response.Content()
+	options := response.Options()
+	chatID := ctx.GetChatID()
+
+	if msgID, ok := a.menuMessageID(ctx, options); ok {
+		err := a.editText(ctx, msgID, content, options)
+		if err == nil || isNotModified(err) {
+			a.tracker.TrackMenuMessage(chatID, msgID)
+			return nil
+		}
+
+		// Сообщение слишком старое, удалено или содержит медиа - отправляем новое
+		a.logger.Debug("Upsert edit failed, sending new message",
+			"chat", chatID,
+			"message", msgID,
+			"error", err,
+		)
+		a.tracker.Forget(chatID)
+	}
+
+	sentID, err := a.sendText(ctx, content, options)
+	if err != nil {
+		return err
+	}
+
+	a.tracker.TrackMenuMessage(chatID, sentID)
+	return nil
+}
+
+// menuMessageID определяет сообщение для редактирования:
+// явно указанное, сообщение с нажатой кнопкой или последнее меню в чате
+func (a *Adapter) menuMessageID(ctx core.UniversalContext, options core.ResponseOptions) (int, bool) {
+	if options.MessageToEditID != "" {
+		if id, err := strconv.Atoi(options.MessageToEditID); err == nil {
+			return id, true
+		}
+	}
+
+	if ctx.IsCallback() {
+		if id, err := strconv.Atoi(ctx.GetMessageID()); err == nil {
+			return id, true
+		}
+	}
+
+	return a.tracker.LastMenuMessage(ctx.GetChatID())
+}
+
 // deleteMessage удаляет сообщение
 func (a *Adapter) deleteMessage(ctx core.UniversalContext, response core.Response) error {
 	options := response.Options()
@@ -383,6 +645,23 @@ func (a *Adapter) buildReplyKeyboard(keyboard core.Keyboard) tgbotapi.ReplyKeybo
 
 // Helper functions
 
+// parseMode конвертирует режим парсинга в формат Telegram
+func parseMode(mode core.ParseMode) string {
+	switch mode {
+	case core.ParseModeHTML:
+		return tgbotapi.ModeHTML
+	case core.ParseModeMarkdown:
+		return tgbotapi.ModeMarkdownV2
+	default:
+		return ""
+	}
+}
+
+// isNotModified проверяет ошибку "message is not modified" (контент не изменился)
+func isNotModified(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "message is not modified")
+}
+
 // parseCallbackData парсит callback data
 func parseCallbackData(data string) (route string, params map[string]string) {
 	params = make(map[string]string)
@@ -0,0 +1,173 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/andranikuz/botkit/core"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DefaultAdminCacheTTL время кеширования статуса администратора
+const DefaultAdminCacheTTL = 5 * time.Minute
+
+// adminCache кеш статусов администраторов чатов
+type adminCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[adminCacheKey]adminCacheEntry
+}
+
+type adminCacheKey struct {
+	chatID int64
+	userID int64
+}
+
+type adminCacheEntry struct {
+	isAdmin   bool
+	expiresAt time.Time
+}
+
+// newAdminCache создает кеш статусов администраторов
+func newAdminCache(ttl time.Duration) *adminCache {
+	return &adminCache{
+		ttl:     ttl,
+		entries: make(map[adminCacheKey]adminCacheEntry),
+	}
+}
+
+// get возвращает закешированный статус
+func (c *adminCache) get(chatID, userID int64) (bool, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[adminCacheKey{chatID, userID}]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return false, false
+	}
+	return entry.isAdmin, true
+}
+
+// set сохраняет статус и удаляет устаревшие записи
+func (c *adminCache) set(chatID, userID int64, isAdmin bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.entries[adminCacheKey{chatID, userID}] = adminCacheEntry{
+		isAdmin:   isAdmin,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// isChatAdmin проверяет права администратора через getChatMember с кешированием
+func (a *Adapter) isChatAdmin(chatID, userID int64) (bool, error) {
+	if isAdmin, ok := a.admins.get(chatID, userID); ok {
+		return isAdmin, nil
+	}
+
+	if a.bot == nil {
+		return false, fmt.Errorf("bot is not configured")
+	}
+
+	member, err := a.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: chatID,
+			UserID: userID,
+		},
+	})
+	if err != nil {
+		a.logger.Warn("Failed to get chat member", "chat", chatID, "user", userID, "error", err)
+		return false, err
+	}
+
+	isAdmin := member.IsAdministrator() || member.IsCreator()
+	a.admins.set(chatID, userID, isAdmin)
+
+	return isAdmin, nil
+}
+
+// fillChatInfo заполняет тип чата, адресацию и информацию об ответе
+// Возвращает false, если команда адресована другому боту (/cmd@otherbot)
+func (a *Adapter) fillChatInfo(ctx *core.BaseContext, msg *tgbotapi.Message) bool {
+	if msg.Chat != nil {
+		ctx.SetChatType(core.ChatType(msg.Chat.Type))
+	}
+
+	if a.bot == nil {
+		return true
+	}
+
+	if msg.From != nil && msg.Chat != nil {
+		chatID, userID := msg.Chat.ID, msg.From.ID
+		ctx.SetAdminChecker(func() (bool, error) {
+			return a.isChatAdmin(chatID, userID)
+		})
+	}
+
+	botName := a.bot.Self.UserName
+
+	// Упоминание бота
+	for _, entity := range msg.Entities {
+		switch {
+		case entity.Type == "mention":
+			if strings.EqualFold(entitySubstring(msg.Text, entity), "@"+botName) {
+				ctx.SetBotMentioned(true)
+			}
+		case entity.Type == "text_mention" && entity.User != nil:
+			if entity.User.ID == a.bot.Self.ID {
+				ctx.SetBotMentioned(true)
+			}
+		}
+	}
+
+	// Ответ на сообщение
+	if reply := msg.ReplyToMessage; reply != nil {
+		info := &core.ReplyInfo{
+			MessageID: strconv.Itoa(reply.MessageID),
+			Text:      reply.Text,
+		}
+		if reply.From != nil {
+			info.UserID = reply.From.ID
+			info.Username = reply.From.UserName
+			info.FromBot = reply.From.ID == a.bot.Self.ID
+		}
+		ctx.SetReplyTo(info)
+	}
+
+	// Адресация команды: /start@botname
+	if msg.IsCommand() {
+		command := msg.CommandWithAt()
+		if idx := strings.Index(command, "@"); idx >= 0 {
+			if !strings.EqualFold(command[idx+1:], botName) {
+				return false
+			}
+
+			// Убираем @botname, чтобы паттерны маршрутов совпадали
+			ctx.SetText(strings.Replace(msg.Text, command, command[:idx], 1))
+			ctx.SetAddressed(true)
+		}
+	}
+
+	return true
+}
+
+// entitySubstring извлекает текст сущности (смещения в UTF-16)
+func entitySubstring(text string, entity tgbotapi.MessageEntity) string {
+	encoded := utf16.Encode([]rune(text))
+	end := entity.Offset + entity.Length
+	if entity.Offset < 0 || end > len(encoded) {
+		return ""
+	}
+	return string(utf16.Decode(encoded[entity.Offset:end]))
+}
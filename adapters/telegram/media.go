@@ -0,0 +1,126 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andranikuz/botkit/core"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendMedia отправляет медиа ответа, по сообщению на файл, и возвращает ID последнего сообщения
+//
+// Текст ответа становится подписью первого файла (у остальных - Media.Caption).
+// Подпись длиннее MaxCaptionLength разбивается: начало остается у медиа,
+// остаток досылается текстом после всех файлов. Клавиатура прикрепляется к последнему сообщению
+func (a *Adapter) sendMedia(ctx core.UniversalContext, content core.MessageContent, options core.ResponseOptions) (int, error) {
+	var overflow []string
+	var sent tgbotapi.Message
+
+	for i, media := range content.Media {
+		caption := media.Caption
+		if i == 0 && content.Text != "" {
+			caption = content.Text
+		}
+
+		// У стикеров нет подписи - текст уходит в досылаемые сообщения
+		var rest string
+		if media.Type == core.MediaTypeSticker {
+			caption, rest = "", caption
+		} else {
+			caption, rest = SplitCaption(caption, content.ParseMode)
+		}
+		if strings.TrimSpace(rest) != "" {
+			overflow = append(overflow, rest)
+		}
+
+		base := tgbotapi.BaseChat{
+			ChatID:              ctx.GetChatID(),
+			DisableNotification: options.DisableNotification,
+		}
+		if i == 0 && options.ReplyToMessageID != "" {
+			if id, err := strconv.Atoi(options.ReplyToMessageID); err == nil {
+				base.ReplyToMessageID = id
+			}
+		}
+		if i == len(content.Media)-1 && len(overflow) == 0 && content.Keyboard != nil {
+			base.ReplyMarkup = a.buildKeyboard(content.Keyboard)
+		}
+
+		msg, err := mediaMessage(base, media, caption, parseMode(content.ParseMode))
+		if err != nil {
+			return 0, err
+		}
+		if sent, err = a.bot.Send(msg); err != nil {
+			return 0, fmt.Errorf("failed to send %s: %w", media.Type, err)
+		}
+	}
+
+	if len(overflow) > 0 {
+		rest := content
+		rest.Media = nil
+		rest.Text = strings.Join(overflow, "\n\n")
+
+		restOptions := options
+		restOptions.ReplyToMessageID = ""
+		return a.sendText(ctx, rest, restOptions)
+	}
+
+	a.deleteUserMessage(ctx, options)
+
+	return sent.MessageID, nil
+}
+
+// mediaMessage создает сообщение с файлом по FileID или URL
+func mediaMessage(base tgbotapi.BaseChat, media core.Media, caption, mode string) (tgbotapi.Chattable, error) {
+	var file tgbotapi.RequestFileData
+	switch {
+	case media.FileID != "":
+		file = tgbotapi.FileID(media.FileID)
+	case media.URL != "":
+		file = tgbotapi.FileURL(media.URL)
+	default:
+		return nil, fmt.Errorf("media %s without file_id or url", media.Type)
+	}
+
+	switch media.Type {
+	case core.MediaTypePhoto:
+		msg := tgbotapi.NewPhoto(base.ChatID, file)
+		msg.BaseChat = base
+		msg.Caption, msg.ParseMode = caption, mode
+		return msg, nil
+
+	case core.MediaTypeVideo:
+		msg := tgbotapi.NewVideo(base.ChatID, file)
+		msg.BaseChat = base
+		msg.Caption, msg.ParseMode = caption, mode
+		return msg, nil
+
+	case core.MediaTypeAudio:
+		msg := tgbotapi.NewAudio(base.ChatID, file)
+		msg.BaseChat = base
+		msg.Caption, msg.ParseMode = caption, mode
+		return msg, nil
+
+	case core.MediaTypeVoice:
+		msg := tgbotapi.NewVoice(base.ChatID, file)
+		msg.BaseChat = base
+		msg.Caption, msg.ParseMode = caption, mode
+		return msg, nil
+
+	case core.MediaTypeDocument:
+		msg := tgbotapi.NewDocument(base.ChatID, file)
+		msg.BaseChat = base
+		msg.Caption, msg.ParseMode = caption, mode
+		return msg, nil
+
+	case core.MediaTypeSticker:
+		msg := tgbotapi.NewSticker(base.ChatID, file)
+		msg.BaseChat = base
+		return msg, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported media type: %s", media.Type)
+	}
+}
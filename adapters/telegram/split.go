@@ -0,0 +1,387 @@
+package telegram
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// Лимиты Telegram Bot API
+const (
+	// MaxMessageLength максимальная длина текста сообщения
+	MaxMessageLength = 4096
+
+	// MaxCaptionLength максимальная длина подписи к медиа
+	MaxCaptionLength = 1024
+)
+
+// SplitConfig настройки разбиения длинных сообщений
+type SplitConfig struct {
+	// MaxLength максимальная длина одной части (по умолчанию MaxMessageLength)
+	MaxLength int
+
+	// MaxChunks максимальное количество частей (0 = без ограничений)
+	MaxChunks int
+
+	// DocumentFallback отправлять текст .txt документом, если частей больше MaxChunks
+	DocumentFallback bool
+
+	// DocumentName имя файла для документа
+	DocumentName string
+}
+
+// DefaultSplitConfig возвращает настройки разбиения по умолчанию
+func DefaultSplitConfig() SplitConfig {
+	return SplitConfig{
+		MaxLength:    MaxMessageLength,
+		DocumentName: "message.txt",
+	}
+}
+
+// formatEntity открытая сущность форматирования (HTML тег или MarkdownV2 маркер)
+type formatEntity struct {
+	open  string
+	close string
+}
+
+// SplitText разбивает текст на части не длиннее limit символов (всегда хотя бы одну).
+// Текст режется по абзацам, затем по строкам, затем по пробелам.
+// HTML теги и сущности MarkdownV2 не разрываются: открытые на границе
+// части сущности закрываются в конце части и открываются заново в следующей.
+func SplitText(text string, limit int, mode core.ParseMode) []string {
+	if limit <= 0 {
+		limit = MaxMessageLength
+	}
+
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	chunks := make([]string, 0, 2)
+	rest := []rune(text)
+	prefix := 0
+
+	for len(rest) > limit {
+		safe, _ := scanFormatting(rest, mode)
+
+		window := limit
+		var cut int
+		var stack []formatEntity
+
+		// Уменьшаем окно, пока часть вместе с закрывающими тегами не влезет в лимит
+		for {
+			cut = findCut(rest, safe, prefix, window)
+			_, stack = scanFormatting(rest[:cut], mode)
+
+			overflow := cut + closersLength(stack) - limit
+			if overflow <= 0 || window <= prefix+1 {
+				break
+			}
+			window -= overflow
+			if window < prefix+1 {
+				window = prefix + 1
+			}
+		}
+
+		chunk := strings.TrimRight(string(rest[:cut]), " \n") + closers(stack)
+		if strings.TrimSpace(chunk) != "" {
+			chunks = append(chunks, chunk)
+		}
+
+		openers := openers(stack)
+		tail := strings.TrimLeft(string(rest[cut:]), " \n")
+		rest = []rune(openers + tail)
+		prefix = utf8.RuneCountInString(openers)
+
+		// Защита от зацикливания: если открывающие теги сами не влезают, режем без форматирования
+		if prefix+1 >= limit {
+			rest = []rune(tail)
+			prefix = 0
+		}
+	}
+
+	if len(rest) > prefix && strings.TrimSpace(string(rest[prefix:])) != "" {
+		chunks = append(chunks, string(rest))
+	}
+
+	// Текст из одних пробелов - одна часть, как у короткого текста
+	if len(chunks) == 0 {
+		chunks = append(chunks, string([]rune(text)[:limit]))
+	}
+
+	return chunks
+}
+
+// SplitCaption разбивает текст на подпись к медиа (не длиннее MaxCaptionLength)
+// и остаток, который отправляется следующими сообщениями
+func SplitCaption(text string, mode core.ParseMode) (caption, rest string) {
+	chunks := SplitText(text, MaxCaptionLength, mode)
+	return chunks[0], strings.Join(chunks[1:], "\n")
+}
+
+// findCut ищет позицию разреза в пределах окна.
+// Предпочитает границы абзацев, затем строк, затем слов во второй половине окна.
+func findCut(runes []rune, safe []bool, min, window int) int {
+	if window > len(runes) {
+		window = len(runes)
+	}
+
+	half := min + (window-min)/2
+
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if pos := lastSafeIndex(runes, safe, sep, half, window); pos > 0 {
+			return pos
+		}
+	}
+
+	// Жесткий разрез в ближайшей безопасной позиции
+	for pos := window; pos > min; pos-- {
+		if safe[pos] {
+			return pos
+		}
+	}
+
+	return window
+}
+
+// lastSafeIndex возвращает последнюю безопасную позицию разделителя в диапазоне (from, to]
+func lastSafeIndex(runes []rune, safe []bool, sep string, from, to int) int {
+	sepRunes := []rune(sep)
+
+	for pos := to - len(sepRunes); pos > from; pos-- {
+		if !safe[pos] {
+			continue
+		}
+
+		match := true
+		for i, r := range sepRunes {
+			if runes[pos+i] != r {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return pos
+		}
+	}
+
+	return 0
+}
+
+// scanFormatting размечает безопасные позиции разреза и возвращает
+// стек сущностей, оставшихся открытыми в конце текста.
+// safe[i] == true означает, что текст можно разрезать перед символом i.
+func scanFormatting(runes []rune, mode core.ParseMode) ([]bool, []formatEntity) {
+	safe := make([]bool, len(runes)+1)
+	for i := range safe {
+		safe[i] = true
+	}
+
+	switch mode {
+	case core.ParseModeHTML:
+		return safe, scanHTML(runes, safe)
+	case core.ParseModeMarkdown:
+		return safe, scanMarkdownV2(runes, safe)
+	default:
+		return safe, nil
+	}
+}
+
+// scanHTML разбирает HTML разметку Telegram
+func scanHTML(runes []rune, safe []bool) []formatEntity {
+	stack := make([]formatEntity, 0)
+
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '<':
+			end := indexRune(runes, '>', i+1)
+			if end < 0 {
+				continue
+			}
+			markUnsafe(safe, i+1, end)
+
+			tag := string(runes[i+1 : end])
+			if strings.HasPrefix(tag, "/") {
+				stack = popEntity(stack, "</"+tagName(tag[1:])+">")
+			} else if !strings.HasSuffix(tag, "/") {
+				stack = append(stack, formatEntity{
+					open:  "<" + tag + ">",
+					close: "</" + tagName(tag) + ">",
+				})
+			}
+			i = end
+
+		case '&':
+			end := indexRune(runes, ';', i+1)
+			if end < 0 || end-i > 10 {
+				continue
+			}
+			markUnsafe(safe, i+1, end)
+			i = end
+		}
+	}
+
+	return stack
+}
+
+// scanMarkdownV2 разбирает разметку MarkdownV2
+func scanMarkdownV2(runes []rune, safe []bool) []formatEntity {
+	stack := make([]formatEntity, 0)
+
+	for i := 0; i < len(runes); i++ {
+		// Внутри блока кода значимы только экранирование и закрывающий маркер
+		if top := topEntity(stack); top == "`" || top == "```" {
+			switch {
+			case runes[i] == '\\':
+				markUnsafe(safe, i+1, i+1)
+				i++
+			case hasPrefixAt(runes, i, top):
+				stack = stack[:len(stack)-1]
+				markUnsafe(safe, i+1, i+len(top)-1)
+				i += len(top) - 1
+			}
+			continue
+		}
+
+		switch {
+		case runes[i] == '\\':
+			markUnsafe(safe, i+1, i+1)
+			i++
+
+		case hasPrefixAt(runes, i, "```"):
+			open := "```"
+			end := i + 2
+			// Язык блока кода до перевода строки
+			if nl := indexRune(runes, '\n', i+3); nl >= 0 && !strings.ContainsAny(string(runes[i+3:nl]), " `") {
+				open = string(runes[i : nl+1])
+				end = nl
+			}
+			markUnsafe(safe, i+1, end)
+			stack = append(stack, formatEntity{open: open, close: "```"})
+			i = end
+
+		case runes[i] == '`':
+			stack = append(stack, formatEntity{open: "`", close: "`"})
+
+		case runes[i] == '[':
+			// Ссылку [text](url) нельзя разрывать
+			mid := indexString(runes, "](", i+1)
+			if mid < 0 {
+				continue
+			}
+			end := indexRune(runes, ')', mid+2)
+			if end < 0 {
+				continue
+			}
+			markUnsafe(safe, i+1, end)
+			i = end
+
+		case hasPrefixAt(runes, i, "||"), hasPrefixAt(runes, i, "__"):
+			marker := string(runes[i : i+2])
+			stack = toggleEntity(stack, marker)
+			markUnsafe(safe, i+1, i+1)
+			i++
+
+		case runes[i] == '*' || runes[i] == '_' || runes[i] == '~':
+			stack = toggleEntity(stack, string(runes[i]))
+		}
+	}
+
+	return stack
+}
+
+// toggleEntity закрывает маркер, если он открыт, иначе открывает
+func toggleEntity(stack []formatEntity, marker string) []formatEntity {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].close == marker {
+			return append(stack[:i], stack[i+1:]...)
+		}
+	}
+	return append(stack, formatEntity{open: marker, close: marker})
+}
+
+// popEntity удаляет последнюю сущность с указанным закрывающим тегом
+func popEntity(stack []formatEntity, closeTag string) []formatEntity {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].close == closeTag {
+			return append(stack[:i], stack[i+1:]...)
+		}
+	}
+	return stack
+}
+
+// topEntity возвращает закрывающий маркер верхней сущности
+func topEntity(stack []formatEntity) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	return stack[len(stack)-1].close
+}
+
+// closers возвращает закрывающие теги в обратном порядке
+func closers(stack []formatEntity) string {
+	var sb strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		sb.WriteString(stack[i].close)
+	}
+	return sb.String()
+}
+
+// openers возвращает открывающие теги в исходном порядке
+func openers(stack []formatEntity) string {
+	var sb strings.Builder
+	for _, e := range stack {
+		sb.WriteString(e.open)
+	}
+	return sb.String()
+}
+
+// closersLength возвращает длину закрывающих тегов в символах
+func closersLength(stack []formatEntity) int {
+	return utf8.RuneCountInString(closers(stack))
+}
+
+// tagName извлекает имя HTML тега
+func tagName(tag string) string {
+	if idx := strings.IndexAny(tag, " \t\n"); idx >= 0 {
+		tag = tag[:idx]
+	}
+	return strings.ToLower(tag)
+}
+
+// markUnsafe помечает позиции [from, to] как небезопасные для разреза
+func markUnsafe(safe []bool, from, to int) {
+	for i := from; i <= to && i < len(safe); i++ {
+		safe[i] = false
+	}
+}
+
+func indexRune(runes []rune, r rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexString(runes []rune, s string, from int) int {
+	for i := from; i < len(runes); i++ {
+		if hasPrefixAt(runes, i, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasPrefixAt(runes []rune, pos int, prefix string) bool {
+	for _, r := range prefix {
+		if pos >= len(runes) || runes[pos] != r {
+			return false
+		}
+		pos++
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		mode  core.ParseMode
+		want  []string
+	}{
+		{
+			name:  "short text",
+			text:  "hello",
+			limit: 10,
+			want:  []string{"hello"},
+		},
+		{
+			name:  "empty text",
+			text:  "",
+			limit: 10,
+			want:  []string{""},
+		},
+		{
+			name:  "whitespace only",
+			text:  strings.Repeat(" ", 25),
+			limit: 10,
+			want:  []string{strings.Repeat(" ", 10)},
+		},
+		{
+			name:  "paragraphs",
+			text:  "first para\n\nsecond para",
+			limit: 15,
+			want:  []string{"first para", "second para"},
+		},
+		{
+			name:  "lines",
+			text:  "line one\nline two",
+			limit: 12,
+			want:  []string{"line one", "line two"},
+		},
+		{
+			name:  "words",
+			text:  "aaaa bbbb cccc",
+			limit: 10,
+			want:  []string{"aaaa bbbb", "cccc"},
+		},
+		{
+			name:  "hard cut",
+			text:  "abcdefghij",
+			limit: 4,
+			want:  []string{"abcd", "efgh", "ij"},
+		},
+		{
+			name:  "html tag reopened",
+			text:  "<b>aaaa bbbb cccc dddd</b>",
+			limit: 20,
+			mode:  core.ParseModeHTML,
+			want:  []string{"<b>aaaa bbbb</b>", "<b>cccc dddd</b>"},
+		},
+		{
+			name:  "html link attributes reopened",
+			text:  `<a href="x">aaaa bbbb cccc</a>`,
+			limit: 25,
+			mode:  core.ParseModeHTML,
+			want:  []string{`<a href="x">aaaa bbbb</a>`, `<a href="x">cccc</a>`},
+		},
+		{
+			name:  "html entity not cut",
+			text:  "aaaaaa&amp;bb",
+			limit: 8,
+			mode:  core.ParseModeHTML,
+			want:  []string{"aaaaaa", "&amp;bb"},
+		},
+		{
+			name:  "markdown bold reopened",
+			text:  "*aaaa bbbb cccc*",
+			limit: 12,
+			mode:  core.ParseModeMarkdown,
+			want:  []string{"*aaaa bbbb*", "*cccc*"},
+		},
+		{
+			name:  "markdown escape not cut",
+			text:  `aaaaa\.bbbb`,
+			limit: 6,
+			mode:  core.ParseModeMarkdown,
+			want:  []string{"aaaaa", `\.bbbb`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.text, tt.limit, tt.mode)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("SplitText() = %q, want %q", got, tt.want)
+			}
+			for _, chunk := range got {
+				if n := utf8.RuneCountInString(chunk); n > tt.limit {
+					t.Errorf("chunk %q has %d runes, limit %d", chunk, n, tt.limit)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTextLimits(t *testing.T) {
+	text := strings.Repeat("<b>жирный</b> <i>курсив</i> обычный текст\n", 500)
+
+	chunks := SplitText(text, MaxMessageLength, core.ParseModeHTML)
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := utf8.RuneCountInString(chunk); n > MaxMessageLength {
+			t.Errorf("chunk %d has %d runes", i, n)
+		}
+		if strings.Count(chunk, "<b>") != strings.Count(chunk, "</b>") {
+			t.Errorf("chunk %d has unbalanced <b>", i)
+		}
+		if strings.Count(chunk, "<i>") != strings.Count(chunk, "</i>") {
+			t.Errorf("chunk %d has unbalanced <i>", i)
+		}
+	}
+}
+
+func TestSplitCaption(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantCaption string
+		wantRest    string
+	}{
+		{name: "empty", text: "", wantCaption: "", wantRest: ""},
+		{name: "short", text: "photo", wantCaption: "photo", wantRest: ""},
+		{
+			name:        "long",
+			text:        strings.Repeat("a", MaxCaptionLength) + "\n\nrest",
+			wantCaption: strings.Repeat("a", MaxCaptionLength),
+			wantRest:    "rest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caption, rest := SplitCaption(tt.text, core.ParseModePlain)
+			if caption != tt.wantCaption || rest != tt.wantRest {
+				t.Fatalf("SplitCaption() = %q, %q, want %q, %q", caption, rest, tt.wantCaption, tt.wantRest)
+			}
+		})
+	}
+}
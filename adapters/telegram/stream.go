@@ -0,0 +1,167 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andranikuz/botkit/core"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DefaultStreamInterval минимальный интервал между редактированиями потокового сообщения
+const DefaultStreamInterval = time.Second
+
+// streamWriter доставляет потоковый ответ через редактирование сообщения
+type streamWriter struct {
+	adapter *Adapter
+	ctx     core.UniversalContext
+	content core.MessageContent
+	options core.ResponseOptions
+
+	// text текст текущего сообщения
+	text strings.Builder
+
+	// msgID ID текущего сообщения (0 - еще не отправлено)
+	msgID int
+
+	// dirty есть неотправленные изменения
+	dirty bool
+}
+
+// streamMessage отправляет потоковый ответ
+// Первая часть отправляется новым сообщением, последующие - редактированием
+// не чаще streamInterval. Клавиатура прикрепляется по завершении потока
+func (a *Adapter) streamMessage(ctx core.UniversalContext, response core.Response) error {
+	streamResp, ok := response.(core.StreamResponse)
+	if !ok || streamResp.Stream() == nil {
+		return fmt.Errorf("stream response without stream")
+	}
+
+	w := &streamWriter{
+		adapter: a,
+		ctx:     ctx,
+		content: response.Content(),
+		options: response.Options(),
+	}
+	w.text.WriteString(w.content.Text)
+	w.dirty = w.text.Len() > 0
+
+	ticker := time.NewTicker(a.streamInterval)
+	defer ticker.Stop()
+
+	stream := streamResp.Stream()
+	defer core.DrainStream(stream)
+
+	for {
+		select {
+		case chunk, ok := <-stream:
+			if !ok {
+				return w.flush(true)
+			}
+
+			if chunk.Err != nil {
+				if err := w.flush(true); err != nil {
+					a.logger.Error("Failed to flush stream", "error", err)
+					a.sendFailed()
+				}
+				return chunk.Err
+			}
+
+			w.text.WriteString(chunk.Text)
+			w.dirty = w.dirty || chunk.Text != ""
+
+		case <-ticker.C:
+			if err := w.flush(false); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// flush отправляет накопленный текст
+func (w *streamWriter) flush(final bool) error {
+	if !w.dirty && !(final && w.msgID != 0 && w.content.Keyboard != nil) {
+		return nil
+	}
+
+	text := w.text.String()
+	limit := w.adapter.split.MaxLength
+	mode := w.content.ParseMode
+
+	// Текст перерос лимит - фиксируем заполненные сообщения и продолжаем в новом
+	if utf8.RuneCountInString(text) > limit {
+		parts := SplitText(text, limit, mode)
+		if len(parts) == 0 {
+			w.dirty = false
+			return nil
+		}
+		for _, part := range parts[:len(parts)-1] {
+			if err := w.put(part, false); err != nil {
+				return err
+			}
+			w.msgID = 0
+		}
+
+		text = parts[len(parts)-1]
+		w.text.Reset()
+		w.text.WriteString(text)
+	}
+
+	if strings.TrimSpace(text) == "" {
+		w.dirty = false
+		return nil
+	}
+
+	// Промежуточный текст может содержать незакрытые теги
+	if !final {
+		_, stack := scanFormatting([]rune(text), mode)
+		text += closers(stack)
+	}
+
+	if err := w.put(text, final); err != nil {
+		return err
+	}
+
+	w.dirty = false
+	return nil
+}
+
+// put отправляет новое сообщение или редактирует текущее
+func (w *streamWriter) put(text string, withKeyboard bool) error {
+	bot := w.adapter.bot
+	chatID := w.ctx.GetChatID()
+	keyboard := w.content.Keyboard
+
+	if w.msgID == 0 {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = parseMode(w.content.ParseMode)
+		msg.DisableNotification = w.options.DisableNotification
+		msg.DisableWebPagePreview = w.options.DisableWebPreview
+		if withKeyboard && keyboard != nil {
+			msg.ReplyMarkup = w.adapter.buildKeyboard(keyboard)
+		}
+
+		sent, err := bot.Send(msg)
+		if err != nil {
+			return err
+		}
+		w.msgID = sent.MessageID
+		return nil
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, w.msgID, text)
+	edit.ParseMode = parseMode(w.content.ParseMode)
+	edit.DisableWebPagePreview = w.options.DisableWebPreview
+	if withKeyboard && keyboard != nil {
+		if markup, ok := w.adapter.buildKeyboard(keyboard).(tgbotapi.InlineKeyboardMarkup); ok {
+			edit.ReplyMarkup = &markup
+		}
+	}
+
+	if _, err := bot.Send(edit); err != nil && !isNotModified(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package telegram
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMenuMessageTTL сколько трекер помнит сообщение меню
+	// (бот не может удалить сообщение старше 48 часов, старое меню удобнее прислать заново)
+	DefaultMenuMessageTTL = 48 * time.Hour
+
+	// DefaultMaxTrackedChats сколько чатов помнит трекер в памяти
+	DefaultMaxTrackedChats = 10000
+)
+
+// MessageTracker запоминает последнее сообщение меню бота в каждом чате
+type MessageTracker interface {
+	// LastMenuMessage возвращает ID последнего сообщения меню в чате
+	LastMenuMessage(chatID int64) (int, bool)
+
+	// TrackMenuMessage запоминает сообщение меню в чате
+	TrackMenuMessage(chatID int64, messageID int)
+
+	// Forget забывает сообщение меню в чате
+	Forget(chatID int64)
+}
+
+// MemoryMessageTracker реализация MessageTracker в памяти процесса
+//
+// Сообщение забывается через TTL после того, как его запомнили или обновили, а при
+// превышении лимита чатов вытесняется чат, меню которого обновлялось давнее всего
+type MemoryMessageTracker struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxChats int
+
+	// messages элементы order по чату; order - от недавних к давним
+	messages map[int64]*list.Element
+	order    *list.List
+}
+
+// trackedMessage сообщение меню в чате
+type trackedMessage struct {
+	chatID    int64
+	messageID int
+	seen      time.Time
+}
+
+// NewMemoryMessageTracker создает трекер сообщений в памяти
+// с DefaultMenuMessageTTL и DefaultMaxTrackedChats
+func NewMemoryMessageTracker() *MemoryMessageTracker {
+	return &MemoryMessageTracker{
+		ttl:      DefaultMenuMessageTTL,
+		maxChats: DefaultMaxTrackedChats,
+		messages: make(map[int64]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// SetTTL устанавливает, сколько помнить сообщение меню (0 - без ограничения)
+func (t *MemoryMessageTracker) SetTTL(ttl time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ttl = ttl
+}
+
+// SetMaxChats устанавливает, сколько чатов помнить (0 - без ограничения)
+func (t *MemoryMessageTracker) SetMaxChats(maxChats int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.maxChats = maxChats
+	t.evict()
+}
+
+// LastMenuMessage возвращает ID последнего сообщения меню в чате
+func (t *MemoryMessageTracker) LastMenuMessage(chatID int64) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	element, ok := t.messages[chatID]
+	if !ok {
+		return 0, false
+	}
+
+	message := element.Value.(*trackedMessage)
+	if t.expired(message, time.Now()) {
+		t.remove(element)
+		return 0, false
+	}
+	return message.messageID, true
+}
+
+// TrackMenuMessage запоминает сообщение меню в чате
+// ID 0 (сообщение не отправлено) не запоминается
+func (t *MemoryMessageTracker) TrackMenuMessage(chatID int64, messageID int) {
+	if messageID <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if element, ok := t.messages[chatID]; ok {
+		message := element.Value.(*trackedMessage)
+		message.messageID = messageID
+		message.seen = now
+		t.order.MoveToFront(element)
+		return
+	}
+
+	t.messages[chatID] = t.order.PushFront(&trackedMessage{
+		chatID:    chatID,
+		messageID: messageID,
+		seen:      now,
+	})
+	t.evict()
+}
+
+// Forget забывает сообщение меню в чате
+func (t *MemoryMessageTracker) Forget(chatID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if element, ok := t.messages[chatID]; ok {
+		t.remove(element)
+	}
+}
+
+// Len возвращает количество запомненных чатов
+func (t *MemoryMessageTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.order.Len()
+}
+
+// evict удаляет устаревшие записи и вытесняет давние сверх лимита
+func (t *MemoryMessageTracker) evict() {
+	now := time.Now()
+	for element := t.order.Back(); element != nil; element = t.order.Back() {
+		message := element.Value.(*trackedMessage)
+		overflow := t.maxChats > 0 && t.order.Len() > t.maxChats
+		if !overflow && !t.expired(message, now) {
+			return
+		}
+		t.remove(element)
+	}
+}
+
+// expired проверяет, что сообщение не обновлялось дольше TTL
+func (t *MemoryMessageTracker) expired(message *trackedMessage, now time.Time) bool {
+	return t.ttl > 0 && now.Sub(message.seen) > t.ttl
+}
+
+// remove удаляет запись чата
+func (t *MemoryMessageTracker) remove(element *list.Element) {
+	message := t.order.Remove(element).(*trackedMessage)
+	delete(t.messages, message.chatID)
+}
@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryMessageTracker(t *testing.T) {
+	type track struct {
+		chatID    int64
+		messageID int
+	}
+
+	tests := []struct {
+		name     string
+		maxChats int
+		tracks   []track
+		want     map[int64]int
+		missing  []int64
+	}{
+		{
+			name:   "last message wins",
+			tracks: []track{{1, 10}, {1, 11}},
+			want:   map[int64]int{1: 11},
+		},
+		{
+			name:    "zero id is not tracked",
+			tracks:  []track{{1, 0}},
+			missing: []int64{1},
+		},
+		{
+			name:   "zero id keeps previous menu",
+			tracks: []track{{1, 10}, {1, 0}},
+			want:   map[int64]int{1: 10},
+		},
+		{
+			name:     "least recently updated chat is evicted",
+			maxChats: 2,
+			tracks:   []track{{1, 10}, {2, 20}, {1, 11}, {3, 30}},
+			want:     map[int64]int{1: 11, 3: 30},
+			missing:  []int64{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewMemoryMessageTracker()
+			if tt.maxChats > 0 {
+				tracker.SetMaxChats(tt.maxChats)
+			}
+
+			for _, tr := range tt.tracks {
+				tracker.TrackMenuMessage(tr.chatID, tr.messageID)
+			}
+
+			for chatID, want := range tt.want {
+				if got, ok := tracker.LastMenuMessage(chatID); !ok || got != want {
+					t.Errorf("chat %d: got %d (%v), want %d", chatID, got, ok, want)
+				}
+			}
+			for _, chatID := range tt.missing {
+				if got, ok := tracker.LastMenuMessage(chatID); ok {
+					t.Errorf("chat %d: got %d, want nothing", chatID, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryMessageTrackerTTL(t *testing.T) {
+	tracker := NewMemoryMessageTracker()
+	tracker.SetTTL(20 * time.Millisecond)
+
+	tracker.TrackMenuMessage(1, 10)
+	time.Sleep(30 * time.Millisecond)
+	tracker.TrackMenuMessage(2, 20)
+
+	if _, ok := tracker.LastMenuMessage(1); ok {
+		t.Error("expired message is returned")
+	}
+	if _, ok := tracker.LastMenuMessage(2); !ok {
+		t.Error("fresh message is forgotten")
+	}
+	if n := tracker.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+}
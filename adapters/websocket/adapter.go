@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/tracing"
 	"github.com/gorilla/websocket"
 )
 
@@ -20,6 +22,12 @@ type Adapter struct {
 	upgrader    websocket.Upgrader
 	connections map[string]*Connection
 	mu          sync.RWMutex
+
+	// metrics метрики адаптера (websocket.connections, adapter.send_errors)
+	metrics core.Metrics
+
+	// tracer трассировка входящих сообщений
+	tracer *tracing.Tracer
 }
 
 // Connection представляет WebSocket соединение
@@ -31,6 +39,12 @@ type Connection struct {
 	Hub     *Adapter
 	Context context.Context
 	Cancel  context.CancelFunc
+
+	// inbox очередь входящих сообщений (обрабатываются по порядку в processPump)
+	inbox chan Message
+
+	sendMu sync.Mutex
+	closed bool
 }
 
 // Message сообщение WebSocket
@@ -66,6 +80,23 @@ func (a *Adapter) UseRouter(router core.Router) {
 	a.router = router
 }
 
+// SetMetrics включает метрики адаптера:
+// websocket.connections - число открытых соединений,
+// adapter.send_errors с меткой adapter="websocket" - сообщения, не доставленные клиенту.
+// Вызывается до приема соединений
+func (a *Adapter) SetMetrics(metrics core.Metrics) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.metrics = metrics
+	a.reportConnections()
+}
+
+// SetTracer включает трассировку: span "websocket.message" на каждое входящее сообщение
+// и дочерний "websocket.send" на отправку ответа. Вызывается до приема соединений
+func (a *Adapter) SetTracer(tracer *tracing.Tracer) {
+	a.tracer = tracer
+}
+
 // ServeHTTP обрабатывает WebSocket соединения
 func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Извлекаем user ID из заголовков или query params
@@ -92,6 +123,7 @@ func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Hub:     a,
 		Context: ctx,
 		Cancel:  cancel,
+		inbox:   make(chan Message, 64),
 	}
 
 	// Регистрируем соединение
@@ -100,6 +132,7 @@ func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Запускаем горутины для чтения и записи
 	go connection.readPump()
 	go connection.writePump()
+	go connection.processPump()
 
 	// Отправляем приветственное сообщение
 	welcome := Message{
@@ -119,6 +152,7 @@ func (a *Adapter) register(conn *Connection) {
 	defer a.mu.Unlock()
 
 	a.connections[conn.ID] = conn
+	a.reportConnections()
 	a.logger.Info("WebSocket connection registered",
 		"id", conn.ID,
 		"user", conn.UserID,
@@ -132,9 +166,14 @@ func (a *Adapter) unregister(conn *Connection) {
 
 	if _, ok := a.connections[conn.ID]; ok {
 		delete(a.connections, conn.ID)
-		close(conn.Send)
+		a.reportConnections()
 		conn.Cancel()
 
+		conn.sendMu.Lock()
+		conn.closed = true
+		close(conn.Send)
+		conn.sendMu.Unlock()
+
 		a.logger.Info("WebSocket connection unregistered",
 			"id", conn.ID,
 			"user", conn.UserID,
@@ -143,10 +182,13 @@ func (a *Adapter) unregister(conn *Connection) {
 }
 
 // readPump читает сообщения от клиента
+// Обработка вынесена в processPump, чтобы закрытие соединения замечалось
+// сразу и отменяло контекст выполняющегося обработчика
 func (c *Connection) readPump() {
 	defer func() {
 		c.Hub.unregister(c)
 		c.Conn.Close()
+		close(c.inbox)
 	}()
 
 	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -171,7 +213,21 @@ func (c *Connection) readPump() {
 			continue
 		}
 
-		// Обрабатываем сообщение
+		// Ставим сообщение в очередь обработки
+		select {
+		case c.inbox <- msg:
+		default:
+			c.sendError("Too many pending messages")
+		}
+	}
+}
+
+// processPump последовательно обрабатывает входящие сообщения
+func (c *Connection) processPump() {
+	for msg := range c.inbox {
+		if c.Context.Err() != nil {
+			continue
+		}
 		c.handleMessage(msg)
 	}
 }
@@ -212,10 +268,30 @@ func (c *Connection) handleMessage(msg Message) {
 	// Создаем универсальный контекст
 	ctx := c.messageToContext(msg)
 
+	spanCtx, span := c.Hub.tracer.Start(ctx.Context(), "websocket.message", tracing.WithAttributes(
+		tracing.Attr("message.id", msg.ID),
+		tracing.Attr("message.type", msg.Type),
+		tracing.Attr("user_id", c.UserID),
+		tracing.Attr("connection.id", c.ID),
+	))
+	ctx.WithContext(spanCtx)
+	defer span.End()
+
 	// Роутим через основной роутер
 	if c.Hub.router != nil {
 		response := c.Hub.router.Route(ctx)
+
+		// Клиент отключился во время обработки - отвечать некому
+		if c.Context.Err() != nil {
+			span.SetStatus(tracing.StatusError, "connection closed")
+			return
+		}
+
+		_, sendSpan := c.Hub.tracer.Start(ctx.Context(), "websocket.send",
+			tracing.WithAttributes(tracing.Attr("response.type", string(response.Type()))),
+		)
 		c.sendResponse(msg.ID, response)
+		sendSpan.End()
 	} else {
 		c.sendError("Router not configured")
 	}
@@ -229,6 +305,22 @@ func (c *Connection) messageToContext(msg Message) core.UniversalContext {
 	ctx.SetUserID(c.UserID)
 	ctx.SetChatID(msg.ChatID)
 	ctx.SetMessageID(msg.ID)
+
+	// Данные клиента - первыми и без служебных ключей, чтобы не перекрыть значения адаптера
+	for k, v := range msg.Data {
+		if !core.IsReservedKey(k) {
+			ctx.Set(k, v)
+		}
+	}
+
+	// Клиент повторяет сообщение с тем же ID (в том числе после переподключения)
+	if msg.ID != "" {
+		owner := c.ID
+		if c.UserID != 0 {
+			owner = strconv.FormatInt(c.UserID, 10)
+		}
+		ctx.Set(core.IdempotencyKey, "websocket:"+owner+":"+msg.ID)
+	}
 	ctx.SetText(msg.Text)
 
 	// Устанавливаем тип сообщения
@@ -239,14 +331,25 @@ func (c *Connection) messageToContext(msg Message) core.UniversalContext {
 		ctx.SetIsCallback(true)
 	}
 
-	// Добавляем данные
-	for k, v := range msg.Data {
-		ctx.Set(k, v)
-	}
-
 	// Сохраняем оригинальное сообщение
 	ctx.SetOriginal(msg)
 
+	// Индикаторы действий отправляем фреймами "typing"
+	ctx.SetChatActionSender(func(action core.ChatAction) error {
+		if err := c.Context.Err(); err != nil {
+			return err
+		}
+		c.sendMessage(Message{
+			Type:   "typing",
+			ID:     msg.ID,
+			ChatID: msg.ChatID,
+			Data: map[string]interface{}{
+				"action": action,
+			},
+		})
+		return nil
+	})
+
 	return ctx
 }
 
@@ -271,6 +374,20 @@ func (c *Connection) sendResponse(requestID string, response core.Response) {
 			"text":       response.Content().Text,
 		}
 
+	case core.ResponseTypeUpsert:
+		msg.Data = map[string]interface{}{
+			"action":     "upsert",
+			"message_id": response.Options().MessageToEditID,
+			"text":       response.Content().Text,
+		}
+
+	case core.ResponseTypeCallback:
+		msg.Data = map[string]interface{}{
+			"action":     "notify",
+			"text":       response.Options().CallbackText,
+			"show_alert": response.Options().ShowAlert,
+		}
+
 	case core.ResponseTypeDelete:
 		msg.Data = map[string]interface{}{
 			"action":     "delete",
@@ -281,6 +398,10 @@ func (c *Connection) sendResponse(requestID string, response core.Response) {
 		// Не отправляем ничего
 		return
 
+	case core.ResponseTypeStream:
+		c.sendStream(requestID, response)
+		return
+
 	case core.ResponseTypeMultiple:
 		// Отправляем каждое действие отдельно
 		for _, action := range response.Actions() {
@@ -292,19 +413,79 @@ func (c *Connection) sendResponse(requestID string, response core.Response) {
 	c.sendMessage(msg)
 }
 
+// sendStream отправляет потоковый ответ частями
+// Каждая часть приходит фреймом "stream", завершение - фреймом "stream_end"
+func (c *Connection) sendStream(requestID string, response core.Response) {
+	streamResp, ok := response.(core.StreamResponse)
+	if !ok || streamResp.Stream() == nil {
+		c.sendError("Invalid stream response")
+		return
+	}
+
+	stream := streamResp.Stream()
+	defer core.DrainStream(stream)
+
+	if text := response.Content().Text; text != "" {
+		c.sendMessage(Message{Type: "stream", ID: requestID, Text: text})
+	}
+
+loop:
+	for {
+		select {
+		case chunk, ok := <-stream:
+			if !ok {
+				break loop
+			}
+
+			if chunk.Err != nil {
+				c.sendMessage(Message{Type: "error", ID: requestID, Error: chunk.Err.Error()})
+				return
+			}
+
+			if chunk.Text != "" {
+				c.sendMessage(Message{Type: "stream", ID: requestID, Text: chunk.Text})
+			}
+
+		case <-c.Context.Done():
+			// Соединение закрыто - прекращаем отправку
+			return
+		}
+	}
+
+	c.sendMessage(Message{
+		Type: "stream_end",
+		ID:   requestID,
+		Data: map[string]interface{}{
+			"parse_mode": response.Content().ParseMode,
+		},
+	})
+}
+
 // sendMessage отправляет сообщение клиенту
 func (c *Connection) sendMessage(msg Message) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		c.Hub.logger.Error("Failed to marshal message", "error", err)
+		c.Hub.sendFailed()
+		return
+	}
+
+	// Send закрывается в unregister под sendMu
+	c.sendMu.Lock()
+	if c.closed {
+		c.sendMu.Unlock()
 		return
 	}
 
 	select {
 	case c.Send <- data:
+		c.sendMu.Unlock()
 	default:
+		c.sendMu.Unlock()
+		c.Hub.sendFailed()
 		// Канал переполнен, закрываем соединение
-		c.Hub.unregister(c)
+		// (асинхронно: sendMessage может вызываться под блокировкой хаба)
+		go c.Hub.unregister(c)
 		c.Conn.Close()
 	}
 }
@@ -318,6 +499,21 @@ func (c *Connection) sendError(errMsg string) {
 	c.sendMessage(msg)
 }
 
+// reportConnections записывает число соединений (вызывается под a.mu)
+func (a *Adapter) reportConnections() {
+	if a.metrics != nil {
+		a.metrics.Gauge("websocket.connections", float64(len(a.connections)))
+	}
+}
+
+// sendFailed учитывает недоставленное сообщение в метриках
+// (без блокировки хаба: sendMessage может вызываться под ней)
+func (a *Adapter) sendFailed() {
+	if a.metrics != nil {
+		a.metrics.Counter("adapter.send_errors", 1, "adapter", "websocket")
+	}
+}
+
 // Broadcast отправляет сообщение всем подключенным клиентам
 func (a *Adapter) Broadcast(msg Message) {
 	a.mu.RLock()
@@ -0,0 +1,390 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+)
+
+const (
+	// DefaultRetention срок хранения записей по умолчанию
+	DefaultRetention = 90 * 24 * time.Hour
+
+	// DefaultCleanupInterval интервал удаления устаревших записей
+	DefaultCleanupInterval = time.Hour
+
+	// DefaultPrefix префикс ключей записей в хранилище
+	DefaultPrefix = "audit:"
+
+	// DefaultLimit записей в ответе на запрос по умолчанию
+	DefaultLimit = 100
+
+	// MaxLimit максимум записей в ответе на запрос
+	MaxLimit = 1000
+)
+
+// DefaultEvents события, которые журнал сохраняет по умолчанию
+var DefaultEvents = []string{"command.executed", "user.action"}
+
+// Options настройки журнала
+type Options struct {
+	// Events типы сохраняемых событий (nil - DefaultEvents, "*" - все события)
+	Events []string
+
+	// Retention срок хранения записей (0 - DefaultRetention)
+	Retention time.Duration
+
+	// CleanupInterval интервал удаления устаревших записей (0 - DefaultCleanupInterval)
+	CleanupInterval time.Duration
+
+	// Prefix префикс ключей в хранилище (пустой - DefaultPrefix)
+	Prefix string
+}
+
+// Record запись журнала аудита
+type Record struct {
+	ID      string                 `json:"id"`
+	Time    time.Time              `json:"time"`
+	Type    string                 `json:"type"`
+	Source  string                 `json:"source,omitempty"`
+	UserID  int64                  `json:"user_id,omitempty"`
+	ChatID  int64                  `json:"chat_id,omitempty"`
+	Module  string                 `json:"module,omitempty"`
+	Action  string                 `json:"action,omitempty"`
+	Success bool                   `json:"success"`
+	Error   string                 `json:"error,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Query условия выборки записей (пустые поля не ограничивают выборку)
+type Query struct {
+	UserID int64
+	Module string
+	Type   string
+	From   time.Time
+	To     time.Time
+
+	// Limit максимум записей (0 - DefaultLimit, не больше MaxLimit)
+	Limit int
+}
+
+// ErrInvalidQuery некорректные условия выборки
+var ErrInvalidQuery = errors.New("invalid audit query")
+
+// Ключи журнала в хранилище
+//
+//	<prefix>r:<id>              запись
+//	<prefix>u:<user_id>:<id>    индекс по пользователю
+//	<prefix>m:<module>:<id>     индекс по модулю
+//
+// ID записи - <время в наносекундах>-<случайный суффикс>, поэтому ключи
+// сортируются по времени без чтения самих записей
+const (
+	recordsKey = "r:"
+	usersKey   = "u:"
+	modulesKey = "m:"
+)
+
+// Log журнал аудита в core.Storage
+//
+// core.Storage умеет только перечислять ключи по префиксу, поэтому Find
+// перечисляет ключи самого узкого индекса (пользователь, затем модуль, иначе
+// все записи) и читает записи только из диапазона времени, пока не наберет
+// Limit. Cleanup перечисляет ключи всех записей и читает только устаревшие
+type Log struct {
+	storage core.Storage
+	opts    Options
+}
+
+// New создает журнал аудита
+func New(storage core.Storage, opts Options) *Log {
+	if len(opts.Events) == 0 {
+		opts.Events = DefaultEvents
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultRetention
+	}
+	if opts.CleanupInterval <= 0 {
+		opts.CleanupInterval = DefaultCleanupInterval
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultPrefix
+	}
+
+	return &Log{
+		storage: storage,
+		opts:    opts,
+	}
+}
+
+// Events возвращает типы сохраняемых событий
+func (l *Log) Events() []string {
+	return l.opts.Events
+}
+
+// Append сохраняет запись (ID и время заполняются, если не заданы)
+func (l *Log) Append(ctx context.Context, record Record) (Record, error) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	if record.ID == "" {
+		record.ID = newID(record.Time)
+	}
+
+	// Индексы пишутся первыми: индекс без записи Find пропускает, а запись
+	// без индекса не нашлась бы по пользователю и модулю. При ошибке
+	// уже записанные индексы удаляются
+	keys := l.indexKeys(record)
+	for i, key := range keys {
+		if err := l.storage.Save(ctx, key, record.ID); err != nil {
+			l.deleteKeys(ctx, keys[:i])
+			return record, fmt.Errorf("failed to index audit record %s: %w", record.ID, err)
+		}
+	}
+	if err := l.storage.Save(ctx, l.recordKey(record.ID), record); err != nil {
+		l.deleteKeys(ctx, keys)
+		return record, fmt.Errorf("failed to save audit record: %w", err)
+	}
+	return record, nil
+}
+
+// deleteKeys удаляет индексы несохраненной записи (ошибки удаления игнорируются)
+func (l *Log) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		_ = l.storage.Delete(ctx, key)
+	}
+}
+
+// AppendEvent сохраняет событие
+func (l *Log) AppendEvent(ctx context.Context, event core.Event) error {
+	_, err := l.Append(ctx, RecordOf(event))
+	return err
+}
+
+// Find возвращает записи по условиям, новые первыми
+func (l *Log) Find(ctx context.Context, query Query) ([]Record, error) {
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, fmt.Errorf("%w: to is before from", ErrInvalidQuery)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	// Самый узкий индекс: условия по остальным полям проверяются по записи
+	prefix := l.opts.Prefix + recordsKey
+	switch {
+	case query.UserID != 0:
+		prefix = fmt.Sprintf("%s%s%d:", l.opts.Prefix, usersKey, query.UserID)
+	case query.Module != "":
+		prefix = l.opts.Prefix + modulesKey + query.Module + ":"
+	}
+
+	entries, err := l.entries(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, limit)
+	for i := len(entries) - 1; i >= 0 && len(records) < limit; i-- {
+		entry := entries[i]
+		if !query.From.IsZero() && entry.time.Before(query.From) {
+			break
+		}
+		if !query.To.IsZero() && entry.time.After(query.To) {
+			continue
+		}
+
+		var record Record
+		if err := l.storage.Load(ctx, l.recordKey(entry.id), &record); err != nil {
+			// Запись могла быть удалена очисткой между List и Load
+			continue
+		}
+
+		if query.UserID != 0 && record.UserID != query.UserID {
+			continue
+		}
+		if query.Module != "" && record.Module != query.Module {
+			continue
+		}
+		if query.Type != "" && record.Type != query.Type {
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Cleanup удаляет записи старше срока хранения и возвращает их количество
+func (l *Log) Cleanup(ctx context.Context) (int, error) {
+	entries, err := l.entries(ctx, l.opts.Prefix+recordsKey)
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(-l.opts.Retention)
+	deleted := 0
+	for _, entry := range entries {
+		if !entry.time.Before(deadline) {
+			break
+		}
+
+		// Индексы удаляются первыми: без записи их уже не найти
+		var record Record
+		if err := l.storage.Load(ctx, l.recordKey(entry.id), &record); err == nil {
+			for _, key := range l.indexKeys(record) {
+				if err := l.storage.Delete(ctx, key); err != nil {
+					return deleted, fmt.Errorf("failed to delete audit index %s: %w", key, err)
+				}
+			}
+		}
+
+		if err := l.storage.Delete(ctx, l.recordKey(entry.id)); err != nil {
+			return deleted, fmt.Errorf("failed to delete audit record %s: %w", entry.id, err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// recordKey возвращает ключ записи
+func (l *Log) recordKey(id string) string {
+	return l.opts.Prefix + recordsKey + id
+}
+
+// indexKeys возвращает ключи индексов записи
+func (l *Log) indexKeys(record Record) []string {
+	var keys []string
+	if record.UserID != 0 {
+		keys = append(keys, fmt.Sprintf("%s%s%d:%s", l.opts.Prefix, usersKey, record.UserID, record.ID))
+	}
+	if record.Module != "" {
+		keys = append(keys, l.opts.Prefix+modulesKey+record.Module+":"+record.ID)
+	}
+	return keys
+}
+
+// entry ID записи и время из него
+type entry struct {
+	id   string
+	time time.Time
+}
+
+// entries возвращает ID записей под префиксом ключей, отсортированные по времени
+func (l *Log) entries(ctx context.Context, prefix string) ([]entry, error) {
+	keys, err := l.storage.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit records: %w", err)
+	}
+
+	result := make([]entry, 0, len(keys))
+	for _, key := range keys {
+		id := strings.TrimPrefix(key, prefix)
+		// Префикс модуля "arena:" не должен захватывать модуль "arena:pvp"
+		if strings.Contains(id, ":") {
+			continue
+		}
+		t, ok := timeOf(id)
+		if !ok {
+			continue
+		}
+		result = append(result, entry{id: id, time: t})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].id < result[j].id })
+	return result, nil
+}
+
+// RecordOf переводит событие в запись журнала
+func RecordOf(event core.Event) Record {
+	data := make(map[string]interface{}, len(event.Data()))
+	for key, value := range event.Data() {
+		data[key] = value
+	}
+
+	record := Record{
+		Type:    event.Type(),
+		Source:  event.Source(),
+		UserID:  event.UserID(),
+		ChatID:  event.ChatID(),
+		Success: true,
+		Data:    data,
+	}
+
+	switch e := event.(type) {
+	case *events.CommandExecutedEvent:
+		record.Module = e.Module
+		record.Action = e.Command
+		record.Success = e.Success
+		if e.Error != nil {
+			record.Error = e.Error.Error()
+		}
+		if e.Route != "" {
+			data["route"] = e.Route
+		}
+		if e.Latency > 0 {
+			data["latency_ms"] = float64(e.Latency.Microseconds()) / 1000
+		}
+		// Поля записи не дублируются в данных
+		for _, key := range []string{"module", "command", "success", "error"} {
+			delete(data, key)
+		}
+
+	case *events.UserActionEvent:
+		record.Action = e.Action
+		data["resource"] = e.Resource
+		data["result"] = e.Result
+		if module, ok := data["module"].(string); ok {
+			record.Module = module
+			delete(data, "module")
+		}
+
+	default:
+		if module, ok := data["module"].(string); ok {
+			record.Module = module
+			delete(data, "module")
+		}
+	}
+
+	if len(data) == 0 {
+		record.Data = nil
+	}
+	return record
+}
+
+// newID создает ID записи: время в наносекундах (20 цифр) и случайный суффикс
+func newID(t time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%020d-%s", t.UnixNano(), hex.EncodeToString(suffix))
+}
+
+// timeOf возвращает время записи из ее ID
+func timeOf(id string) (time.Time, bool) {
+	nanos, _, ok := strings.Cut(id, "-")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n), true
+}
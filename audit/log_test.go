@@ -0,0 +1,229 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/events"
+)
+
+// memoryStorage core.Storage в памяти для тестов
+type memoryStorage struct {
+	mu    sync.Mutex
+	data  map[string][]byte
+	loads int
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+func (s *memoryStorage) Save(ctx context.Context, key string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = encoded
+	return nil
+}
+
+func (s *memoryStorage) Load(ctx context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	encoded, ok := s.data[key]
+	if !ok {
+		return fmt.Errorf("key %s not found", key)
+	}
+	return json.Unmarshal(encoded, dest)
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memoryStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// fixture журнал с записями: ID записи - ее порядковый номер, минута от base
+func fixture(t *testing.T, base time.Time) (*Log, *memoryStorage) {
+	t.Helper()
+
+	storage := newMemoryStorage()
+	log := New(storage, Options{})
+
+	records := []Record{
+		{UserID: 1, Module: "arena", Type: "command.executed"},
+		{UserID: 2, Module: "arena", Type: "command.executed"},
+		{UserID: 1, Module: "shop", Type: "user.action"},
+		{UserID: 1, Module: "arena:pvp", Type: "command.executed"},
+		{Module: "config", Type: "config.changed"},
+		{UserID: 12, Module: "arena", Type: "command.executed"},
+	}
+	for i, record := range records {
+		record.Time = base.Add(time.Duration(i) * time.Minute)
+		record.Action = fmt.Sprint(i)
+		if _, err := log.Append(context.Background(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return log, storage
+}
+
+func TestLogFind(t *testing.T) {
+	base := time.Now().Add(-time.Hour).Truncate(time.Minute)
+
+	tests := []struct {
+		name    string
+		query   Query
+		want    []string
+		wantErr bool
+	}{
+		{name: "all, newest first", query: Query{}, want: []string{"5", "4", "3", "2", "1", "0"}},
+		{name: "by user", query: Query{UserID: 1}, want: []string{"3", "2", "0"}},
+		{name: "user prefix is exact", query: Query{UserID: 12}, want: []string{"5"}},
+		{name: "by module", query: Query{Module: "arena"}, want: []string{"5", "1", "0"}},
+		{name: "user and module", query: Query{UserID: 1, Module: "arena"}, want: []string{"0"}},
+		{name: "by type", query: Query{Type: "user.action"}, want: []string{"2"}},
+		{name: "time range", query: Query{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)}, want: []string{"3", "2", "1"}},
+		{name: "user and time", query: Query{UserID: 1, From: base.Add(time.Minute)}, want: []string{"3", "2"}},
+		{name: "limit", query: Query{Limit: 2}, want: []string{"5", "4"}},
+		{name: "unknown user", query: Query{UserID: 99}, want: []string{}},
+		{name: "to before from", query: Query{From: base.Add(time.Minute), To: base}, wantErr: true},
+	}
+
+	log, _ := fixture(t, base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := log.Find(context.Background(), tt.query)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Fatalf("err = %v, want ErrInvalidQuery", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(records))
+			for _, record := range records {
+				got = append(got, record.Action)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("Find() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogFindReadsIndex(t *testing.T) {
+	log, storage := fixture(t, time.Now().Add(-time.Hour))
+
+	storage.loads = 0
+	if _, err := log.Find(context.Background(), Query{UserID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if storage.loads != 1 {
+		t.Fatalf("Find by user loaded %d records, want 1", storage.loads)
+	}
+}
+
+func TestLogCleanup(t *testing.T) {
+	log, storage := fixture(t, time.Now().Add(-2*DefaultRetention))
+	if _, err := log.Append(context.Background(), Record{UserID: 1, Module: "arena", Action: "fresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := log.Cleanup(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 6 {
+		t.Fatalf("deleted = %d, want 6", deleted)
+	}
+
+	// Остаются запись и два ее индекса
+	if keys, _ := storage.List(context.Background(), DefaultPrefix); len(keys) != 3 {
+		t.Fatalf("keys after cleanup: %v", keys)
+	}
+	records, err := log.Find(context.Background(), Query{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Action != "fresh" {
+		t.Fatalf("records after cleanup: %+v", records)
+	}
+}
+
+// failingStorage отказывает в сохранении ключей с префиксом
+type failingStorage struct {
+	*memoryStorage
+	prefix string
+}
+
+func (s *failingStorage) Save(ctx context.Context, key string, data interface{}) error {
+	if strings.HasPrefix(key, s.prefix) {
+		return errors.New("storage unavailable")
+	}
+	return s.memoryStorage.Save(ctx, key, data)
+}
+
+func TestLogAppendFailureLeavesNoKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{name: "record", prefix: DefaultPrefix + recordsKey},
+		{name: "user index", prefix: DefaultPrefix + usersKey},
+		{name: "module index", prefix: DefaultPrefix + modulesKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &failingStorage{memoryStorage: newMemoryStorage(), prefix: tt.prefix}
+			log := New(storage, Options{})
+
+			if _, err := log.Append(context.Background(), Record{UserID: 1, Module: "arena"}); err == nil {
+				t.Fatal("Append succeeded, want error")
+			}
+			if keys, _ := storage.List(context.Background(), ""); len(keys) != 0 {
+				t.Fatalf("keys after failed Append: %v", keys)
+			}
+		})
+	}
+}
+
+func TestRecordOfAdminAction(t *testing.T) {
+	record := RecordOf(events.NewAdminActionEvent(7, "access", "ban", "user:42", 3600))
+
+	if record.UserID != 7 || record.Module != "access" || record.Action != "ban" {
+		t.Fatalf("record = %+v", record)
+	}
+	if record.Data["resource"] != "user:42" || record.Data["value"] != 3600 {
+		t.Fatalf("record data = %v", record.Data)
+	}
+	if _, ok := record.Data["module"]; ok {
+		t.Error("module is duplicated in data")
+	}
+}
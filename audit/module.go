@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+var (
+	_ core.Module           = (*Module)(nil)
+	_ core.EventAwareModule = (*Module)(nil)
+	_ core.APIModule        = (*Module)(nil)
+)
+
+// Module сохраняет события в журнал аудита и отдает записи по HTTP
+//
+// Роутер подписывает модуль на события журнала (по умолчанию command.executed,
+// которое роутер публикует после каждого обработчика, и user.action).
+// Устаревшие записи удаляются в фоне раз в Options.CleanupInterval.
+//
+// HTTP API (/api/v1/audit/...): GET /records?user_id=&module=&type=&from=&to=&limit=,
+// from и to - RFC 3339 или unix-время в секундах.
+type Module struct {
+	log    *Log
+	logger core.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewModule создает модуль журнала аудита
+func NewModule(log *Log, logger core.Logger) *Module {
+	return &Module{
+		log:    log,
+		logger: logger,
+	}
+}
+
+func (m *Module) Name() string                      { return "audit" }
+func (m *Module) Version() string                   { return "1.0.0" }
+func (m *Module) Routes() []core.RoutePattern       { return nil }
+func (m *Module) Init(deps core.Dependencies) error { return nil }
+
+// Start запускает удаление устаревших записей
+func (m *Module) Start(ctx context.Context) error {
+	cleanupCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	m.wg.Add(1)
+	go m.cleanupLoop(cleanupCtx)
+	return nil
+}
+
+// Stop останавливает удаление устаревших записей
+func (m *Module) Stop(ctx context.Context) error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events возвращает подписки на события журнала
+func (m *Module) Events() []core.EventSubscription {
+	types := m.log.Events()
+	subscriptions := make([]core.EventSubscription, 0, len(types))
+	for _, eventType := range types {
+		subscriptions = append(subscriptions, core.EventSubscription{
+			EventType: eventType,
+			Handler:   m.HandleEvent,
+		})
+	}
+	return subscriptions
+}
+
+// HandleEvent сохраняет событие в журнал
+func (m *Module) HandleEvent(ctx context.Context, event core.Event) error {
+	if err := m.log.AppendEvent(ctx, event); err != nil {
+		if m.logger != nil {
+			m.logger.Error("Failed to write audit record", "type", event.Type(), "error", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// APIHandlers возвращает HTTP endpoints
+func (m *Module) APIHandlers() []core.APIHandler {
+	return []core.APIHandler{
+		{Method: "GET", Path: "/records", Handler: m.apiRecords, Admin: true, Description: "Записи журнала аудита по пользователю, модулю и времени"},
+	}
+}
+
+func (m *Module) apiRecords(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	query, err := queryOf(req.Query)
+	if err != nil {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": err.Error()}}, nil
+	}
+
+	records, err := m.log.Find(ctx, query)
+	if errors.Is(err, ErrInvalidQuery) {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": err.Error()}}, nil
+	}
+	if err != nil {
+		return core.APIResponse{}, err
+	}
+
+	return core.APIResponse{Body: map[string]interface{}{
+		"records": records,
+		"count":   len(records),
+	}}, nil
+}
+
+// cleanupLoop удаляет устаревшие записи при запуске и раз в CleanupInterval
+func (m *Module) cleanupLoop(ctx context.Context) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.log.opts.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := m.log.Cleanup(ctx)
+		if m.logger != nil {
+			if err != nil && ctx.Err() == nil {
+				m.logger.Error("Failed to clean up audit log", "error", err)
+			} else if deleted > 0 {
+				m.logger.Info("Audit records expired", "deleted", deleted)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// queryOf разбирает параметры запроса API
+func queryOf(params map[string]string) (Query, error) {
+	query := Query{
+		Module: params["module"],
+		Type:   params["type"],
+	}
+
+	if value := params["user_id"]; value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return query, errors.New("user_id must be a number")
+		}
+		query.UserID = id
+	}
+
+	if value := params["limit"]; value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return query, errors.New("limit must be a positive number")
+		}
+		query.Limit = limit
+	}
+
+	var err error
+	if query.From, err = parseTime(params["from"]); err != nil {
+		return query, errors.New("from must be RFC 3339 or unix time")
+	}
+	if query.To, err = parseTime(params["to"]); err != nil {
+		return query, errors.New("to must be RFC 3339 or unix time")
+	}
+
+	return query, nil
+}
+
+// parseTime разбирает время в RFC 3339 или unix-время в секундах (пустая строка - нулевое время)
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
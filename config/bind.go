@@ -0,0 +1,340 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Validator структура конфигурации с собственной проверкой
+// Validate вызывается после заполнения полей и проверки тегов validate
+type Validator interface {
+	Validate() error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Bind заполняет структуру target значениями секции key и проверяет ее
+//
+// Теги полей:
+//
+//	config:"max_opponents"      - ключ поля (по умолчанию имя поля в snake_case, "-" - пропустить)
+//	default:"5"                 - значение, если ключ не задан
+//	validate:"required,min=1"   - проверки: required, min, max (для строк, списков
+//	                              и мап - длина), oneof=a b c
+//
+// Вложенные структуры читаются из вложенных секций. Переменные окружения
+// (SetEnvPrefix) применяются к каждому полю. Все ошибки возвращаются вместе
+// и оборачивают ErrInvalidConfig
+func (c *Config) Bind(key string, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: bind target must be a pointer to struct, got %T", target)
+	}
+
+	section := c.Sub(key)
+
+	var errs []error
+	section.bindStruct(value.Elem(), "", &errs)
+
+	if len(errs) == 0 {
+		if validator, ok := target.(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		name := c.fullKey(key)
+		if name == "" {
+			name = "config"
+		}
+		return fmt.Errorf("%w %s: %w", ErrInvalidConfig, name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// bindStruct заполняет поля структуры значениями по ключам prefix + имя поля
+func (c *Config) bindStruct(target reflect.Value, prefix string, errs *[]error) {
+	targetType := target.Type()
+
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("config")
+		if tag == "-" {
+			continue
+		}
+
+		fieldValue := target.Field(i)
+
+		// Встроенная структура без тега читается из той же секции
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			c.bindStruct(fieldValue, prefix, errs)
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			c.bindStruct(fieldValue, key, errs)
+			continue
+		}
+
+		raw, set := c.lookup(key)
+		if !set {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				raw, set = def, true
+			}
+		}
+
+		if set {
+			if err := assign(fieldValue, raw); err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+		}
+
+		if rules := field.Tag.Get("validate"); rules != "" {
+			if err := validateField(fieldValue, rules, set); err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+}
+
+// assign записывает значение конфигурации в поле
+func assign(target reflect.Value, raw interface{}) error {
+	if target.Type() == durationType {
+		d, ok := toDuration(raw)
+		if !ok {
+			return fmt.Errorf("invalid duration %v", raw)
+		}
+		target.SetInt(int64(d))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		s, ok := toString(raw)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", raw)
+		}
+		target.SetString(s)
+
+	case reflect.Bool:
+		b, ok := toBool(raw)
+		if !ok {
+			return fmt.Errorf("invalid bool %v", raw)
+		}
+		target.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(raw)
+		if !ok || target.OverflowInt(i) {
+			return fmt.Errorf("invalid %s %v", target.Kind(), raw)
+		}
+		target.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := toInt64(raw)
+		if !ok || i < 0 || target.OverflowUint(uint64(i)) {
+			return fmt.Errorf("invalid %s %v", target.Kind(), raw)
+		}
+		target.SetUint(uint64(i))
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(raw)
+		if !ok || target.OverflowFloat(f) {
+			return fmt.Errorf("invalid %s %v", target.Kind(), raw)
+		}
+		target.SetFloat(f)
+
+	case reflect.Slice:
+		items, ok := raw.([]interface{})
+		if !ok {
+			strs, ok := toStringSlice(raw)
+			if !ok {
+				return fmt.Errorf("expected list, got %T", raw)
+			}
+			items = make([]interface{}, len(strs))
+			for i, s := range strs {
+				items[i] = s
+			}
+		}
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assign(slice.Index(i), item); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		target.Set(slice)
+
+	case reflect.Map:
+		values, ok := raw.(map[string]interface{})
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("expected map, got %T", raw)
+		}
+		result := reflect.MakeMapWithSize(target.Type(), len(values))
+		for k, v := range values {
+			item := reflect.New(target.Type().Elem()).Elem()
+			if err := assign(item, v); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			result.SetMapIndex(reflect.ValueOf(k).Convert(target.Type().Key()), item)
+		}
+		target.Set(result)
+
+	case reflect.Pointer:
+		item := reflect.New(target.Type().Elem())
+		if err := assign(item.Elem(), raw); err != nil {
+			return err
+		}
+		target.Set(item)
+
+	case reflect.Struct:
+		values, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected section, got %T", raw)
+		}
+		var errs []error
+		FromMap(values).bindStruct(target, "", &errs)
+		return errors.Join(errs...)
+
+	case reflect.Interface:
+		if raw != nil {
+			target.Set(reflect.ValueOf(raw))
+		}
+
+	default:
+		return fmt.Errorf("unsupported field type %s", target.Type())
+	}
+
+	return nil
+}
+
+// validateField проверяет поле по правилам тега validate
+// Указатель проверяется по значению, nil - как незаданное поле
+func validateField(value reflect.Value, rules string, set bool) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			set = false
+		} else {
+			value = value.Elem()
+		}
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "":
+			continue
+		case "required":
+			if !set || value.IsZero() {
+				return errors.New("is required")
+			}
+		case "min", "max":
+			if !set {
+				continue
+			}
+			if err := checkBound(value, name, arg); err != nil {
+				return err
+			}
+		case "oneof":
+			if !set {
+				continue
+			}
+			actual := fmt.Sprint(value.Interface())
+			allowed := strings.Fields(arg)
+			found := false
+			for _, option := range allowed {
+				if option == actual {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("must be one of [%s], got %q", strings.Join(allowed, " "), actual)
+			}
+		default:
+			return fmt.Errorf("unknown validation rule %q", name)
+		}
+	}
+	return nil
+}
+
+// checkBound проверяет min/max: числа и длительности по значению, строки, списки и мапы по длине
+func checkBound(value reflect.Value, rule, arg string) error {
+	var actual, bound float64
+
+	switch {
+	case value.Type() == durationType:
+		d, ok := toDuration(arg)
+		if !ok {
+			return fmt.Errorf("invalid %s bound %q", rule, arg)
+		}
+		actual, bound = float64(value.Int()), float64(d)
+
+	default:
+		b, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s bound %q", rule, arg)
+		}
+		bound = b
+
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			actual = float64(value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			actual = float64(value.Uint())
+		case reflect.Float32, reflect.Float64:
+			actual = value.Float()
+		case reflect.String, reflect.Slice, reflect.Map:
+			actual = float64(value.Len())
+		default:
+			return fmt.Errorf("%s is not supported for %s", rule, value.Type())
+		}
+	}
+
+	if rule == "min" && actual < bound {
+		return fmt.Errorf("must be >= %s", arg)
+	}
+	if rule == "max" && actual > bound {
+		return fmt.Errorf("must be <= %s", arg)
+	}
+	return nil
+}
+
+// snakeCase переводит имя поля в snake_case: MaxOpponents -> max_opponents, APIKey -> api_key
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,487 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+)
+
+// ModulesKey секция конфигурации модулей: настройки модуля arena лежат в "modules.arena"
+const ModulesKey = core.ModulesConfigKey
+
+var (
+	// ErrUnsupportedFormat формат файла конфигурации не поддерживается
+	ErrUnsupportedFormat = errors.New("unsupported config format")
+
+	// ErrInvalidConfig конфигурация не прошла проверку при привязке к структуре
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+var (
+	_ core.Config          = (*Config)(nil)
+	_ core.SectionedConfig = (*Config)(nil)
+)
+
+// Config конфигурация бота (реализует core.Config и core.SectionedConfig)
+//
+// Значения собираются из слоев по возрастанию приоритета: значения по умолчанию
+// (SetDefault), файлы YAML/JSON/TOML (LoadFile, более поздний файл перекрывает ранний),
+// переменные окружения (SetEnvPrefix) и значения Set. Ключи - пути через точку:
+// "telegram.token", "modules.arena.max_opponents".
+// Секция (Sub) - представление той же конфигурации с префиксом ключей.
+//
+// Изменения (Reload, Watch, Set) применяются атомарно: читатели видят либо старые,
+// либо новые значения целиком. Об изменившихся ключах сообщает событие "config.changed"
+// (SetEventBus)
+type Config struct {
+	store  *store
+	prefix string
+}
+
+// store общее состояние конфигурации и ее секций
+// Читатели получают неизменяемый снимок без блокировок, изменения создают новый снимок
+type store struct {
+	mu      sync.Mutex
+	current atomic.Pointer[snapshot]
+	sources []source
+	bus     core.EventBus
+}
+
+// source источник слоя файлов
+type source struct {
+	// path файл, перечитываемый при Reload (пусто - значения загружены из памяти)
+	path   string
+	format Format
+
+	// section путь секции, в которую загружен источник
+	section []string
+	values  map[string]interface{}
+}
+
+// snapshot неизменяемый снимок слоев конфигурации
+type snapshot struct {
+	defaults  map[string]interface{}
+	files     map[string]interface{}
+	overrides map[string]interface{}
+	merged    map[string]interface{}
+	envPrefix string
+}
+
+// New создает пустую конфигурацию
+func New() *Config {
+	s := &store{}
+	s.current.Store(&snapshot{merged: map[string]interface{}{}})
+	return &Config{store: s}
+}
+
+// Load создает конфигурацию из файлов (формат определяется по расширению)
+func Load(paths ...string) (*Config, error) {
+	c := New()
+	for _, path := range paths {
+		if err := c.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// FromMap создает конфигурацию из готовых значений
+func FromMap(values map[string]interface{}) *Config {
+	c := New()
+	_ = c.addSource(source{values: normalizeMap(values)})
+	return c
+}
+
+// ModuleSection возвращает секцию конфигурации модуля ("modules.<name>")
+// Если конфигурация не поддерживает секции, возвращается пустая конфигурация
+func ModuleSection(cfg core.Config, name string) core.Config {
+	if sectioned, ok := cfg.(core.SectionedConfig); ok {
+		return sectioned.Section(ModuleKey(name))
+	}
+	return New()
+}
+
+// ModuleKey возвращает ключ секции модуля
+func ModuleKey(name string) string {
+	return ModulesKey + "." + name
+}
+
+// SetEnvPrefix включает переопределение ключей переменными окружения
+// С префиксом "BOTKIT" ключ "telegram.token" читается из BOTKIT_TELEGRAM_TOKEN,
+// "modules.arena.max_opponents" - из BOTKIT_MODULES_ARENA_MAX_OPPONENTS.
+// Пустой префикс выключает переопределение
+func (c *Config) SetEnvPrefix(prefix string) {
+	c.update("env", func(s *snapshot) error {
+		s.envPrefix = strings.ToUpper(strings.TrimSuffix(prefix, "_"))
+		return nil
+	})
+}
+
+// SetEventBus включает публикацию события "config.changed" при изменении значений
+func (c *Config) SetEventBus(bus core.EventBus) {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+	c.store.bus = bus
+}
+
+// Sub возвращает секцию конфигурации: ключи секции задаются относительно key
+func (c *Config) Sub(key string) *Config {
+	if key == "" {
+		return c
+	}
+	return &Config{store: c.store, prefix: c.fullKey(key) + "."}
+}
+
+// Section возвращает секцию конфигурации (реализует core.SectionedConfig)
+func (c *Config) Section(key string) core.Config {
+	return c.Sub(key)
+}
+
+// Get получает значение по ключу
+func (c *Config) Get(key string) interface{} {
+	value, _ := c.lookup(key)
+	return value
+}
+
+// IsSet проверяет наличие ключа (в любом слое, включая окружение)
+func (c *Config) IsSet(key string) bool {
+	_, ok := c.lookup(key)
+	return ok
+}
+
+// Set устанавливает значение (перекрывает файлы и окружение)
+func (c *Config) Set(key string, value interface{}) {
+	path := splitKey(c.fullKey(key))
+	c.update("set", func(s *snapshot) error {
+		s.overrides = setPath(s.overrides, path, value)
+		return nil
+	})
+}
+
+// SetDefault устанавливает значение по умолчанию (самый низкий приоритет)
+func (c *Config) SetDefault(key string, value interface{}) {
+	path := splitKey(c.fullKey(key))
+	c.update("default", func(s *snapshot) error {
+		s.defaults = setPath(s.defaults, path, value)
+		return nil
+	})
+}
+
+// GetString получает строковое значение
+func (c *Config) GetString(key string) string {
+	value, _ := toString(c.Get(key))
+	return value
+}
+
+// GetInt получает целочисленное значение
+func (c *Config) GetInt(key string) int {
+	value, _ := toInt64(c.Get(key))
+	return int(value)
+}
+
+// GetBool получает булево значение
+func (c *Config) GetBool(key string) bool {
+	value, _ := toBool(c.Get(key))
+	return value
+}
+
+// GetFloat64 получает дробное значение
+func (c *Config) GetFloat64(key string) float64 {
+	value, _ := toFloat64(c.Get(key))
+	return value
+}
+
+// GetDuration получает длительность: строка "1m30s" или число секунд
+func (c *Config) GetDuration(key string) time.Duration {
+	value, _ := toDuration(c.Get(key))
+	return value
+}
+
+// GetStringSlice получает массив строк (строка окружения разбивается по запятым)
+func (c *Config) GetStringSlice(key string) []string {
+	value, _ := toStringSlice(c.Get(key))
+	return value
+}
+
+// GetStringMap получает мапу строк
+func (c *Config) GetStringMap(key string) map[string]string {
+	values, ok := c.Get(key).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[k], _ = toString(v)
+	}
+	return result
+}
+
+// GetStringOr получает строковое значение или def, если ключ не задан
+func (c *Config) GetStringOr(key string, def string) string {
+	if value, ok := toString(c.Get(key)); ok {
+		return value
+	}
+	return def
+}
+
+// GetIntOr получает целочисленное значение или def, если ключ не задан или некорректен
+func (c *Config) GetIntOr(key string, def int) int {
+	if value, ok := toInt64(c.Get(key)); ok {
+		return int(value)
+	}
+	return def
+}
+
+// GetBoolOr получает булево значение или def, если ключ не задан или некорректен
+func (c *Config) GetBoolOr(key string, def bool) bool {
+	if value, ok := toBool(c.Get(key)); ok {
+		return value
+	}
+	return def
+}
+
+// GetFloat64Or получает дробное значение или def, если ключ не задан или некорректен
+func (c *Config) GetFloat64Or(key string, def float64) float64 {
+	if value, ok := toFloat64(c.Get(key)); ok {
+		return value
+	}
+	return def
+}
+
+// GetDurationOr получает длительность или def, если ключ не задан или некорректен
+func (c *Config) GetDurationOr(key string, def time.Duration) time.Duration {
+	if value, ok := toDuration(c.Get(key)); ok {
+		return value
+	}
+	return def
+}
+
+// GetStringSliceOr получает массив строк или def, если ключ не задан
+func (c *Config) GetStringSliceOr(key string, def []string) []string {
+	if value, ok := toStringSlice(c.Get(key)); ok {
+		return value
+	}
+	return def
+}
+
+// AllSettings возвращает копию значений секции (без переменных окружения)
+func (c *Config) AllSettings() map[string]interface{} {
+	snap := c.store.current.Load()
+
+	tree := snap.merged
+	if c.prefix != "" {
+		value, _ := lookupPath(snap.merged, splitKey(strings.TrimSuffix(c.prefix, ".")))
+		tree, _ = value.(map[string]interface{})
+	}
+	return cloneMap(tree)
+}
+
+// Keys возвращает отсортированные ключи значений секции ("telegram.token")
+func (c *Config) Keys() []string {
+	var keys []string
+	flatten(c.AllSettings(), "", func(key string, _ interface{}) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// lookup ищет значение: Set, затем окружение, затем файлы и значения по умолчанию
+func (c *Config) lookup(key string) (interface{}, bool) {
+	full := c.fullKey(key)
+	path := splitKey(full)
+	snap := c.store.current.Load()
+
+	if value, ok := lookupPath(snap.overrides, path); ok {
+		return value, true
+	}
+	if snap.envPrefix != "" {
+		if value, ok := os.LookupEnv(envName(snap.envPrefix, full)); ok {
+			return value, true
+		}
+	}
+	return lookupPath(snap.merged, path)
+}
+
+// update применяет изменение к копии снимка и атомарно публикует новый снимок
+// При ошибке изменения снимок не меняется. Об изменившихся ключах сообщает событие
+func (c *Config) update(reason string, change func(s *snapshot) error) error {
+	c.store.mu.Lock()
+
+	prev := c.store.current.Load()
+	next := *prev
+	if err := change(&next); err != nil {
+		c.store.mu.Unlock()
+		return err
+	}
+	next.merged = mergeMaps(mergeMaps(next.defaults, next.files), next.overrides)
+	c.store.current.Store(&next)
+	bus := c.store.bus
+
+	c.store.mu.Unlock()
+
+	if bus != nil {
+		if changed := changedKeys(prev.merged, next.merged); len(changed) > 0 {
+			bus.PublishAsync(context.Background(), events.NewConfigChangedEvent(changed, reason))
+		}
+	}
+	return nil
+}
+
+// changedKeys возвращает отсортированные ключи, значения которых различаются
+func changedKeys(prev, next map[string]interface{}) []string {
+	before := make(map[string]interface{})
+	flatten(prev, "", func(key string, value interface{}) { before[key] = value })
+
+	var changed []string
+	flatten(next, "", func(key string, value interface{}) {
+		old, ok := before[key]
+		delete(before, key)
+		if !ok || !reflect.DeepEqual(old, value) {
+			changed = append(changed, key)
+		}
+	})
+	for key := range before {
+		changed = append(changed, key)
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// fullKey возвращает ключ относительно корня конфигурации
+func (c *Config) fullKey(key string) string {
+	if key == "" {
+		return strings.TrimSuffix(c.prefix, ".")
+	}
+	return c.prefix + key
+}
+
+// envName возвращает имя переменной окружения для ключа
+func envName(prefix, key string) string {
+	name := strings.NewReplacer(".", "_", "-", "_").Replace(key)
+	return prefix + "_" + strings.ToUpper(name)
+}
+
+// splitKey разбивает ключ на части пути
+func splitKey(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, ".")
+}
+
+// lookupPath ищет значение по пути в дереве (числовая часть пути - индекс списка)
+func lookupPath(tree map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = tree
+	for _, part := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	if current == nil {
+		return nil, false
+	}
+	return current, true
+}
+
+// setPath возвращает копию дерева со значением по пути (исходное дерево не меняется)
+func setPath(tree map[string]interface{}, path []string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(tree)+1)
+	for k, v := range tree {
+		result[k] = v
+	}
+	if len(path) == 0 {
+		return result
+	}
+
+	if len(path) == 1 {
+		result[path[0]] = normalize(value)
+		return result
+	}
+
+	child, _ := result[path[0]].(map[string]interface{})
+	result[path[0]] = setPath(child, path[1:], value)
+	return result
+}
+
+// mergeMaps возвращает глубокое объединение деревьев: значения over перекрывают base
+func mergeMaps(base, over map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(over))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range over {
+		if overMap, ok := v.(map[string]interface{}); ok {
+			if baseMap, ok := result[k].(map[string]interface{}); ok {
+				result[k] = mergeMaps(baseMap, overMap)
+				continue
+			}
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// cloneMap возвращает глубокую копию дерева
+func cloneMap(tree map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(tree))
+	for k, v := range tree {
+		result[k] = cloneValue(v)
+	}
+	return result
+}
+
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = cloneValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// flatten обходит листья дерева с полными ключами
+func flatten(tree map[string]interface{}, prefix string, visit func(key string, value interface{})) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if child, ok := v.(map[string]interface{}); ok && len(child) > 0 {
+			flatten(child, key, visit)
+			continue
+		}
+		visit(key, v)
+	}
+}
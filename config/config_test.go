@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const baseYAML = `
+telegram:
+  token: file-token
+  timeout: 10s
+modules:
+  arena:
+    max_opponents: 3
+    modes: [duel, team]
+`
+
+const overrideJSON = `{"modules": {"arena": {"max_opponents": 8}, "shop": {"currency": "gold"}}}`
+
+func TestConfigLayers(t *testing.T) {
+	t.Setenv("BOTKIT_TELEGRAM_TOKEN", "env-token")
+	t.Setenv("BOTKIT_MODULES_SHOP_CURRENCY", "gems")
+
+	cfg := New()
+	cfg.SetDefault("telegram.token", "default-token")
+	cfg.SetDefault("telegram.debug", true)
+	if err := cfg.LoadBytes([]byte(baseYAML), FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.LoadBytes([]byte(overrideJSON), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Set("modules.arena.max_opponents", 12)
+
+	tests := []struct {
+		name   string
+		env    bool
+		key    string
+		want   interface{}
+		absent bool
+	}{
+		{name: "default", key: "telegram.debug", want: true},
+		{name: "file over default", key: "telegram.token", want: "file-token"},
+		{name: "env over file", env: true, key: "telegram.token", want: "env-token"},
+		{name: "later file keeps earlier keys", key: "modules.arena.modes.1", want: "team"},
+		{name: "later file adds keys", key: "modules.shop.currency", want: "gold"},
+		{name: "env over later file", env: true, key: "modules.shop.currency", want: "gems"},
+		{name: "set over files", key: "modules.arena.max_opponents", want: 12},
+		{name: "set over env", env: true, key: "modules.arena.max_opponents", want: 12},
+		{name: "missing", key: "modules.chat.enabled", absent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env {
+				cfg.SetEnvPrefix("BOTKIT_")
+				defer cfg.SetEnvPrefix("")
+			}
+
+			if tt.absent {
+				if cfg.IsSet(tt.key) {
+					t.Fatalf("%s is set: %v", tt.key, cfg.Get(tt.key))
+				}
+				return
+			}
+			if got := cfg.Get(tt.key); got != tt.want {
+				t.Fatalf("Get(%q) = %v (%T), want %v (%T)", tt.key, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigSection(t *testing.T) {
+	cfg := FromMap(map[string]interface{}{
+		"modules": map[string]interface{}{
+			"arena": map[string]interface{}{"max_opponents": 3, "timeout": "30s"},
+		},
+	})
+
+	arena := ModuleSection(cfg, "arena")
+	if arena.GetInt("max_opponents") != 3 {
+		t.Fatalf("max_opponents = %d, want 3", arena.GetInt("max_opponents"))
+	}
+
+	// Секция - представление той же конфигурации
+	arena.Set("max_opponents", 5)
+	if cfg.GetInt("modules.arena.max_opponents") != 5 {
+		t.Fatalf("section Set is not visible in the root config")
+	}
+	if d := cfg.Sub("modules.arena").GetDuration("timeout"); d != 30*time.Second {
+		t.Fatalf("timeout = %s, want 30s", d)
+	}
+	if keys := strings.Join(cfg.Sub("modules").Keys(), ","); keys != "arena.max_opponents,arena.timeout" {
+		t.Fatalf("Keys() = %s", keys)
+	}
+}
+
+// arenaConfig структура настроек модуля для Bind
+type arenaConfig struct {
+	MaxOpponents int           `config:"max_opponents" default:"5" validate:"min=1,max=10"`
+	Mode         string        `default:"duel" validate:"oneof=duel team"`
+	Timeout      time.Duration `default:"30s" validate:"min=1s"`
+	Modes        []string
+	APIKey       string `validate:"required"`
+	Rewards      struct {
+		Gold int `default:"100"`
+	}
+	Skipped string `config:"-"`
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		env     map[string]string
+		check   func(t *testing.T, c arenaConfig)
+		wantErr []string
+	}{
+		{
+			name:   "defaults",
+			values: map[string]interface{}{"api_key": "k"},
+			check: func(t *testing.T, c arenaConfig) {
+				if c.MaxOpponents != 5 || c.Mode != "duel" || c.Timeout != 30*time.Second || c.Rewards.Gold != 100 {
+					t.Fatalf("config = %+v", c)
+				}
+			},
+		},
+		{
+			name: "values and conversions",
+			values: map[string]interface{}{
+				"api_key":       "k",
+				"max_opponents": "7",
+				"mode":          "team",
+				"timeout":       "1m",
+				"modes":         []interface{}{"duel", "team"},
+				"rewards":       map[string]interface{}{"gold": 250},
+				"skipped":       "ignored",
+			},
+			check: func(t *testing.T, c arenaConfig) {
+				if c.MaxOpponents != 7 || c.Mode != "team" || c.Timeout != time.Minute || c.Rewards.Gold != 250 {
+					t.Fatalf("config = %+v", c)
+				}
+				if strings.Join(c.Modes, ",") != "duel,team" || c.Skipped != "" {
+					t.Fatalf("config = %+v", c)
+				}
+			},
+		},
+		{
+			name:   "env overrides fields",
+			values: map[string]interface{}{"api_key": "k", "max_opponents": 2},
+			env: map[string]string{
+				"BOTKIT_MODULES_ARENA_MAX_OPPONENTS": "9",
+				"BOTKIT_MODULES_ARENA_REWARDS_GOLD":  "50",
+				"BOTKIT_MODULES_ARENA_MODES":         "duel,team",
+			},
+			check: func(t *testing.T, c arenaConfig) {
+				if c.MaxOpponents != 9 || c.Rewards.Gold != 50 || strings.Join(c.Modes, ",") != "duel,team" {
+					t.Fatalf("config = %+v", c)
+				}
+			},
+		},
+		{
+			name:    "required",
+			values:  map[string]interface{}{},
+			wantErr: []string{"modules.arena", "api_key: is required"},
+		},
+		{
+			name:    "bounds",
+			values:  map[string]interface{}{"api_key": "k", "max_opponents": 11, "timeout": "500ms"},
+			wantErr: []string{"max_opponents: must be <= 10", "timeout: must be >= 1s"},
+		},
+		{
+			name:    "oneof",
+			values:  map[string]interface{}{"api_key": "k", "mode": "solo"},
+			wantErr: []string{`mode: must be one of [duel team], got "solo"`},
+		},
+		{
+			name:    "invalid value",
+			values:  map[string]interface{}{"api_key": "k", "max_opponents": "many"},
+			wantErr: []string{"max_opponents: invalid int many"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg := FromMap(map[string]interface{}{
+				"modules": map[string]interface{}{"arena": tt.values},
+			})
+			cfg.SetEnvPrefix("BOTKIT")
+
+			var target arenaConfig
+			err := cfg.Bind(ModuleKey("arena"), &target)
+			if len(tt.wantErr) > 0 {
+				if !errors.Is(err, ErrInvalidConfig) {
+					t.Fatalf("err = %v, want ErrInvalidConfig", err)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("err = %v, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, target)
+		})
+	}
+}
+
+func TestBindTarget(t *testing.T) {
+	var notStruct int
+	if err := New().Bind("", &notStruct); err == nil {
+		t.Fatal("Bind accepted a pointer to int")
+	}
+	if err := New().Bind("", arenaConfig{}); err == nil {
+		t.Fatal("Bind accepted a struct value")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"MaxOpponents": "max_opponents",
+		"APIKey":       "api_key",
+		"UserID":       "user_id",
+		"Gold":         "gold",
+		"HTTPServer":   "http_server",
+	}
+	for name, want := range tests {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format формат файла конфигурации
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+)
+
+// FormatOf определяет формат по расширению файла (.yaml, .yml, .json, .toml)
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, path)
+	}
+}
+
+// LoadFile загружает файл в секцию конфигурации поверх ранее загруженных файлов
+// Файл запоминается и перечитывается в Reload и Watch
+func (c *Config) LoadFile(path string) error {
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+
+	values, err := readFile(path, format)
+	if err != nil {
+		return err
+	}
+	return c.addSource(source{path: path, format: format, values: values})
+}
+
+// LoadReader загружает конфигурацию указанного формата из r
+func (c *Config) LoadReader(r io.Reader, format Format) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	return c.LoadBytes(data, format)
+}
+
+// LoadBytes загружает конфигурацию указанного формата поверх ранее загруженных файлов
+func (c *Config) LoadBytes(data []byte, format Format) error {
+	values, err := decode(data, format)
+	if err != nil {
+		return err
+	}
+	return c.addSource(source{values: values})
+}
+
+// Reload перечитывает файлы конфигурации и атомарно заменяет значения
+// Если хотя бы один файл не читается или не разбирается, остаются прежние значения.
+// reason передается в событие "config.changed": file, signal, admin
+func (c *Config) Reload(reason string) error {
+	return c.update(reason, func(s *snapshot) error {
+		sources := make([]source, len(c.store.sources))
+		copy(sources, c.store.sources)
+
+		for i := range sources {
+			if sources[i].path == "" {
+				continue
+			}
+			values, err := readFile(sources[i].path, sources[i].format)
+			if err != nil {
+				return err
+			}
+			sources[i].values = values
+		}
+
+		c.store.sources = sources
+		s.files = mergeSources(sources)
+		return nil
+	})
+}
+
+// addSource добавляет источник в секцию конфигурации
+func (c *Config) addSource(src source) error {
+	src.section = splitKey(strings.TrimSuffix(c.prefix, "."))
+	return c.update("load", func(s *snapshot) error {
+		c.store.sources = append(c.store.sources, src)
+		s.files = mergeSources(c.store.sources)
+		return nil
+	})
+}
+
+// filePaths возвращает файлы источников
+func (c *Config) filePaths() []string {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	var paths []string
+	for _, src := range c.store.sources {
+		if src.path != "" {
+			paths = append(paths, src.path)
+		}
+	}
+	return paths
+}
+
+// mergeSources объединяет источники в порядке загрузки
+func mergeSources(sources []source) map[string]interface{} {
+	files := make(map[string]interface{})
+	for _, src := range sources {
+		if len(src.section) == 0 {
+			files = mergeMaps(files, src.values)
+			continue
+		}
+		current, _ := lookupPath(files, src.section)
+		section, _ := current.(map[string]interface{})
+		files = setPath(files, src.section, mergeMaps(section, src.values))
+	}
+	return files
+}
+
+// readFile читает и разбирает файл конфигурации
+func readFile(path string, format Format) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	values, err := decode(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// decode разбирает конфигурацию в дерево map[string]interface{}
+func decode(data []byte, format Format) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	var err error
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &values)
+	case FormatJSON:
+		err = json.Unmarshal(data, &values)
+	case FormatTOML:
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", format, err)
+	}
+
+	return normalizeMap(values), nil
+}
+
+// normalizeMap приводит дерево к map[string]interface{} и []interface{} на всех уровнях
+func normalizeMap(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[k] = normalize(v)
+	}
+	return result
+}
+
+// normalize приводит значение, полученное из декодера или Set, к виду дерева
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return normalizeMap(v)
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[fmt.Sprint(k)] = normalize(item)
+		}
+		return result
+	case map[string]string:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[k] = item
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalize(item)
+		}
+		return result
+	case []map[string]interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeMap(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
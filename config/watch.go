@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce задержка перезагрузки после изменения файла
+// (редакторы и деплой записывают файл несколькими операциями)
+const DefaultWatchDebounce = 200 * time.Millisecond
+
+// configMapData символическая ссылка Kubernetes ConfigMap на текущую версию файлов:
+// app.yaml -> ..data/app.yaml, ..data -> ..2024_05_01_10_00_00.123. Kubelet обновляет
+// ConfigMap атомарной заменой ..data, а сами ссылки app.yaml не меняются
+const configMapData = "..data"
+
+// Watch перечитывает конфигурацию при изменении загруженных файлов и по сигналу SIGHUP
+//
+// Отслеживаются каталоги файлов, поэтому замена файла переименованием (редакторы)
+// тоже замечается, а для смонтированного ConfigMap - замена ссылки ..data в каталоге.
+// Ошибка перезагрузки пишется в logger, прежние значения сохраняются.
+// Наблюдение прекращается при отмене ctx
+func (c *Config) Watch(ctx context.Context, logger core.Logger) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, path := range c.filePaths() {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch config %s: %w", path, err)
+		}
+		files[abs] = true
+		dirs[filepath.Dir(abs)] = true
+	}
+
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch config directory %s: %w", dir, err)
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go c.watch(ctx, watcher, signals, files, logger)
+	return nil
+}
+
+// watch обрабатывает события файлов и сигналы до отмены ctx
+func (c *Config) watch(ctx context.Context, watcher *fsnotify.Watcher, signals chan os.Signal, files map[string]bool, logger core.Logger) {
+	defer watcher.Close()
+	defer signal.Stop(signals)
+
+	debounce := time.NewTimer(DefaultWatchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !watched(event, files) {
+				continue
+			}
+			debounce.Reset(DefaultWatchDebounce)
+
+		case <-debounce.C:
+			c.reloadLogged("file", logger)
+
+		case <-signals:
+			c.reloadLogged("signal", logger)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			if logger != nil {
+				logger.Warn("Config watcher error", "error", err)
+			}
+		}
+	}
+}
+
+// watched проверяет, что событие меняет загруженный файл или версию ConfigMap
+func watched(event fsnotify.Event, files map[string]bool) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+		return false
+	}
+
+	// Отслеживаются только каталоги файлов, поэтому ..data относится к одному из них
+	name := filepath.Clean(event.Name)
+	return files[name] || filepath.Base(name) == configMapData
+}
+
+// reloadLogged перезагружает конфигурацию и пишет результат в logger
+func (c *Config) reloadLogged(reason string, logger core.Logger) {
+	err := c.Reload(reason)
+	if logger == nil {
+		return
+	}
+
+	if err != nil {
+		logger.Error("Failed to reload config, keeping previous values", "error", err, "reason", reason)
+		return
+	}
+	logger.Info("Config reloaded", "reason", reason)
+}
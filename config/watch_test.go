@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeVersion создает каталог версии ConfigMap с app.yaml
+func writeVersion(t *testing.T, dir, version, content string) {
+	t.Helper()
+	if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, version, "app.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor ждет, пока значение ключа станет want
+func waitFor(t *testing.T, cfg *Config, key string, want int) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for cfg.GetInt(key) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s = %d, want %d", key, cfg.GetInt(key), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchReloads(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, dir string) string
+		update func(t *testing.T, dir string)
+	}{
+		{
+			name: "file rewritten",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "app.yaml")
+				if err := os.WriteFile(path, []byte("limit: 1\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			},
+			update: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("limit: 2\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			// Раскладка смонтированного ConfigMap: app.yaml -> ..data/app.yaml -> v1/app.yaml
+			name: "configmap data swapped",
+			setup: func(t *testing.T, dir string) string {
+				writeVersion(t, dir, "..v1", "limit: 1\n")
+				if err := os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+					t.Fatal(err)
+				}
+				path := filepath.Join(dir, "app.yaml")
+				if err := os.Symlink(filepath.Join("..data", "app.yaml"), path); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			},
+			update: func(t *testing.T, dir string) {
+				writeVersion(t, dir, "..v2", "limit: 2\n")
+				if err := os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := tt.setup(t, dir)
+
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if err := cfg.Watch(ctx, nil); err != nil {
+				t.Fatal(err)
+			}
+			waitFor(t, cfg, "limit", 1)
+
+			tt.update(t, dir)
+			waitFor(t, cfg, "limit", 2)
+		})
+	}
+}
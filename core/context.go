@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"sync"
 	"time"
 )
 
@@ -10,6 +11,10 @@ type UniversalContext interface {
 	// Context возвращает базовый контекст Go
 	Context() context.Context
 	
+	// WithContext заменяет базовый контекст Go (таймауты, отмена)
+	// Последующие обработчики получат новый контекст через Context()
+	WithContext(ctx context.Context)
+	
 	// === Идентификаторы ===
 	
 	// GetUserID возвращает ID пользователя
@@ -38,6 +43,24 @@ type UniversalContext interface {
 	// GetProfile возвращает профиль пользователя (если загружен)
 	GetProfile() *Profile
 	
+	// === Чат ===
+	
+	// GetChatType возвращает тип чата (private, group, supergroup, channel)
+	GetChatType() ChatType
+	
+	// IsBotMentioned проверяет, упомянут ли бот в сообщении
+	IsBotMentioned() bool
+	
+	// IsAddressedToBot проверяет, адресовано ли сообщение боту
+	// (личный чат, упоминание, ответ на сообщение бота или команда с @ботом)
+	IsAddressedToBot() bool
+	
+	// GetReplyTo возвращает информацию о сообщении, на которое ответил пользователь
+	GetReplyTo() *ReplyInfo
+	
+	// IsChatAdmin проверяет, является ли отправитель администратором чата
+	IsChatAdmin() bool
+	
 	// === Контент сообщения ===
 	
 	// GetText возвращает текст сообщения
@@ -111,10 +134,127 @@ type UniversalContext interface {
 	
 	// Get получает произвольное значение из контекста
 	Get(key string) (interface{}, bool)
+	
+	// === Действия ===
+	
+	// SendChatAction показывает индикатор действия бота (typing, upload_photo, ...)
+	// Если транспорт не поддерживает действия, вызов игнорируется
+	SendChatAction(action ChatAction) error
+}
+
+// ChatType тип чата
+type ChatType string
+
+const (
+	ChatTypePrivate    ChatType = "private"
+	ChatTypeGroup      ChatType = "group"
+	ChatTypeSupergroup ChatType = "supergroup"
+	ChatTypeChannel    ChatType = "channel"
+)
+
+// IsGroup проверяет, является ли чат групповым
+func (t ChatType) IsGroup() bool {
+	return t == ChatTypeGroup || t == ChatTypeSupergroup
+}
+
+// ReplyInfo информация о сообщении, на которое ответил пользователь
+type ReplyInfo struct {
+	MessageID string `json:"message_id"`
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Text      string `json:"text"`
+	
+	// FromBot сообщение отправлено этим ботом
+	FromBot bool `json:"from_bot"`
+}
+
+// AdminChecker функция проверки прав администратора чата транспортом
+type AdminChecker func() (bool, error)
+
+// ChatAction действие бота в чате
+type ChatAction string
+
+const (
+	ChatActionTyping         ChatAction = "typing"
+	ChatActionUploadPhoto    ChatAction = "upload_photo"
+	ChatActionRecordVideo    ChatAction = "record_video"
+	ChatActionUploadVideo    ChatAction = "upload_video"
+	ChatActionRecordVoice    ChatAction = "record_voice"
+	ChatActionUploadVoice    ChatAction = "upload_voice"
+	ChatActionUploadDocument ChatAction = "upload_document"
+	ChatActionFindLocation   ChatAction = "find_location"
+)
+
+// ChatActionSender функция отправки действия транспортом
+type ChatActionSender func(action ChatAction) error
+
+// IdempotencyKey ключ значения (ctx.Get) с идентификатором доставки
+// Адаптеры заполняют его из update ID, заголовка Idempotency-Key или ID сообщения WebSocket,
+// повторная доставка того же запроса получает тот же ключ
+const IdempotencyKey = "idempotency_key"
+
+// RequestIDKey ключ значения (ctx.Get) с идентификатором запроса для логов
+// Роутер генерирует его, если адаптер не заполнил значение
+const RequestIDKey = "request_id"
+
+// LoggerKey ключ значения (ctx.Get) с логгером запроса
+// Роутер добавляет в логгер поля пользователя, чата, источника, маршрута и модуля
+const LoggerKey = "logger"
+
+// AddressedToOtherBotKey ключ значения (ctx.Get): команда в группе адресована другому боту
+// Telegram-адаптер такие обновления в роутер не передает
+const AddressedToOtherBotKey = "addressed_to_other_bot"
+
+// RateLimitStateKey ключ значения (ctx.Get) с состоянием лимита маршрута (routing.RateLimitState)
+// Устанавливается перед вызовом обработчика маршрута с ограничением скорости
+const RateLimitStateKey = "rate_limit"
+
+// ForwardedKey ключ значения (ctx.Get): сообщение переслано из другого чата (bool)
+const ForwardedKey = "forwarded"
+
+// LinksKey ключ значения (ctx.Get) с количеством ссылок в сообщении (int, включая скрытые)
+const LinksKey = "links"
+
+// IdempotentReplayKey ключ значения (ctx.Get): ответ повторной доставки взят из сохраненного (bool)
+// Устанавливает middleware идемпотентности
+const IdempotentReplayKey = "idempotent_replay"
+
+// CacheHitKey ключ значения (ctx.Get): ответ взят из кеша ответов (bool)
+// Устанавливает middleware кеширования
+const CacheHitKey = "cache_hit"
+
+// IsReservedKey проверяет, что ключ значения заполняют адаптер, роутер или middleware.
+// Адаптеры не принимают такие ключи из данных клиента: иначе клиент подменил бы
+// ключ идемпотентности чужого запроса, идентификатор запроса в логах или признаки запроса
+func IsReservedKey(key string) bool {
+	switch key {
+	case IdempotencyKey, RequestIDKey, LoggerKey,
+		AddressedToOtherBotKey, RateLimitStateKey, ForwardedKey, LinksKey,
+		IdempotentReplayKey, CacheHitKey:
+		return true
+	default:
+		return false
+	}
+}
+
+// LoggerFrom возвращает логгер запроса или fallback, если роутер его не установил
+func LoggerFrom(ctx UniversalContext, fallback Logger) Logger {
+	if ctx != nil {
+		if value, ok := ctx.Get(LoggerKey); ok {
+			if logger, ok := value.(Logger); ok && logger != nil {
+				return logger
+			}
+		}
+	}
+	return fallback
 }
 
 // BaseContext базовая реализация UniversalContext
+//
+// Контекст Go, параметры и значения защищены мьютексом: после таймаута
+// обработчик может продолжать их изменять, пока middleware формирует ответ
 type BaseContext struct {
+	mu         sync.RWMutex
 	ctx        context.Context
 	userID     int64
 	chatID     int64
@@ -136,6 +276,12 @@ type BaseContext struct {
 	timestamp  time.Time
 	original   interface{}
 	values     map[string]interface{}
+	actions    ChatActionSender
+	chatType   ChatType
+	mentioned  bool
+	addressed  bool
+	replyTo    *ReplyInfo
+	admins     AdminChecker
 }
 
 // NewBaseContext создает новый базовый контекст
@@ -152,7 +298,6 @@ func NewBaseContext(ctx context.Context) *BaseContext {
 }
 
 // Context implementation
-func (c *BaseContext) Context() context.Context                 { return c.ctx }
 func (c *BaseContext) GetUserID() int64                         { return c.userID }
 func (c *BaseContext) GetChatID() int64                         { return c.chatID }
 func (c *BaseContext) GetMessageID() string                     { return c.messageID }
@@ -175,17 +320,27 @@ func (c *BaseContext) GetOriginal() interface{}                 { return c.origi
 func (c *BaseContext) GetRoles() []string                       { return c.roles }
 func (c *BaseContext) IsAuthenticated() bool                    { return c.userID > 0 }
 
+func (c *BaseContext) Context() context.Context {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ctx
+}
+
 func (c *BaseContext) GetParam(key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	val, ok := c.params[key]
 	return val, ok
 }
 
 func (c *BaseContext) SetParam(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.params[key] = value
 }
 
 func (c *BaseContext) GetIntParam(key string) (int, bool) {
-	if val, ok := c.params[key]; ok {
+	if val, ok := c.GetParam(key); ok {
 		if i, ok := val.(int); ok {
 			return i, true
 		}
@@ -194,7 +349,7 @@ func (c *BaseContext) GetIntParam(key string) (int, bool) {
 }
 
 func (c *BaseContext) GetStringParam(key string) (string, bool) {
-	if val, ok := c.params[key]; ok {
+	if val, ok := c.GetParam(key); ok {
 		if s, ok := val.(string); ok {
 			return s, true
 		}
@@ -208,14 +363,54 @@ func (c *BaseContext) HasPermission(perm string) bool {
 }
 
 func (c *BaseContext) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.values[key] = value
 }
 
 func (c *BaseContext) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	val, ok := c.values[key]
 	return val, ok
 }
 
+func (c *BaseContext) GetChatType() ChatType  { return c.chatType }
+func (c *BaseContext) IsBotMentioned() bool   { return c.mentioned }
+func (c *BaseContext) GetReplyTo() *ReplyInfo { return c.replyTo }
+
+func (c *BaseContext) IsAddressedToBot() bool {
+	// Личный чат или транспорт без понятия чатов - всегда адресовано боту
+	if c.chatType == "" || c.chatType == ChatTypePrivate {
+		return true
+	}
+	return c.addressed || c.mentioned || (c.replyTo != nil && c.replyTo.FromBot)
+}
+
+func (c *BaseContext) IsChatAdmin() bool {
+	if c.admins == nil {
+		return false
+	}
+	isAdmin, err := c.admins()
+	return err == nil && isAdmin
+}
+
+func (c *BaseContext) SendChatAction(action ChatAction) error {
+	if c.actions == nil {
+		return nil
+	}
+	return c.actions(action)
+}
+
+// WithContext заменяет базовый контекст Go
+func (c *BaseContext) WithContext(ctx context.Context) {
+	if ctx != nil {
+		c.mu.Lock()
+		c.ctx = ctx
+		c.mu.Unlock()
+	}
+}
+
 // Setters for BaseContext
 func (c *BaseContext) SetUserID(id int64)           { c.userID = id }
 func (c *BaseContext) SetChatID(id int64)           { c.chatID = id }
@@ -234,6 +429,36 @@ func (c *BaseContext) SetSource(source string)      { c.source = source }
 func (c *BaseContext) SetLocale(locale string)      { c.locale = locale }
 func (c *BaseContext) SetOriginal(orig interface{}) { c.original = orig }
 
+// SetChatType устанавливает тип чата
+func (c *BaseContext) SetChatType(t ChatType) {
+	c.chatType = t
+}
+
+// SetBotMentioned отмечает упоминание бота
+func (c *BaseContext) SetBotMentioned(v bool) {
+	c.mentioned = v
+}
+
+// SetAddressed отмечает сообщение как адресованное боту (например, /cmd@bot)
+func (c *BaseContext) SetAddressed(v bool) {
+	c.addressed = v
+}
+
+// SetReplyTo устанавливает информацию об ответе
+func (c *BaseContext) SetReplyTo(reply *ReplyInfo) {
+	c.replyTo = reply
+}
+
+// SetAdminChecker устанавливает функцию проверки прав администратора
+func (c *BaseContext) SetAdminChecker(checker AdminChecker) {
+	c.admins = checker
+}
+
+// SetChatActionSender устанавливает функцию отправки действий транспорта
+func (c *BaseContext) SetChatActionSender(sender ChatActionSender) {
+	c.actions = sender
+}
+
 // Media представляет медиа файл
 type Media struct {
 	Type      MediaType `json:"type"`
@@ -49,6 +49,16 @@ type EventBus interface {
 	Stop(ctx context.Context) error
 }
 
+// OwnedSubscriber шина событий с подписками, привязанными к владельцу
+// Роутер использует ее, чтобы снять подписки модуля при выгрузке
+type OwnedSubscriber interface {
+	// SubscribeOwned подписывается на событие от имени владельца
+	SubscribeOwned(owner, eventType string, handler EventHandlerFunc) error
+	
+	// UnsubscribeOwner снимает все подписки владельца
+	UnsubscribeOwner(owner string) error
+}
+
 // Event базовый интерфейс события
 type Event interface {
 	// Type возвращает тип события
@@ -121,6 +131,23 @@ type Config interface {
 	IsSet(key string) bool
 }
 
+// FeatureFlags флаги функциональности
+type FeatureFlags interface {
+	// Enabled проверяет, включен ли флаг для пользователя запроса
+	Enabled(ctx UniversalContext, flag string) bool
+}
+
+// SectionedConfig конфигурация с секциями и привязкой к структурам
+type SectionedConfig interface {
+	Config
+	
+	// Section возвращает секцию: ключи задаются относительно key
+	Section(key string) Config
+	
+	// Bind заполняет структуру target значениями секции key и проверяет ее
+	Bind(key string, target interface{}) error
+}
+
 // Middleware промежуточный обработчик
 type Middleware interface {
 	// Name возвращает имя middleware
@@ -177,6 +204,15 @@ type Cache interface {
 	Clear(ctx context.Context) error
 }
 
+// CounterCache кеш с атомарными счетчиками (например, Redis INCRBY + EXPIRE)
+type CounterCache interface {
+	Cache
+	
+	// Increment атомарно увеличивает счетчик на delta и продлевает TTL (в секундах)
+	// Возвращает новое значение счетчика
+	Increment(ctx context.Context, key string, delta int64, ttl int) (int64, error)
+}
+
 // Storage интерфейс хранилища
 type Storage interface {
 	// Save сохраняет данные
@@ -214,6 +250,10 @@ type APIHandler struct {
 	Handler     APIHandlerFunc
 	Middlewares []Middleware
 	Description string
+
+	// Admin endpoint управления ботом: HTTP адаптер отклоняет запросы к нему,
+	// пока не задан Authorizer (SetAuthorizer)
+	Admin bool
 }
 
 // APIRequest запрос к API
@@ -0,0 +1,110 @@
+package core
+
+import "context"
+
+// Introspector источник полного описания бота (реализуется роутером)
+type Introspector interface {
+	// Introspect описывает модули, их маршруты, события, API, цепочку middleware и здоровье
+	Introspect(ctx context.Context) Introspection
+	
+	// DescribeModule описывает один модуль без проверки здоровья (false - модуль не найден)
+	DescribeModule(name string) (ModuleInfo, bool)
+	
+	// ModuleHealth проверяет здоровье одного модуля (false - модуль не найден)
+	ModuleHealth(ctx context.Context, name string) (HealthStatus, bool)
+}
+
+// Introspection описание работающего бота
+type Introspection struct {
+	Modules     []ModuleInfo     `json:"modules"`
+	Middlewares []MiddlewareInfo `json:"middlewares"`
+	Health      HealthReport     `json:"health"`
+	Time        int64            `json:"time"`
+}
+
+// ModuleInfo описание модуля
+type ModuleInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+
+	// Kinds возможности модуля: standard, wildcard, api, event_aware, lifecycle, dependent, configurable
+	Kinds []string `json:"kinds"`
+
+	// Feature флаг модуля (FeatureGatedModule)
+	Feature string `json:"feature,omitempty"`
+
+	// Metadata метаданные (если модуль реализует MetadataProvider)
+	Metadata *ModuleMetadata `json:"metadata,omitempty"`
+
+	// WildcardPriority приоритет wildcard обработчика
+	WildcardPriority *int `json:"wildcard_priority,omitempty"`
+
+	Dependencies []ModuleDependency `json:"dependencies,omitempty"`
+	Routes       []RouteDescription `json:"routes"`
+	Events       []EventInfo        `json:"events"`
+	API          []APIInfo          `json:"api"`
+	Health       HealthStatus       `json:"health"`
+}
+
+// RouteDescription описание маршрута модуля
+type RouteDescription struct {
+	Patterns []string `json:"patterns"`
+	Type     string   `json:"type"`
+	Priority int      `json:"priority"`
+
+	// Метаданные маршрута
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Hidden      bool     `json:"hidden,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+
+	// Security ограничения доступа
+	Security RouteSecurityInfo `json:"security"`
+
+	// CacheTTL время кеширования ответа в секундах (0 - не кешируется)
+	CacheTTL int `json:"cache_ttl,omitempty"`
+
+	// Feature флаг маршрута, FeatureFallback - есть запасной обработчик
+	Feature         string `json:"feature,omitempty"`
+	FeatureFallback bool   `json:"feature_fallback,omitempty"`
+}
+
+// RouteSecurityInfo ограничения доступа к маршруту
+type RouteSecurityInfo struct {
+	RequireAuth        bool       `json:"require_auth,omitempty"`
+	RequireRoles       []string   `json:"require_roles,omitempty"`
+	RequirePermissions []string   `json:"require_permissions,omitempty"`
+	AllowedSources     []string   `json:"allowed_sources,omitempty"`
+	AllowedChatTypes   []ChatType `json:"allowed_chat_types,omitempty"`
+	RequireChatAdmin   bool       `json:"require_chat_admin,omitempty"`
+	RequireAddressed   bool       `json:"require_addressed,omitempty"`
+
+	// RateLimit ограничение скорости: "5/60s (sliding_window)"
+	RateLimit string `json:"rate_limit,omitempty"`
+}
+
+// EventInfo подписка модуля на событие
+type EventInfo struct {
+	EventType string `json:"event_type"`
+	Priority  int    `json:"priority"`
+	Filtered  bool   `json:"filtered,omitempty"`
+}
+
+// APIInfo HTTP обработчик модуля
+type APIInfo struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Admin       bool   `json:"admin,omitempty"`
+}
+
+// MiddlewareInfo middleware в цепочке обработки (в порядке выполнения)
+type MiddlewareInfo struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+}
@@ -50,6 +50,28 @@ type WildcardModule interface {
 	HandleWildcard(ctx UniversalContext) Response
 }
 
+// DependentModule модуль, зависящий от других модулей
+// Роутер запускает зависимости раньше модуля, а останавливает позже
+type DependentModule interface {
+	Module
+	
+	// DependsOn возвращает зависимости модуля
+	DependsOn() []ModuleDependency
+}
+
+// ModuleDependency зависимость от другого модуля
+type ModuleDependency struct {
+	// Name имя модуля
+	Name string `json:"name"`
+	
+	// Version ограничение на Version() модуля: ">=1.2.0", "^1.2", "~1.2.3", "1.x",
+	// несколько условий через запятую, альтернативы через "||". Пусто - любая версия
+	Version string `json:"version,omitempty"`
+	
+	// Optional модуль может отсутствовать (но если есть - запускается раньше)
+	Optional bool `json:"optional,omitempty"`
+}
+
 // APIModule модуль с HTTP API endpoints
 type APIModule interface {
 	Module
@@ -79,6 +101,27 @@ type Dependencies interface {
 	Set(key string, value interface{})
 }
 
+// ModuleScopedDependencies зависимости, выдающие каждому модулю свой дочерний контейнер
+// Роутер передает в Init модуля результат ForModule и освобождает его при выгрузке модуля
+type ModuleScopedDependencies interface {
+	Dependencies
+	
+	// ForModule возвращает зависимости регистрации модуля (видят общие зависимости)
+	// owner уникален для каждой регистрации: "arena#3"
+	ForModule(owner string) Dependencies
+	
+	// ReleaseModule освобождает зависимости регистрации модуля
+	ReleaseModule(owner string)
+}
+
+// ValidatedDependencies зависимости, проверяемые при запуске роутера
+type ValidatedDependencies interface {
+	Dependencies
+	
+	// Validate проверяет, что все объявленные зависимости зарегистрированы
+	Validate() error
+}
+
 // HandlerFunc функция-обработчик для маршрута
 type HandlerFunc func(ctx UniversalContext) Response
 
@@ -116,6 +159,37 @@ type ModuleMetadata struct {
 	Config      map[string]string `json:"config"`
 }
 
+// MetadataProvider модуль, описывающий себя метаданными
+type MetadataProvider interface {
+	Module
+	
+	// Metadata возвращает метаданные модуля (автор, теги, конфигурация)
+	Metadata() ModuleMetadata
+}
+
+// FeatureGatedModule модуль, доступный только при включенном флаге
+// Пока флаг выключен для пользователя, роутер пропускает маршруты и wildcard модуля
+type FeatureGatedModule interface {
+	Module
+	
+	// FeatureFlag возвращает имя флага модуля
+	FeatureFlag() string
+}
+
+// ModulesConfigKey секция конфигурации модулей: настройки модуля arena лежат в "modules.arena"
+const ModulesConfigKey = "modules"
+
+// ConfigurableModule модуль, объявляющий схему своей конфигурации
+// Перед Init роутер заполняет схему секцией "modules.<name>" и проверяет ее
+// (теги config, default и validate); ошибка проверки отменяет регистрацию модуля.
+// Конфигурация роутера должна реализовать SectionedConfig (например, config.Config)
+type ConfigurableModule interface {
+	Module
+	
+	// ConfigSchema возвращает указатель на структуру конфигурации модуля
+	ConfigSchema() interface{}
+}
+
 // Lifecycle хуки жизненного цикла модуля
 type Lifecycle interface {
 	// OnInit вызывается при инициализации
@@ -139,4 +213,28 @@ type HealthStatus struct {
 	Healthy bool              `json:"healthy"`
 	Message string            `json:"message"`
 	Details map[string]string `json:"details"`
+}
+
+// HealthReport сводный статус здоровья модулей
+type HealthReport struct {
+	// Status healthy, starting или unhealthy
+	Status string `json:"status"`
+	
+	// Live процесс работает (все проверки здоровья ответили вовремя)
+	Live bool `json:"live"`
+	
+	// Ready роутер запущен и все модули здоровы - можно принимать запросы
+	Ready bool `json:"ready"`
+	
+	// Modules статус каждого модуля
+	Modules map[string]HealthStatus `json:"modules"`
+	
+	// Time время проверки (unix)
+	Time int64 `json:"time"`
+}
+
+// HealthReporter источник сводного статуса здоровья (реализуется роутером)
+type HealthReporter interface {
+	// Health проверяет здоровье модулей
+	Health(ctx context.Context) HealthReport
 }
\ No newline at end of file
@@ -48,6 +48,9 @@ const (
 	
 	// ResponseTypeStream потоковый ответ (для больших данных)
 	ResponseTypeStream ResponseType = "stream"
+	
+	// ResponseTypeUpsert отредактировать сообщение меню или отправить новое
+	ResponseTypeUpsert ResponseType = "upsert"
 )
 
 // MessageContent содержимое сообщения
@@ -243,12 +246,64 @@ type EmbedField struct {
 	Inline bool   `json:"inline,omitempty"`
 }
 
+// RedirectTarget цель внутреннего перенаправления
+type RedirectTarget struct {
+	// Target текст или callback data для повторной маршрутизации
+	Target string `json:"target"`
+	
+	// Module ограничивает поиск маршрута указанным модулем
+	Module string `json:"module,omitempty"`
+	
+	// Params параметры, добавляемые в контекст
+	Params map[string]interface{} `json:"params,omitempty"`
+	
+	// AsCallback маршрутизировать как callback
+	AsCallback bool `json:"as_callback,omitempty"`
+}
+
+// StreamChunk часть потокового ответа
+type StreamChunk struct {
+	// Text добавляемый к сообщению текст
+	Text string `json:"text"`
+	
+	// Err ошибка генерации (завершает поток)
+	Err error `json:"-"`
+}
+
+// RedirectResponse ответ с перенаправлением на другой маршрут
+type RedirectResponse interface {
+	Response
+	
+	// Redirect возвращает цель перенаправления
+	Redirect() *RedirectTarget
+}
+
+// StreamResponse потоковый ответ
+type StreamResponse interface {
+	Response
+	
+	// Stream возвращает канал частей ответа (закрывается производителем)
+	Stream() <-chan StreamChunk
+}
+
+// ErrorResponse ответ обработчика, завершившегося ошибкой
+// Пользователь получает текст ответа, а ошибка передается в Lifecycle.OnError модуля
+type ErrorResponse interface {
+	Response
+	
+	// Err возвращает ошибку обработчика (nil - ответ без ошибки)
+	Err() error
+}
+
 // BaseResponse базовая реализация Response
 type BaseResponse struct {
 	responseType ResponseType
 	content      MessageContent
 	options      ResponseOptions
 	actions      []Response
+	redirect     *RedirectTarget
+	stream       <-chan StreamChunk
+	err          error
 }
 
 // NewBaseResponse создает новый базовый ответ
@@ -268,6 +323,9 @@ func (r *BaseResponse) Options() ResponseOptions   { return r.options }
 func (r *BaseResponse) Actions() []Response        { return r.actions }
 func (r *BaseResponse) IsEmpty() bool              { return r.content.Text == "" && len(r.content.Media) == 0 }
 func (r *BaseResponse) IsSilent() bool             { return r.responseType == ResponseTypeSilent }
+func (r *BaseResponse) Redirect() *RedirectTarget  { return r.redirect }
+func (r *BaseResponse) Stream() <-chan StreamChunk { return r.stream }
+func (r *BaseResponse) Err() error                 { return r.err }
 
 // Builder methods
 func (r *BaseResponse) WithText(text string) *BaseResponse {
@@ -300,6 +358,24 @@ func (r *BaseResponse) WithDeleteUserMessage() *BaseResponse {
 	return r
 }
 
+// WithMessageToEdit задает ID сообщения для редактирования вместо отправки нового
+func (r *BaseResponse) WithMessageToEdit(messageID string) *BaseResponse {
+	r.options.MessageToEditID = messageID
+	return r
+}
+
+func (r *BaseResponse) WithError(err error) *BaseResponse {
+	r.err = err
+	return r
+}
+
+func (r *BaseResponse) WithRedirectModule(module string) *BaseResponse {
+	if r.redirect != nil {
+		r.redirect.Module = module
+	}
+	return r
+}
+
 // === Конструкторы для удобства ===
 
 // NewMessage создает новое сообщение
@@ -307,6 +383,12 @@ func NewMessage(text string) *BaseResponse {
 	return NewBaseResponse(ResponseTypeMessage).WithText(text)
 }
 
+// NewErrorResponse создает сообщение об ошибке обработчика.
+// Пользователь видит text, ошибка передается модулю через Lifecycle.OnError
+func NewErrorResponse(text string, err error) *BaseResponse {
+	return NewMessage(text).WithError(err)
+}
+
 // NewEditMessage создает ответ с редактированием
 func NewEditMessage(messageID, text string) *BaseResponse {
 	resp := NewBaseResponse(ResponseTypeEdit).WithText(text)
@@ -314,6 +396,13 @@ func NewEditMessage(messageID, text string) *BaseResponse {
 	return resp
 }
 
+// NewUpsertMessage создает ответ "редактировать или отправить".
+// Адаптер редактирует сообщение меню (явно указанное, из callback или
+// последнее отслеживаемое), а если это невозможно - отправляет новое
+func NewUpsertMessage(text string) *BaseResponse {
+	return NewBaseResponse(ResponseTypeUpsert).WithText(text)
+}
+
 // NewDeleteMessage создает ответ с удалением
 func NewDeleteMessage(messageID string) *BaseResponse {
 	resp := NewBaseResponse(ResponseTypeDelete)
@@ -321,6 +410,61 @@ func NewDeleteMessage(messageID string) *BaseResponse {
 	return resp
 }
 
+// NewRedirect создает ответ с внутренним перенаправлением на маршрут,
+// совпадающий с target (например "/start" или "помощь")
+func NewRedirect(target string, params map[string]interface{}) *BaseResponse {
+	resp := NewBaseResponse(ResponseTypeRedirect)
+	resp.redirect = &RedirectTarget{
+		Target: target,
+		Params: params,
+	}
+	return resp
+}
+
+// NewRedirectToRoute создает перенаправление на callback маршрут module:action
+func NewRedirectToRoute(route *Route) *BaseResponse {
+	resp := NewBaseResponse(ResponseTypeRedirect)
+	resp.redirect = &RedirectTarget{
+		Target:     route.Module + ":" + route.Action,
+		Params:     route.Params,
+		AsCallback: true,
+	}
+	return resp
+}
+
+// NewStreamResponse создает потоковый ответ.
+// Производитель пишет части в канал и закрывает его по завершении.
+// Если адаптер прерывает отправку (ошибка, отключение клиента), он дочитывает
+// канал через DrainStream - производитель не блокируется, но чтобы не делать
+// лишнюю работу, ему стоит проверять ctx.Context().Done()
+func NewStreamResponse(stream <-chan StreamChunk) *BaseResponse {
+	resp := NewBaseResponse(ResponseTypeStream)
+	resp.stream = stream
+	return resp
+}
+
+// DrainStream дочитывает канал потокового ответа в фоне, пока производитель
+// его не закроет. Адаптеры вызывают его через defer, чтобы ранний выход
+// из отправки не оставлял производителя заблокированным на записи
+func DrainStream(stream <-chan StreamChunk) {
+	if stream == nil {
+		return
+	}
+	go func() {
+		for range stream {
+		}
+	}()
+}
+
+// NewCallbackAnswer создает ответ на callback query (уведомление или alert)
+func NewCallbackAnswer(text string, showAlert bool) *BaseResponse {
+	resp := NewBaseResponse(ResponseTypeCallback)
+	resp.content.Text = text
+	resp.options.CallbackText = text
+	resp.options.ShowAlert = showAlert
+	return resp
+}
+
 // NewSilentResponse создает тихий ответ
 func NewSilentResponse() *BaseResponse {
 	return NewBaseResponse(ResponseTypeSilent)
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrResponseNotSerializable ответ нельзя сериализовать (потоковый ответ)
+var ErrResponseNotSerializable = errors.New("response is not serializable")
+
+// StaticKeyboard клавиатура с фиксированными кнопками
+// Используется при восстановлении сериализованного ответа
+type StaticKeyboard struct {
+	KeyboardType KeyboardType    `json:"type"`
+	Rows         [][]Button      `json:"buttons,omitempty"`
+	Opts         KeyboardOptions `json:"options"`
+}
+
+// NewStaticKeyboard создает копию клавиатуры
+func NewStaticKeyboard(keyboard Keyboard) *StaticKeyboard {
+	if keyboard == nil {
+		return nil
+	}
+	if static, ok := keyboard.(*StaticKeyboard); ok {
+		return static
+	}
+
+	return &StaticKeyboard{
+		KeyboardType: keyboard.Type(),
+		Rows:         keyboard.Buttons(),
+		Opts:         keyboard.Options(),
+	}
+}
+
+func (k *StaticKeyboard) Type() KeyboardType       { return k.KeyboardType }
+func (k *StaticKeyboard) Buttons() [][]Button      { return k.Rows }
+func (k *StaticKeyboard) Options() KeyboardOptions { return k.Opts }
+
+// ResponseSnapshot сериализуемое представление ответа
+type ResponseSnapshot struct {
+	Type      ResponseType           `json:"type"`
+	Text      string                 `json:"text,omitempty"`
+	ParseMode ParseMode              `json:"parse_mode,omitempty"`
+	Media     []Media                `json:"media,omitempty"`
+	Keyboard  *StaticKeyboard        `json:"keyboard,omitempty"`
+	Embeds    []Embed                `json:"embeds,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Options   ResponseOptions        `json:"options"`
+	Actions   []*ResponseSnapshot    `json:"actions,omitempty"`
+	Redirect  *RedirectTarget        `json:"redirect,omitempty"`
+}
+
+// SnapshotResponse снимает сериализуемую копию ответа
+// Клавиатура любого типа сохраняется как StaticKeyboard
+func SnapshotResponse(response Response) (*ResponseSnapshot, error) {
+	if response == nil {
+		return nil, fmt.Errorf("nil response: %w", ErrResponseNotSerializable)
+	}
+	if response.Type() == ResponseTypeStream {
+		return nil, fmt.Errorf("stream response: %w", ErrResponseNotSerializable)
+	}
+
+	content := response.Content()
+	snapshot := &ResponseSnapshot{
+		Type:      response.Type(),
+		Text:      content.Text,
+		ParseMode: content.ParseMode,
+		Media:     content.Media,
+		Keyboard:  NewStaticKeyboard(content.Keyboard),
+		Embeds:    content.Embeds,
+		Metadata:  content.Metadata,
+		Options:   response.Options(),
+	}
+
+	if redirect, ok := response.(RedirectResponse); ok {
+		snapshot.Redirect = redirect.Redirect()
+	}
+
+	for _, action := range response.Actions() {
+		actionSnapshot, err := SnapshotResponse(action)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Actions = append(snapshot.Actions, actionSnapshot)
+	}
+
+	return snapshot, nil
+}
+
+// Response восстанавливает ответ из снимка
+func (s *ResponseSnapshot) Response() *BaseResponse {
+	resp := NewBaseResponse(s.Type)
+	resp.content = MessageContent{
+		Text:      s.Text,
+		ParseMode: s.ParseMode,
+		Media:     s.Media,
+		Embeds:    s.Embeds,
+		Metadata:  s.Metadata,
+	}
+	if s.Keyboard != nil {
+		resp.content.Keyboard = s.Keyboard
+	}
+	resp.options = s.Options
+	resp.redirect = s.Redirect
+
+	for _, action := range s.Actions {
+		resp.actions = append(resp.actions, action.Response())
+	}
+
+	return resp
+}
+
+// MarshalResponse сериализует ответ в JSON (для хранения в core.Cache)
+func MarshalResponse(response Response) ([]byte, error) {
+	snapshot, err := SnapshotResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(snapshot)
+}
+
+// UnmarshalResponse восстанавливает ответ из JSON
+func UnmarshalResponse(data []byte) (*BaseResponse, error) {
+	var snapshot ResponseSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return snapshot.Response(), nil
+}
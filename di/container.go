@@ -0,0 +1,635 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+var (
+	_ core.Dependencies             = (*Container)(nil)
+	_ core.ModuleScopedDependencies = (*Container)(nil)
+	_ core.ValidatedDependencies    = (*Container)(nil)
+)
+
+var (
+	// ErrNotFound зависимость не зарегистрирована
+	ErrNotFound = errors.New("dependency not found")
+
+	// ErrAmbiguous интерфейс реализуют несколько зависимостей
+	ErrAmbiguous = errors.New("ambiguous dependency")
+
+	// ErrCycle циклическая зависимость фабрик
+	ErrCycle = errors.New("dependency cycle")
+
+	// ErrScopeRequired зависимость уровня запроса запрошена вне запроса
+	ErrScopeRequired = errors.New("scoped dependency requires a request scope")
+
+	// ErrScopeClosed запрос уже завершен
+	ErrScopeClosed = errors.New("dependency scope is closed")
+
+	// ErrAlreadyRegistered зависимость с таким ключом уже зарегистрирована
+	ErrAlreadyRegistered = errors.New("dependency already registered")
+)
+
+// Lifetime время жизни зависимости
+type Lifetime int
+
+const (
+	// LifetimeSingleton один экземпляр на контейнер (создается при первом запросе)
+	LifetimeSingleton Lifetime = iota
+
+	// LifetimeScoped один экземпляр на запрос (например, транзакция БД на один update)
+	LifetimeScoped
+
+	// LifetimeTransient новый экземпляр на каждый запрос зависимости
+	LifetimeTransient
+)
+
+// String возвращает название времени жизни
+func (l Lifetime) String() string {
+	switch l {
+	case LifetimeSingleton:
+		return "singleton"
+	case LifetimeScoped:
+		return "scoped"
+	case LifetimeTransient:
+		return "transient"
+	default:
+		return fmt.Sprintf("lifetime(%d)", int(l))
+	}
+}
+
+// Key ключ зависимости: тип и необязательное имя
+type Key struct {
+	Type reflect.Type
+	Name string
+}
+
+// KeyOf ключ зависимости типа T
+func KeyOf[T any]() Key {
+	return Key{Type: typeOf[T]()}
+}
+
+// NamedKey ключ именованной зависимости типа T
+func NamedKey[T any](name string) Key {
+	return Key{Type: typeOf[T](), Name: name}
+}
+
+// String возвращает описание ключа
+func (k Key) String() string {
+	if k.Name != "" {
+		return fmt.Sprintf("%v(%q)", k.Type, k.Name)
+	}
+	return fmt.Sprint(k.Type)
+}
+
+// typeOf возвращает тип T (в том числе интерфейсный)
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Resolver источник зависимостей: контейнер или запрос (Scope)
+type Resolver interface {
+	// Context контекст, в котором создаются зависимости
+	Context() context.Context
+
+	resolve(key Key) (interface{}, error)
+}
+
+// Disposable зависимость уровня запроса, которой нужен результат запроса
+// (например, транзакция: commit при err == nil, иначе rollback)
+type Disposable interface {
+	Dispose(err error) error
+}
+
+// provider способ получения зависимости
+type provider struct {
+	key      Key
+	lifetime Lifetime
+	factory  func(r Resolver) (interface{}, error)
+	requires []Key
+	owner    *Container
+
+	// экземпляр singleton
+	mu       sync.Mutex
+	created  bool
+	instance interface{}
+}
+
+// Option настройка регистрации зависимости
+type Option func(p *provider)
+
+// AsSingleton один экземпляр на контейнер (по умолчанию)
+func AsSingleton() Option {
+	return func(p *provider) { p.lifetime = LifetimeSingleton }
+}
+
+// AsScoped один экземпляр на запрос
+func AsScoped() Option {
+	return func(p *provider) { p.lifetime = LifetimeScoped }
+}
+
+// AsTransient новый экземпляр при каждом запросе
+func AsTransient() Option {
+	return func(p *provider) { p.lifetime = LifetimeTransient }
+}
+
+// Named регистрирует зависимость под именем (несколько зависимостей одного типа)
+func Named(name string) Option {
+	return func(p *provider) { p.key.Name = name }
+}
+
+// Requires объявляет зависимости фабрики для проверки при запуске (Validate)
+func Requires(keys ...Key) Option {
+	return func(p *provider) { p.requires = append(p.requires, keys...) }
+}
+
+// Container контейнер зависимостей
+//
+// Зависимости регистрируются типизированно (Provide, ProvideValue, ProvideConstructor)
+// и создаются лениво при первом запросе. Дочерние контейнеры (Child, ForModule)
+// видят зависимости родителя, родитель не видит зависимости дочерних.
+// Реализует core.Dependencies, поэтому передается роутеру через SetDependencies
+type Container struct {
+	name   string
+	parent *Container
+
+	mu        sync.RWMutex
+	providers map[Key]*provider
+	order     []*provider
+	required  []Key
+	children  map[string]*Container
+
+	// созданные singleton, закрываются в обратном порядке (Close)
+	closeMu sync.Mutex
+	created []interface{}
+}
+
+// New создает корневой контейнер
+func New() *Container {
+	return newContainer("root", nil)
+}
+
+func newContainer(name string, parent *Container) *Container {
+	return &Container{
+		name:      name,
+		parent:    parent,
+		providers: make(map[Key]*provider),
+		children:  make(map[string]*Container),
+	}
+}
+
+// Name возвращает имя контейнера
+func (c *Container) Name() string {
+	return c.name
+}
+
+// Child создает дочерний контейнер (заменяет дочерний контейнер с тем же именем)
+func (c *Container) Child(name string) *Container {
+	child := newContainer(name, c)
+
+	c.mu.Lock()
+	c.children[name] = child
+	c.mu.Unlock()
+
+	return child
+}
+
+// ForModule возвращает модулю его дочерний контейнер (реализует core.ModuleScopedDependencies)
+// Роутер вызывает его при каждой регистрации модуля, новая версия модуля получает новый контейнер
+func (c *Container) ForModule(owner string) core.Dependencies {
+	return c.Child(owner)
+}
+
+// ReleaseModule закрывает и удаляет дочерний контейнер выгруженного модуля
+func (c *Container) ReleaseModule(owner string) {
+	c.mu.Lock()
+	child, ok := c.children[owner]
+	delete(c.children, owner)
+	c.mu.Unlock()
+
+	if ok {
+		_ = child.Close()
+	}
+}
+
+// Context контекст создания singleton зависимостей
+func (c *Container) Context() context.Context {
+	return context.Background()
+}
+
+func (c *Container) resolve(key Key) (interface{}, error) {
+	return (&resolution{container: c}).resolve(key)
+}
+
+// register добавляет способ получения зависимости
+func (c *Container) register(p *provider) error {
+	p.owner = c
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.providers[p.key]; exists {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, p.key)
+	}
+	c.providers[p.key] = p
+	c.order = append(c.order, p)
+
+	return nil
+}
+
+// lookup находит способ получения зависимости в контейнере и его родителях
+//
+// Сначала ищется точное совпадение ключа. Для интерфейса ищется единственная
+// зависимость, реализующая его (ближайший контейнер имеет приоритет)
+func (c *Container) lookup(key Key) (*provider, error) {
+	for current := c; current != nil; current = current.parent {
+		current.mu.RLock()
+		p, ok := current.providers[key]
+		current.mu.RUnlock()
+		if ok {
+			return p, nil
+		}
+	}
+
+	if key.Type == nil || key.Type.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	for current := c; current != nil; current = current.parent {
+		var candidates []*provider
+
+		current.mu.RLock()
+		for _, p := range current.order {
+			if key.Name != "" && p.key.Name != key.Name {
+				continue
+			}
+			if p.key.Type != key.Type && p.key.Type.Implements(key.Type) {
+				candidates = append(candidates, p)
+			}
+		}
+		current.mu.RUnlock()
+
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			return nil, fmt.Errorf("%w: %s is implemented by %s and %s",
+				ErrAmbiguous, key, candidates[0].key, candidates[1].key)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+}
+
+// lookupName находит зависимость по имени (для core.Dependencies.Get)
+func (c *Container) lookupName(name string) (*provider, bool) {
+	for current := c; current != nil; current = current.parent {
+		current.mu.RLock()
+		for _, p := range current.order {
+			if p.key.Name == name {
+				current.mu.RUnlock()
+				return p, true
+			}
+		}
+		current.mu.RUnlock()
+	}
+	return nil, false
+}
+
+// singleton возвращает экземпляр singleton, создавая его при первом запросе
+func (p *provider) singleton(res *resolution) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.created {
+		return p.instance, nil
+	}
+
+	instance, err := p.factory(res)
+	if err != nil {
+		return nil, err
+	}
+
+	p.instance = instance
+	p.created = true
+	p.owner.track(instance)
+
+	return instance, nil
+}
+
+// track запоминает созданный singleton для Close
+func (c *Container) track(instance interface{}) {
+	c.closeMu.Lock()
+	c.created = append(c.created, instance)
+	c.closeMu.Unlock()
+}
+
+// Close закрывает созданные singleton (io.Closer) в обратном порядке,
+// сначала в дочерних контейнерах
+func (c *Container) Close() error {
+	c.mu.RLock()
+	children := make([]*Container, 0, len(c.children))
+	for _, child := range c.children {
+		children = append(children, child)
+	}
+	c.mu.RUnlock()
+
+	var errs []error
+	for _, child := range children {
+		errs = append(errs, child.Close())
+	}
+
+	c.closeMu.Lock()
+	created := c.created
+	c.created = nil
+	c.closeMu.Unlock()
+
+	for i := len(created) - 1; i >= 0; i-- {
+		if closer, ok := created[i].(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close %T: %w", created[i], err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// resolution разрешение зависимостей с учетом цепочки (для обнаружения циклов)
+type resolution struct {
+	container *Container
+	scope     *Scope
+	chain     []Key
+}
+
+// Context контекст запроса или контейнера
+func (r *resolution) Context() context.Context {
+	if r.scope != nil {
+		return r.scope.ctx
+	}
+	return r.container.Context()
+}
+
+func (r *resolution) resolve(key Key) (interface{}, error) {
+	for i, k := range r.chain {
+		if k == key {
+			return nil, fmt.Errorf("%w: %s", ErrCycle, formatChain(append(r.chain[i:], key)))
+		}
+	}
+
+	p, err := r.container.lookup(key)
+	if err != nil {
+		if len(r.chain) > 0 {
+			return nil, fmt.Errorf("%s: %w", formatChain(r.chain), err)
+		}
+		return nil, err
+	}
+
+	chain := make([]Key, len(r.chain), len(r.chain)+1)
+	copy(chain, r.chain)
+	chain = append(chain, p.key)
+
+	switch p.lifetime {
+	case LifetimeSingleton:
+		// Singleton не видит зависимости запроса: они живут меньше него
+		return p.singleton(&resolution{container: p.owner, chain: chain})
+	case LifetimeScoped:
+		if r.scope == nil {
+			return nil, fmt.Errorf("%w: %s", ErrScopeRequired, p.key)
+		}
+		return r.scope.instance(p, &resolution{container: p.owner, scope: r.scope, chain: chain})
+	default:
+		return p.factory(&resolution{container: p.owner, scope: r.scope, chain: chain})
+	}
+}
+
+// formatChain цепочка зависимостей: a -> b -> c
+func formatChain(chain []Key) string {
+	s := ""
+	for i, key := range chain {
+		if i > 0 {
+			s += " -> "
+		}
+		s += key.String()
+	}
+	return s
+}
+
+// Provide регистрирует ленивую фабрику зависимости типа T
+// По умолчанию зависимость - singleton (AsScoped, AsTransient меняют время жизни)
+func Provide[T any](c *Container, factory func(r Resolver) (T, error), opts ...Option) error {
+	p := &provider{
+		key: KeyOf[T](),
+		factory: func(r Resolver) (interface{}, error) {
+			return factory(r)
+		},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return c.register(p)
+}
+
+// ProvideValue регистрирует готовый экземпляр типа T
+func ProvideValue[T any](c *Container, value T, opts ...Option) error {
+	p := &provider{
+		key:      KeyOf[T](),
+		created:  true,
+		instance: value,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.lifetime = LifetimeSingleton
+
+	return c.register(p)
+}
+
+// ProvideConstructor регистрирует конструктор func(A, B, ...) T или func(...) (T, error)
+// Параметры конструктора разрешаются из контейнера и проверяются в Validate
+func ProvideConstructor(c *Container, constructor interface{}, opts ...Option) error {
+	fn := reflect.ValueOf(constructor)
+	fnType := fn.Type()
+
+	if fnType.Kind() != reflect.Func || fnType.NumOut() < 1 || fnType.NumOut() > 2 || fnType.IsVariadic() {
+		return fmt.Errorf("di: constructor must be func(...) T or func(...) (T, error), got %v", fnType)
+	}
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	if fnType.NumOut() == 2 && fnType.Out(1) != errType {
+		return fmt.Errorf("di: second result of constructor %v must be error", fnType)
+	}
+
+	params := make([]Key, fnType.NumIn())
+	for i := range params {
+		params[i] = Key{Type: fnType.In(i)}
+	}
+
+	p := &provider{
+		key:      Key{Type: fnType.Out(0)},
+		requires: params,
+		factory: func(r Resolver) (interface{}, error) {
+			args := make([]reflect.Value, len(params))
+			for i, key := range params {
+				value, err := r.resolve(key)
+				if err != nil {
+					return nil, err
+				}
+				args[i] = reflect.New(key.Type).Elem()
+				if value != nil {
+					args[i].Set(reflect.ValueOf(value))
+				}
+			}
+
+			results := fn.Call(args)
+			if len(results) == 2 && !results[1].IsNil() {
+				return nil, results[1].Interface().(error)
+			}
+			return results[0].Interface(), nil
+		},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return c.register(p)
+}
+
+// Resolve возвращает зависимость типа T
+// Для интерфейса подходит единственная зарегистрированная реализация
+func Resolve[T any](r Resolver) (T, error) {
+	return resolveKey[T](r, KeyOf[T]())
+}
+
+// ResolveNamed возвращает именованную зависимость типа T
+func ResolveNamed[T any](r Resolver, name string) (T, error) {
+	return resolveKey[T](r, NamedKey[T](name))
+}
+
+// MustResolve возвращает зависимость типа T или паникует
+func MustResolve[T any](r Resolver) T {
+	value, err := Resolve[T](r)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+func resolveKey[T any](r Resolver, key Key) (T, error) {
+	var zero T
+
+	value, err := r.resolve(key)
+	if err != nil {
+		return zero, err
+	}
+	if value == nil {
+		return zero, nil
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("di: %s resolved to %T", key, value)
+	}
+	return typed, nil
+}
+
+// Require объявляет, что модулю нужна зависимость типа T (проверяется при запуске роутера)
+// Вызывается из Init модуля: di.Require[*sql.DB](deps)
+func Require[T any](deps core.Dependencies, names ...string) {
+	c, ok := deps.(*Container)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(names) == 0 {
+		c.required = append(c.required, KeyOf[T]())
+	}
+	for _, name := range names {
+		c.required = append(c.required, NamedKey[T](name))
+	}
+}
+
+// === core.Dependencies ===
+
+// Database возвращает зависимость "database"
+func (c *Container) Database() interface{} {
+	value, _ := c.Get("database")
+	return value
+}
+
+// EventBus возвращает шину событий
+func (c *Container) EventBus() core.EventBus {
+	bus, _ := Resolve[core.EventBus](c)
+	return bus
+}
+
+// Logger возвращает логгер
+func (c *Container) Logger() core.Logger {
+	logger, _ := Resolve[core.Logger](c)
+	return logger
+}
+
+// Config возвращает конфигурацию
+func (c *Container) Config() core.Config {
+	config, _ := Resolve[core.Config](c)
+	return config
+}
+
+// Get возвращает зависимость по имени (зарегистрированную через Set или Named)
+func (c *Container) Get(key string) (interface{}, bool) {
+	p, ok := c.lookupName(key)
+	if !ok {
+		return nil, false
+	}
+
+	value, err := c.resolve(p.key)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set регистрирует готовую зависимость под именем (заменяет предыдущую с тем же именем)
+// Зависимость также находится по интерфейсу: di.Resolve[ArenaService](c)
+func (c *Container) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, p := range c.providers {
+		if k.Name == key {
+			delete(c.providers, k)
+			for i, ordered := range c.order {
+				if ordered == p {
+					c.order = append(c.order[:i:i], c.order[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+
+	if value == nil {
+		return
+	}
+
+	p := &provider{
+		key:      Key{Type: reflect.TypeOf(value), Name: key},
+		lifetime: LifetimeSingleton,
+		created:  true,
+		instance: value,
+		owner:    c,
+	}
+	c.providers[p.key] = p
+	c.order = append(c.order, p)
+}
@@ -0,0 +1,406 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// greeter интерфейс для поиска зависимости по интерфейсу
+type greeter interface {
+	Greet() string
+}
+
+type english struct{ n int }
+
+func (e *english) Greet() string { return "hello" }
+
+type russian struct{}
+
+func (r *russian) Greet() string { return "привет" }
+
+// service зависимость, созданная конструктором
+type service struct {
+	greeter greeter
+}
+
+// tx зависимость запроса, запоминающая результат запроса
+type tx struct {
+	name   string
+	log    *[]string
+	result error
+}
+
+func (t *tx) Dispose(err error) error {
+	t.result = err
+	*t.log = append(*t.log, t.name)
+	return nil
+}
+
+// counter фабрика, считающая созданные экземпляры
+func counter(created *int) func(r Resolver) (*english, error) {
+	return func(r Resolver) (*english, error) {
+		*created++
+		return &english{n: *created}, nil
+	}
+}
+
+func TestLifetimes(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   Option
+		sameCall bool
+		sameReq  bool
+		created  int
+	}{
+		{name: "singleton", option: AsSingleton(), sameCall: true, sameReq: true, created: 1},
+		{name: "scoped", option: AsScoped(), sameCall: true, sameReq: false, created: 2},
+		{name: "transient", option: AsTransient(), sameCall: false, sameReq: false, created: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			created := 0
+			if err := Provide(c, counter(&created), tt.option); err != nil {
+				t.Fatal(err)
+			}
+
+			first := c.NewScope(context.Background())
+			second := c.NewScope(context.Background())
+
+			a := MustResolve[*english](first)
+			b := MustResolve[*english](first)
+			d := MustResolve[*english](second)
+
+			if (a == b) != tt.sameCall {
+				t.Errorf("same instance within a scope = %v, want %v", a == b, tt.sameCall)
+			}
+			if (a == d) != tt.sameReq {
+				t.Errorf("same instance across scopes = %v, want %v", a == d, tt.sameReq)
+			}
+
+			// Вне запроса зависимость запроса недоступна
+			_, err := Resolve[*english](c)
+			if tt.name == "scoped" {
+				if !errors.Is(err, ErrScopeRequired) {
+					t.Fatalf("resolve outside scope: err = %v, want ErrScopeRequired", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if created != tt.created {
+				t.Errorf("created %d instances, want %d", created, tt.created)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(c *Container) *Container
+		resolve func(c *Container) (string, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "by concrete type",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue(c, &english{})
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				g, err := Resolve[*english](c)
+				if err != nil {
+					return "", err
+				}
+				return g.Greet(), nil
+			},
+			want: "hello",
+		},
+		{
+			name: "by interface",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue(c, &russian{})
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				g, err := Resolve[greeter](c)
+				if err != nil {
+					return "", err
+				}
+				return g.Greet(), nil
+			},
+			want: "привет",
+		},
+		{
+			name: "ambiguous interface",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue(c, &english{})
+				_ = ProvideValue(c, &russian{})
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				_, err := Resolve[greeter](c)
+				return "", err
+			},
+			wantErr: ErrAmbiguous,
+		},
+		{
+			name: "named",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue[greeter](c, &english{}, Named("en"))
+				_ = ProvideValue[greeter](c, &russian{}, Named("ru"))
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				g, err := ResolveNamed[greeter](c, "ru")
+				if err != nil {
+					return "", err
+				}
+				return g.Greet(), nil
+			},
+			want: "привет",
+		},
+		{
+			name: "child sees parent",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue(c, &english{})
+				return c.Child("arena")
+			},
+			resolve: func(c *Container) (string, error) {
+				g, err := Resolve[greeter](c)
+				if err != nil {
+					return "", err
+				}
+				return g.Greet(), nil
+			},
+			want: "hello",
+		},
+		{
+			name: "closest container wins",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue(c, &english{})
+				child := c.Child("arena")
+				_ = ProvideValue(child, &russian{})
+				return child
+			},
+			resolve: func(c *Container) (string, error) {
+				g, err := Resolve[greeter](c)
+				if err != nil {
+					return "", err
+				}
+				return g.Greet(), nil
+			},
+			want: "привет",
+		},
+		{
+			name: "parent does not see child",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue(c.Child("arena"), &english{})
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				_, err := Resolve[greeter](c)
+				return "", err
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "constructor",
+			setup: func(c *Container) *Container {
+				_ = ProvideValue(c, &russian{})
+				_ = ProvideConstructor(c, func(g greeter) *service { return &service{greeter: g} })
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				s, err := Resolve[*service](c)
+				if err != nil {
+					return "", err
+				}
+				return s.greeter.Greet(), nil
+			},
+			want: "привет",
+		},
+		{
+			name: "constructor error",
+			setup: func(c *Container) *Container {
+				_ = ProvideConstructor(c, func() (*service, error) { return nil, ErrNotFound })
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				_, err := Resolve[*service](c)
+				return "", err
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "cycle",
+			setup: func(c *Container) *Container {
+				_ = ProvideConstructor(c, func(e *english) *service { return &service{} })
+				_ = ProvideConstructor(c, func(s *service) *english { return &english{} })
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				_, err := Resolve[*service](c)
+				return "", err
+			},
+			wantErr: ErrCycle,
+		},
+		{
+			name: "set by name",
+			setup: func(c *Container) *Container {
+				c.Set("greeter", &english{})
+				c.Set("greeter", &russian{})
+				return c
+			},
+			resolve: func(c *Container) (string, error) {
+				value, ok := c.Get("greeter")
+				if !ok {
+					return "", ErrNotFound
+				}
+				return value.(greeter).Greet(), nil
+			},
+			want: "привет",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resolve(tt.setup(New()))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvideTwice(t *testing.T) {
+	c := New()
+	if err := ProvideValue(c, &english{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ProvideValue(c, &english{}); !errors.Is(err, ErrAlreadyRegistered) {
+		t.Fatalf("err = %v, want ErrAlreadyRegistered", err)
+	}
+}
+
+func TestScopeClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		result error
+	}{
+		{name: "success"},
+		{name: "failure", result: errors.New("handler failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			c := New()
+			_ = Provide(c, func(r Resolver) (*tx, error) {
+				return &tx{name: "tx", log: &log}, nil
+			}, AsScoped())
+			_ = ProvideConstructor(c, func(t *tx) *service {
+				log = append(log, "service created")
+				return &service{}
+			}, AsScoped())
+
+			scope := c.NewScope(context.Background())
+			if _, err := Resolve[*service](scope); err != nil {
+				t.Fatal(err)
+			}
+			transaction := MustResolve[*tx](scope)
+
+			if err := scope.Close(tt.result); err != nil {
+				t.Fatal(err)
+			}
+			if transaction.result != tt.result {
+				t.Errorf("Dispose got %v, want %v", transaction.result, tt.result)
+			}
+			if strings.Join(log, ",") != "service created,tx" {
+				t.Errorf("log = %v", log)
+			}
+
+			if _, err := Resolve[*tx](scope); !errors.Is(err, ErrScopeClosed) {
+				t.Fatalf("resolve after close: err = %v, want ErrScopeClosed", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(c *Container)
+		wantErr string
+	}{
+		{
+			name: "valid",
+			setup: func(c *Container) {
+				_ = ProvideValue(c, &english{})
+				_ = ProvideConstructor(c, func(g greeter) *service { return &service{greeter: g} })
+			},
+		},
+		{
+			name: "missing constructor parameter",
+			setup: func(c *Container) {
+				_ = ProvideConstructor(c, func(g greeter) *service { return &service{greeter: g} })
+			},
+			wantErr: "requires dependency not found",
+		},
+		{
+			name: "missing module requirement",
+			setup: func(c *Container) {
+				Require[greeter](c.ForModule("arena"))
+			},
+			wantErr: "arena requires dependency not found",
+		},
+		{
+			name: "singleton depends on scoped",
+			setup: func(c *Container) {
+				_ = Provide(c, func(r Resolver) (*english, error) { return &english{}, nil }, AsScoped())
+				_ = ProvideConstructor(c, func(e *english) *service { return &service{} })
+			},
+			wantErr: "depends on scoped",
+		},
+		{
+			name: "cycle",
+			setup: func(c *Container) {
+				_ = ProvideConstructor(c, func(e *english) *service { return &service{} })
+				_ = ProvideConstructor(c, func(s *service) *english { return &english{} })
+			},
+			wantErr: "dependency cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			tt.setup(c)
+
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
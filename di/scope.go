@@ -0,0 +1,158 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// ScopeKey ключ значения (ctx.Get), под которым middleware хранит Scope запроса
+const ScopeKey = "di_scope"
+
+// Scope зависимости одного запроса (update, HTTP запроса, сообщения WebSocket)
+//
+// Зависимости AsScoped создаются один раз на Scope и освобождаются в Close
+// в обратном порядке: Disposable получает ошибку запроса, io.Closer закрывается
+type Scope struct {
+	ctx  context.Context
+	root *Container
+
+	mu      sync.Mutex
+	entries map[*provider]*scopedEntry
+	created []interface{}
+	closed  bool
+}
+
+// scopedEntry экземпляр зависимости в рамках запроса
+type scopedEntry struct {
+	mu       sync.Mutex
+	created  bool
+	instance interface{}
+}
+
+// NewScope создает область зависимостей запроса
+func (c *Container) NewScope(ctx context.Context) *Scope {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &Scope{
+		ctx:     ctx,
+		root:    c,
+		entries: make(map[*provider]*scopedEntry),
+	}
+}
+
+// Context контекст запроса
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Scope) resolve(key Key) (interface{}, error) {
+	return (&resolution{container: s.root, scope: s}).resolve(key)
+}
+
+// In возвращает Resolver запроса, который ищет зависимости в контейнере c
+// (например, в дочернем контейнере модуля). Экземпляры запроса общие
+func (s *Scope) In(c *Container) Resolver {
+	return &resolution{container: c, scope: s}
+}
+
+// instance возвращает экземпляр зависимости запроса, создавая его при первом запросе
+func (s *Scope) instance(p *provider, res *resolution) (interface{}, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrScopeClosed, p.key)
+	}
+	entry, ok := s.entries[p]
+	if !ok {
+		entry = &scopedEntry{}
+		s.entries[p] = entry
+	}
+	s.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.created {
+		return entry.instance, nil
+	}
+
+	instance, err := p.factory(res)
+	if err != nil {
+		return nil, err
+	}
+	entry.instance = instance
+	entry.created = true
+
+	s.mu.Lock()
+	s.created = append(s.created, instance)
+	s.mu.Unlock()
+
+	return instance, nil
+}
+
+// Close освобождает зависимости запроса в обратном порядке создания
+// err - результат запроса (nil - успех), передается в Disposable
+func (s *Scope) Close(err error) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	created := s.created
+	s.created = nil
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(created) - 1; i >= 0; i-- {
+		switch instance := created[i].(type) {
+		case Disposable:
+			if disposeErr := instance.Dispose(err); disposeErr != nil {
+				errs = append(errs, fmt.Errorf("dispose %T: %w", instance, disposeErr))
+			}
+		case io.Closer:
+			if closeErr := instance.Close(); closeErr != nil {
+				errs = append(errs, fmt.Errorf("close %T: %w", instance, closeErr))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ScopeFromContext возвращает Scope запроса (устанавливается DependencyScopeMiddleware)
+func ScopeFromContext(ctx core.UniversalContext) (*Scope, bool) {
+	value, ok := ctx.Get(ScopeKey)
+	if !ok {
+		return nil, false
+	}
+	scope, ok := value.(*Scope)
+	return scope, ok
+}
+
+// FromContext возвращает зависимость типа T в рамках запроса
+func FromContext[T any](ctx core.UniversalContext) (T, error) {
+	scope, ok := ScopeFromContext(ctx)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %s", ErrScopeRequired, KeyOf[T]())
+	}
+	return Resolve[T](scope)
+}
+
+// FromContextIn возвращает зависимость типа T в рамках запроса,
+// начиная поиск с контейнера c (дочернего контейнера модуля).
+// Вне запроса доступны только singleton и transient зависимости
+func FromContextIn[T any](ctx core.UniversalContext, c *Container) (T, error) {
+	if scope, ok := ScopeFromContext(ctx); ok {
+		return Resolve[T](scope.In(c))
+	}
+	return Resolve[T](c)
+}
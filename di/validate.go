@@ -0,0 +1,102 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Validate проверяет граф зависимостей без создания экземпляров (реализует core.ValidatedDependencies)
+//
+// Проверяется, что объявленные зависимости фабрик (Requires, параметры ProvideConstructor)
+// и модулей (Require) зарегистрированы, что singleton не зависит от зависимостей запроса
+// и что в графе нет циклов. Роутер вызывает Validate при запуске
+func (c *Container) Validate() error {
+	var errs []error
+	c.validate(&errs)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// validate проверяет контейнер и его дочерние контейнеры
+func (c *Container) validate(errs *[]error) {
+	c.mu.RLock()
+	providers := append([]*provider(nil), c.order...)
+	required := append([]Key(nil), c.required...)
+	children := make([]*Container, 0, len(c.children))
+	for _, child := range c.children {
+		children = append(children, child)
+	}
+	c.mu.RUnlock()
+
+	for _, key := range required {
+		if _, err := c.lookup(key); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s requires %w", c.name, err))
+		}
+	}
+
+	for _, p := range providers {
+		for _, key := range p.requires {
+			dep, err := p.owner.lookup(key)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %s requires %w", c.name, p.key, err))
+				continue
+			}
+			if p.lifetime == LifetimeSingleton && dep.lifetime == LifetimeScoped {
+				*errs = append(*errs, fmt.Errorf("%s: singleton %s depends on scoped %s", c.name, p.key, dep.key))
+			}
+		}
+
+		// Цикл сообщается один раз - от зависимости с наименьшим ключом
+		if cycle := findCycle(p); cycle != nil && isCycleStart(cycle) {
+			*errs = append(*errs, fmt.Errorf("%s: %w: %s", c.name, ErrCycle, formatChain(cycle)))
+		}
+	}
+
+	for _, child := range children {
+		child.validate(errs)
+	}
+}
+
+// findCycle ищет цикл в объявленных зависимостях, начинающийся с p
+func findCycle(start *provider) []Key {
+	var visit func(p *provider, chain []Key, seen map[*provider]bool) []Key
+	visit = func(p *provider, chain []Key, seen map[*provider]bool) []Key {
+		chain = append(chain, p.key)
+		for _, key := range p.requires {
+			dep, err := p.owner.lookup(key)
+			if err != nil {
+				continue
+			}
+			if dep == start {
+				return append(chain, dep.key)
+			}
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			if cycle := visit(dep, chain, seen); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+
+	return visit(start, nil, map[*provider]bool{start: true})
+}
+
+// isCycleStart проверяет, что цикл начинается с наименьшего ключа
+func isCycleStart(cycle []Key) bool {
+	first := cycle[0].String()
+	for _, key := range cycle[1:] {
+		if key.String() < first {
+			return false
+		}
+	}
+	return true
+}
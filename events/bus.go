@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/tracing"
 	"sync"
 	"time"
 )
 
+var _ core.OwnedSubscriber = (*EventBus)(nil)
+
 // EventBus реализация шины событий
 type EventBus struct {
 	// subscribers подписчики на события
@@ -25,6 +28,9 @@ type EventBus struct {
 	// metrics метрики
 	metrics core.Metrics
 
+	// tracer трассировка обработки событий
+	tracer *tracing.Tracer
+
 	// started флаг запуска
 	started bool
 
@@ -44,6 +50,7 @@ type subscription struct {
 	handler  core.EventHandlerFunc
 	filter   core.EventFilter
 	priority int
+	owner    string
 }
 
 // eventWrapper обертка события для очереди
@@ -68,6 +75,15 @@ func (eb *EventBus) SetWorkers(count int) {
 	eb.workers = count
 }
 
+// SetTracer включает трассировку: обработка события - span "event <type>",
+// дочерний для span публикации из ctx. Асинхронные события сохраняют контекст
+// публикации, поэтому их подписчики продолжают трассу запроса
+func (eb *EventBus) SetTracer(tracer *tracing.Tracer) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.tracer = tracer
+}
+
 // Subscribe подписывается на событие
 func (eb *EventBus) Subscribe(eventType string, handler core.EventHandlerFunc) error {
 	eb.mu.Lock()
@@ -111,6 +127,58 @@ func (eb *EventBus) SubscribeWithFilter(eventType string, handler core.EventHand
 	return nil
 }
 
+// SubscribeOwned подписывается на событие от имени владельца (например, модуля)
+// Все подписки владельца снимаются через UnsubscribeOwner
+func (eb *EventBus) SubscribeOwned(owner, eventType string, handler core.EventHandlerFunc) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+
+	sub := subscription{
+		handler:  handler,
+		priority: 50,
+		owner:    owner,
+	}
+
+	eb.subscribers[eventType] = append(eb.subscribers[eventType], sub)
+
+	eb.logger.Debug("Subscribed to event", "type", eventType, "owner", owner)
+
+	return nil
+}
+
+// UnsubscribeOwner снимает все подписки владельца
+func (eb *EventBus) UnsubscribeOwner(owner string) error {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	removed := 0
+	for eventType, subs := range eb.subscribers {
+		// Новый срез: Publish мог получить ссылку на старый
+		newSubs := make([]subscription, 0, len(subs))
+		for _, sub := range subs {
+			if sub.owner == owner {
+				removed++
+				continue
+			}
+			newSubs = append(newSubs, sub)
+		}
+
+		if len(newSubs) == 0 {
+			delete(eb.subscribers, eventType)
+		} else {
+			eb.subscribers[eventType] = newSubs
+		}
+	}
+
+	eb.logger.Debug("Unsubscribed owner", "owner", owner, "subscriptions", removed)
+
+	return nil
+}
+
 // Unsubscribe отписывается от события
 func (eb *EventBus) Unsubscribe(eventType string, handler core.EventHandlerFunc) error {
 	eb.mu.Lock()
@@ -149,6 +217,7 @@ func (eb *EventBus) Publish(ctx context.Context, event core.Event) error {
 	}
 
 	eb.mu.RLock()
+	tracer := eb.tracer
 	subs, exists := eb.subscribers[eventType]
 	if !exists {
 		subs = []subscription{}
@@ -166,6 +235,11 @@ func (eb *EventBus) Publish(ctx context.Context, event core.Event) error {
 		return nil
 	}
 
+	ctx, span := tracer.Start(ctx, "event "+eventType,
+		tracing.WithAttributes(tracing.Attr("event.type", eventType), tracing.Attr("event.source", event.Source())),
+	)
+	defer span.End()
+
 	// Обрабатываем событие
 	errors := make([]error, 0)
 	for _, sub := range subs {
@@ -195,7 +269,9 @@ func (eb *EventBus) Publish(ctx context.Context, event core.Event) error {
 	}
 
 	if len(errors) > 0 {
-		return fmt.Errorf("event processing had %d errors", len(errors))
+		err := fmt.Errorf("event processing had %d errors", len(errors))
+		span.RecordError(err)
+		return err
 	}
 
 	return nil
@@ -210,10 +286,12 @@ func (eb *EventBus) PublishAsync(ctx context.Context, event core.Event) {
 
 	select {
 	case eb.queue <- eventWrapper{ctx: ctx, event: event}:
-		// Успешно добавлено в очередь
+		// Успешно добавлено в очередь (span обработки станет дочерним для span публикации)
+		tracing.SpanFromContext(ctx).AddEvent("event.enqueued", tracing.Attr("event.type", event.Type()))
 		if eb.metrics != nil {
 			eb.metrics.Counter("events.queued", 1, "type", event.Type())
 		}
+		eb.reportQueueDepth()
 	default:
 		// Очередь переполнена
 		eb.logger.Error("Event queue full, dropping event", "type", event.Type())
@@ -311,6 +389,7 @@ func (eb *EventBus) worker(id int) {
 				eb.logger.Debug("Event worker stopped", "id", id)
 				return
 			}
+			eb.reportQueueDepth()
 
 			// Обрабатываем событие
 			if err := eb.Publish(wrapper.ctx, wrapper.event); err != nil {
@@ -328,6 +407,13 @@ func (eb *EventBus) worker(id int) {
 	}
 }
 
+// reportQueueDepth записывает число событий в очереди (gauge events.queue_depth)
+func (eb *EventBus) reportQueueDepth() {
+	if eb.metrics != nil {
+		eb.metrics.Gauge("events.queue_depth", float64(len(eb.queue)))
+	}
+}
+
 // GetStats возвращает статистику шины событий
 func (eb *EventBus) GetStats() EventBusStats {
 	eb.mu.RLock()
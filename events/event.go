@@ -1,7 +1,10 @@
 package events
 
 import (
+	"strings"
 	"time"
+
+	"github.com/andranikuz/botkit/core"
 )
 
 // Event базовая реализация события
@@ -105,8 +108,10 @@ type CommandExecutedEvent struct {
 	*Event
 	Command string
 	Module  string
+	Route   string
 	Success bool
 	Error   error
+	Latency time.Duration
 }
 
 // NewCommandExecutedEvent создает событие выполнения команды
@@ -143,6 +148,17 @@ func NewUserActionEvent(userID int64, action, resource, result string) *UserActi
 	}
 }
 
+// NewAdminActionEvent создает user.action об изменении, сделанном администратором
+// module - модуль управления, target - что изменено (resource), value - новое значение
+func NewAdminActionEvent(userID int64, module, action, target string, value interface{}) *UserActionEvent {
+	event := NewUserActionEvent(userID, action, target, "success")
+	event.SetData("module", module)
+	if value != nil {
+		event.SetData("value", value)
+	}
+	return event
+}
+
 // StateChangedEvent событие изменения состояния
 type StateChangedEvent struct {
 	*Event
@@ -228,4 +244,113 @@ func NewModuleLifecycleEvent(module, lifecycle, status string) *ModuleLifecycleE
 		Status:    status,
 		Details:   make(map[string]string),
 	}
-}
\ No newline at end of file
+}
+// ModuleHealthEvent событие изменения здоровья модуля
+type ModuleHealthEvent struct {
+	*Event
+	Module  string
+	Healthy bool
+	Message string
+	Details map[string]string
+}
+
+// NewModuleHealthEvent создает событие изменения здоровья модуля
+func NewModuleHealthEvent(module string, status core.HealthStatus) *ModuleHealthEvent {
+	event := NewEvent("module.health", module)
+	event.SetData("module", module).
+		SetData("healthy", status.Healthy).
+		SetData("message", status.Message)
+	
+	return &ModuleHealthEvent{
+		Event:   event,
+		Module:  module,
+		Healthy: status.Healthy,
+		Message: status.Message,
+		Details: status.Details,
+	}
+}
+
+// ConfigChangedEvent событие изменения конфигурации
+type ConfigChangedEvent struct {
+	*Event
+	Keys   []string // изменившиеся ключи: "modules.arena.max_opponents"
+	Reason string   // file, signal, admin, set, load
+}
+
+// NewConfigChangedEvent создает событие изменения конфигурации
+func NewConfigChangedEvent(keys []string, reason string) *ConfigChangedEvent {
+	event := NewEvent("config.changed", "config")
+	event.SetData("keys", keys).
+		SetData("reason", reason)
+	
+	return &ConfigChangedEvent{
+		Event:  event,
+		Keys:   keys,
+		Reason: reason,
+	}
+}
+
+// Affects проверяет, изменился ли ключ или секция prefix ("ratelimit" - любой ключ "ratelimit.*")
+func (e *ConfigChangedEvent) Affects(prefix string) bool {
+	for _, key := range e.Keys {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// SecurityDeniedEvent событие отказа в доступе
+type SecurityDeniedEvent struct {
+	*Event
+	Module string
+	Route  string
+	Reason string // not_authenticated, insufficient_role, rate_limited, ...
+	Error  string
+}
+
+// NewSecurityDeniedEvent создает событие отказа в доступе
+func NewSecurityDeniedEvent(userID, chatID int64, module, route, reason, errText string) *SecurityDeniedEvent {
+	event := NewEvent("security.denied", module)
+	event.SetUserID(userID).SetChatID(chatID)
+	event.SetData("module", module).
+		SetData("route", route).
+		SetData("reason", reason).
+		SetData("error", errText)
+	
+	return &SecurityDeniedEvent{
+		Event:  event,
+		Module: module,
+		Route:  route,
+		Reason: reason,
+		Error:  errText,
+	}
+}
+
+// ModerationEvent событие модерации (антиспам, баны)
+type ModerationEvent struct {
+	*Event
+	Action string // ignore, warn, mute, ban, unban
+	Reason string // repeat, callback_flood, links, forward, manual
+	Until  int64  // окончание мьюта/бана (unix), 0 - бессрочно
+}
+
+// NewModerationEvent создает событие модерации
+func NewModerationEvent(userID, chatID int64, action, reason string) *ModerationEvent {
+	event := NewEvent("moderation", "antispam")
+	event.SetUserID(userID).SetChatID(chatID)
+	event.SetData("action", action).SetData("reason", reason)
+	
+	return &ModerationEvent{
+		Event:  event,
+		Action: action,
+		Reason: reason,
+	}
+}
+
+// SetUntil устанавливает окончание действия
+func (e *ModerationEvent) SetUntil(until int64) *ModerationEvent {
+	e.Until = until
+	e.SetData("until", until)
+	return e
+}
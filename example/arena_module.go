@@ -2,8 +2,10 @@ package example
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/di"
 	"github.com/andranikuz/botkit/events"
 	"github.com/andranikuz/botkit/routing"
 	"time"
@@ -16,6 +18,13 @@ type ArenaModule struct {
 	eventBus     core.EventBus
 	logger       core.Logger
 	arenaService ArenaService // Сервис с бизнес-логикой
+	config       ArenaConfig
+}
+
+// ArenaConfig настройки арены (секция modules.arena)
+type ArenaConfig struct {
+	MaxOpponents int    `config:"max_opponents" default:"5" validate:"min=1,max=20"`
+	Mode         string `config:"mode" default:"ranked" validate:"oneof=ranked casual"`
 }
 
 // ArenaService интерфейс сервиса арены
@@ -43,17 +52,39 @@ func (m *ArenaModule) Version() string {
 	return m.version
 }
 
+// Metadata возвращает метаданные модуля (для /api/v1/introspection)
+func (m *ArenaModule) Metadata() core.ModuleMetadata {
+	return core.ModuleMetadata{
+		Name:        m.name,
+		Version:     m.version,
+		Author:      "botkit",
+		Description: "PvP арена: поиск противников, бои и статистика",
+		Tags:        []string{"game", "pvp"},
+	}
+}
+
+// ConfigSchema возвращает схему конфигурации (роутер заполняет ее до Init)
+func (m *ArenaModule) ConfigSchema() interface{} {
+	return &m.config
+}
+
 // Init инициализирует модуль
 func (m *ArenaModule) Init(deps core.Dependencies) error {
 	m.eventBus = deps.EventBus()
 	m.logger = deps.Logger()
 
-	// Получаем сервис арены из зависимостей
-	if service, ok := deps.Get("arenaService"); ok {
+	// Получаем сервис арены из зависимостей (по интерфейсу, без приведения типов)
+	if container, ok := deps.(*di.Container); ok {
+		service, err := di.Resolve[ArenaService](container)
+		if err != nil && !errors.Is(err, di.ErrNotFound) {
+			return fmt.Errorf("arena service: %w", err)
+		}
+		m.arenaService = service
+	} else if service, ok := deps.Get("arenaService"); ok {
 		m.arenaService = service.(ArenaService)
 	}
 
-	m.logger.Info("Arena module initialized", "version", m.version)
+	m.logger.Info("Arena module initialized", "version", m.version, "mode", m.config.Mode)
 
 	return nil
 }
@@ -201,6 +232,9 @@ func (m *ArenaModule) handleFightCommand(ctx core.UniversalContext) core.Respons
 	if len(opponents) == 0 {
 		return core.NewMessage("🔍 Нет доступных противников")
 	}
+	if len(opponents) > m.config.MaxOpponents {
+		opponents = opponents[:m.config.MaxOpponents]
+	}
 
 	text := "⚔️ <b>Выберите противника:</b>\n\n"
 
@@ -2,20 +2,30 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 
 	httpAdapter "github.com/andranikuz/botkit/adapters/http"
 	"github.com/andranikuz/botkit/adapters/telegram"
 	"github.com/andranikuz/botkit/adapters/websocket"
+	"github.com/andranikuz/botkit/audit"
+	"github.com/andranikuz/botkit/config"
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/di"
 	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/features"
+	"github.com/andranikuz/botkit/logging"
+	"github.com/andranikuz/botkit/metrics"
 	"github.com/andranikuz/botkit/routing"
+	"github.com/andranikuz/botkit/tracing"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/gorilla/mux"
 )
@@ -26,20 +36,51 @@ var (
 	token    = flag.String("token", "", "Telegram bot token")
 	httpPort = flag.String("http-port", ":8080", "HTTP server port")
 	wsPort   = flag.String("ws-port", ":8081", "WebSocket server port")
+	cfgPath  = flag.String("config", "", "Config file (yaml, json or toml)")
 )
 
 func main() {
 	flag.Parse()
 
 	// Create dependencies
-	logger := NewSimpleLogger()
-	eventBus := events.NewEventBus(logger, nil)
-	config := NewSimpleConfig()
-	deps := NewSimpleDependencies(eventBus, logger, config)
+	cfg, err := loadConfig(*cfgPath)
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	// Logger from the "log" config section (BOTKIT_LOG_LEVEL=debug, BOTKIT_LOG_FORMAT=text)
+	logger, err := logging.NewFromConfig(cfg, "log", os.Stderr)
+	if err != nil {
+		log.Fatal("Invalid log config:", err)
+	}
+
+	// Prometheus metrics, served by the HTTP adapter on GET /metrics
+	registry := metrics.New(metrics.Options{Namespace: "botkit"})
+	eventBus := events.NewEventBus(logger, registry)
+
+	// Tracing: spans are written to the debug log (trace_id is also added to request logs)
+	tracer := tracing.NewTracer("botkit", tracing.NewLogExporter(logger))
+	eventBus.SetTracer(tracer)
+	deps := NewSimpleDependencies(eventBus, logger, cfg)
 
 	// Create router
-	router := routing.NewRouter(eventBus, logger, config)
+	router := routing.NewRouter(eventBus, logger, cfg)
 	router.SetDependencies(deps)
+	router.SetMetrics(registry)
+	router.SetTracer(tracer)
+
+	// Feature flags from the "features" config section, changed at runtime with /feature commands
+	flags := features.New()
+	if err := flags.WatchConfig(cfg, features.DefaultConfigKey, eventBus); err != nil {
+		logger.Warn("Invalid feature flags", "error", err)
+	}
+	router.SetFeatureFlags(flags)
+	router.RegisterModule(features.NewAdminModule(flags, logger))
+
+	// Audit log of executed commands and user actions, queried via GET /api/v1/audit/records
+	// (use a persistent core.Storage in production)
+	auditLog := audit.New(NewMemoryStorage(), audit.Options{})
+	router.RegisterModule(audit.NewModule(auditLog, logger))
 
 	// Register modules
 	router.RegisterModule(NewUniversalModule())
@@ -56,6 +97,12 @@ func main() {
 		log.Fatal("Failed to start router:", err)
 	}
 
+	// Reload config on file change and SIGHUP, notify subscribers with "config.changed"
+	cfg.SetEventBus(eventBus)
+	if err := cfg.Watch(ctx, logger); err != nil {
+		logger.Warn("Config watch disabled", "error", err)
+	}
+
 	// Signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -63,15 +110,15 @@ func main() {
 	// Start adapters based on mode
 	switch *mode {
 	case "telegram":
-		runTelegram(router, logger, config)
+		runTelegram(router, logger, cfg, registry, tracer)
 	case "http":
-		runHTTP(router, logger, config)
+		runHTTP(router, logger, cfg, registry, tracer)
 	case "websocket":
-		runWebSocket(router, logger, config)
+		runWebSocket(router, logger, cfg, registry, tracer)
 	case "all":
-		go runTelegram(router, logger, config)
-		go runHTTP(router, logger, config)
-		go runWebSocket(router, logger, config)
+		go runTelegram(router, logger, cfg, registry, tracer)
+		go runHTTP(router, logger, cfg, registry, tracer)
+		go runWebSocket(router, logger, cfg, registry, tracer)
 	default:
 		log.Fatal("Invalid mode. Use: telegram, http, websocket, or all")
 	}
@@ -84,13 +131,18 @@ func main() {
 	router.Stop(ctx)
 }
 
-func runTelegram(router core.Router, logger core.Logger, config core.Config) {
-	if *token == "" {
+func runTelegram(router core.Router, logger core.Logger, config core.Config, registry *metrics.Registry, tracer *tracing.Tracer) {
+	// Флаг -token или telegram.token из конфигурации (BOTKIT_TELEGRAM_TOKEN)
+	botToken := *token
+	if botToken == "" {
+		botToken = config.GetString("telegram.token")
+	}
+	if botToken == "" {
 		logger.Warn("Telegram token not provided, skipping Telegram adapter")
 		return
 	}
 
-	bot, err := tgbotapi.NewBotAPI(*token)
+	bot, err := tgbotapi.NewBotAPI(botToken)
 	if err != nil {
 		logger.Error("Failed to create Telegram bot", "error", err)
 		return
@@ -100,6 +152,8 @@ func runTelegram(router core.Router, logger core.Logger, config core.Config) {
 
 	adapter := telegram.NewAdapter(bot, logger, config)
 	adapter.UseRouter(router)
+	adapter.SetMetrics(registry)
+	adapter.SetTracer(tracer)
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
@@ -110,9 +164,14 @@ func runTelegram(router core.Router, logger core.Logger, config core.Config) {
 	}
 }
 
-func runHTTP(router core.Router, logger core.Logger, config core.Config) {
+func runHTTP(router core.Router, logger core.Logger, config core.Config, registry *metrics.Registry, tracer *tracing.Tracer) {
 	adapter := httpAdapter.NewAdapter(logger, config)
 	adapter.UseRouter(router)
+	adapter.SetMetrics(registry)
+	adapter.SetTracer(tracer)
+	if token := config.GetString("http.api_token"); token != "" {
+		adapter.SetAuthorizer(httpAdapter.TokenAuthorizer(token))
+	}
 
 	logger.Info("HTTP server starting", "port", *httpPort)
 	if err := adapter.ListenAndServe(*httpPort); err != nil {
@@ -120,9 +179,11 @@ func runHTTP(router core.Router, logger core.Logger, config core.Config) {
 	}
 }
 
-func runWebSocket(router core.Router, logger core.Logger, config core.Config) {
+func runWebSocket(router core.Router, logger core.Logger, config core.Config, registry *metrics.Registry, tracer *tracing.Tracer) {
 	wsAdapter := websocket.NewAdapter(logger, config)
 	wsAdapter.UseRouter(router)
+	wsAdapter.SetMetrics(registry)
+	wsAdapter.SetTracer(tracer)
 
 	httpRouter := mux.NewRouter()
 	httpRouter.HandleFunc("/ws", wsAdapter.WebSocketHandler())
@@ -409,75 +470,82 @@ func (m *SimpleAIModule) HandleWildcard(ctx core.UniversalContext) core.Response
 	return core.NewMessage(response)
 }
 
-// Helper implementations
-type SimpleLogger struct{}
-
-func NewSimpleLogger() *SimpleLogger {
-	return &SimpleLogger{}
+// loadConfig загружает файл конфигурации (если указан) с переопределением
+// переменными окружения BOTKIT_* (BOTKIT_TELEGRAM_TOKEN)
+func loadConfig(path string) (*config.Config, error) {
+	cfg := config.New()
+	cfg.SetEnvPrefix("BOTKIT")
+	if path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
 }
 
-func (l *SimpleLogger) Debug(msg string, fields ...interface{}) {
-	log.Printf("[DEBUG] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Info(msg string, fields ...interface{}) {
-	log.Printf("[INFO] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Warn(msg string, fields ...interface{}) {
-	log.Printf("[WARN] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Error(msg string, fields ...interface{}) {
-	log.Printf("[ERROR] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Fatal(msg string, fields ...interface{}) {
-	log.Fatalf("[FATAL] %s %v", msg, fields)
+// NewSimpleDependencies создает контейнер зависимостей с общими сервисами
+// Модули получают дочерние контейнеры и достают зависимости типизированно: di.Resolve[T]
+func NewSimpleDependencies(eventBus core.EventBus, logger core.Logger, config core.Config) *di.Container {
+	deps := di.New()
+	_ = di.ProvideValue[core.EventBus](deps, eventBus)
+	_ = di.ProvideValue[core.Logger](deps, logger)
+	_ = di.ProvideValue[core.Config](deps, config)
+	return deps
 }
 
-func (l *SimpleLogger) WithField(key string, value interface{}) core.Logger {
-	return l
-}
-func (l *SimpleLogger) WithFields(fields map[string]interface{}) core.Logger {
-	return l
+// MemoryStorage хранилище в памяти (значения сериализуются в JSON, как в настоящих хранилищах)
+type MemoryStorage struct {
+	mu   sync.RWMutex
+	data map[string][]byte
 }
-func (l *SimpleLogger) WithError(err error) core.Logger {
-	return l
+
+// NewMemoryStorage создает хранилище в памяти
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{data: make(map[string][]byte)}
 }
 
-type SimpleConfig struct{}
+func (s *MemoryStorage) Save(ctx context.Context, key string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
 
-func NewSimpleConfig() *SimpleConfig {
-	return &SimpleConfig{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = encoded
+	return nil
 }
 
-func (c *SimpleConfig) Get(key string) interface{}                { return nil }
-func (c *SimpleConfig) GetString(key string) string               { return "" }
-func (c *SimpleConfig) GetInt(key string) int                     { return 0 }
-func (c *SimpleConfig) GetBool(key string) bool                   { return false }
-func (c *SimpleConfig) GetStringSlice(key string) []string        { return nil }
-func (c *SimpleConfig) GetStringMap(key string) map[string]string { return nil }
-func (c *SimpleConfig) Set(key string, value interface{})         {}
-func (c *SimpleConfig) IsSet(key string) bool                     { return false }
+func (s *MemoryStorage) Load(ctx context.Context, key string, dest interface{}) error {
+	s.mu.RLock()
+	encoded, ok := s.data[key]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("key %s not found", key)
+	}
+	return json.Unmarshal(encoded, dest)
+}
 
-type SimpleDependencies struct {
-	eventBus core.EventBus
-	logger   core.Logger
-	config   core.Config
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
 }
 
-func NewSimpleDependencies(eventBus core.EventBus, logger core.Logger, config core.Config) *SimpleDependencies {
-	return &SimpleDependencies{
-		eventBus: eventBus,
-		logger:   logger,
-		config:   config,
+func (s *MemoryStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
 	}
+	return keys, nil
 }
 
-func (d *SimpleDependencies) Database() interface{}              { return nil }
-func (d *SimpleDependencies) EventBus() core.EventBus            { return d.eventBus }
-func (d *SimpleDependencies) Logger() core.Logger                { return d.logger }
-func (d *SimpleDependencies) Config() core.Config                { return d.config }
-func (d *SimpleDependencies) Get(key string) (interface{}, bool) { return nil, false }
-func (d *SimpleDependencies) Set(key string, value interface{})  {}
-
 // serveWebSocketTestPage serves a test HTML page
 func serveWebSocketTestPage(w http.ResponseWriter, r *http.Request) {
 	html := `<!DOCTYPE html>
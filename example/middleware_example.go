@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/andranikuz/botkit/core"
@@ -12,16 +13,19 @@ import (
 	"github.com/andranikuz/botkit/routing"
 )
 
+// requestSourceKey ключ значения в контексте запроса
+type requestSourceKey struct{}
+
 // MiddlewareExample демонстрирует использование middleware
 func MiddlewareExample() {
 	// Создаем зависимости
-	logger := NewSimpleLogger()
+	logger := NewLogger()
 	eventBus := events.NewEventBus(logger, nil)
-	config := NewSimpleConfig()
-	deps := NewSimpleDependencies(eventBus, logger, config)
+	cfg := NewConfig()
+	deps := NewSimpleDependencies(eventBus, logger, cfg)
 
 	// Создаем роутер
-	router := routing.NewRouter(eventBus, logger, config)
+	router := routing.NewRouter(eventBus, logger, cfg)
 	router.SetDependencies(deps)
 
 	// Создаем и регистрируем middleware
@@ -35,7 +39,7 @@ func MiddlewareExample() {
 	router.RegisterMiddleware(loggingMW)
 
 	// 3. Rate limiting middleware
-	// Используем RateLimiter из security.go
+	// Используем RateLimiter из routing (счетчики в памяти)
 	rateLimitConfig := &routing.RateLimitConfig{
 		Requests: 10,
 		Window:   60,
@@ -58,11 +62,14 @@ func MiddlewareExample() {
 	}, 70)
 	router.RegisterMiddleware(validationMW)
 
-	// 5. Custom context middleware с таймаутом
+	// 5. Timeout middleware: обработчик получает контекст с дедлайном,
+	// при превышении пользователь сразу получает ответ о таймауте
+	timeoutMW := middleware.NewTimeoutMiddleware(3*time.Second, 65)
+	router.RegisterMiddleware(timeoutMW)
+
+	// Custom context middleware: добавляет значения в контекст запроса
 	contextMW := middleware.NewContextMiddleware(func(ctx core.UniversalContext) context.Context {
-		// Создаем контекст с таймаутом 30 секунд
-		reqCtx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-		return reqCtx
+		return context.WithValue(ctx.Context(), requestSourceKey{}, ctx.GetSource())
 	}, 60)
 	router.RegisterMiddleware(contextMW)
 
@@ -81,6 +88,14 @@ func MiddlewareExample() {
 	})
 	router.RegisterMiddleware(customMW)
 
+	// 7. Индикатор "печатает...", если обработчик работает дольше секунды
+	chatActionMW := middleware.NewChatActionMiddleware(core.ChatActionTyping, time.Second, 40)
+	router.RegisterMiddleware(chatActionMW)
+
+	// 8. Кеширование ответов маршрутов с политикой (RouteBuilder.Cache)
+	cachingMW := middleware.NewCachingMiddleware(NewSimpleCache(), 75)
+	router.RegisterMiddleware(cachingMW)
+
 	// Регистрируем модули
 	router.RegisterModule(NewMiddlewareTestModule())
 
@@ -97,6 +112,8 @@ func MiddlewareExample() {
 	log.Println("4. Validation (priority: 70) - валидация данных")
 	log.Println("5. Context (priority: 60) - добавление контекста")
 	log.Println("6. Custom (priority: 50) - кастомная логика")
+	log.Println("7. ChatAction (priority: 40) - индикатор набора текста")
+	log.Println("8. Caching (priority: 75) - кеширование ответов")
 }
 
 // MiddlewareTestModule модуль для тестирования middleware
@@ -162,6 +179,14 @@ func (m *MiddlewareTestModule) Routes() []core.RoutePattern {
 				Description: "Test slow operation",
 			},
 		},
+		routing.NewRoute("/stats {name}").
+			Handler(m.handleStats).
+			Priority(100).
+			Cache(time.Minute).
+			CacheKey(routing.CacheKeyParams, routing.CacheKeyLocale).
+			CacheShared().
+			Meta("stats", "Test response caching").
+			Build(),
 		routing.RoutePattern{
 			Patterns: []string{"/auth", "auth"},
 			Handler:  m.handleAuth,
@@ -207,20 +232,20 @@ func (m *MiddlewareTestModule) handleSlow(ctx core.UniversalContext) core.Respon
 	// Симулируем долгую операцию
 	m.logger.Info("Starting slow operation...")
 
-	// Получаем контекст, добавленный middleware
-	if reqCtx, ok := ctx.GetParam("_context"); ok {
-		if ctx, ok := reqCtx.(context.Context); ok {
-			select {
-			case <-time.After(5 * time.Second):
-				return core.NewMessage("✅ Slow operation completed!")
-			case <-ctx.Done():
-				return core.NewMessage("⏱️ Operation cancelled by timeout")
-			}
-		}
+	// Контекст с дедлайном устанавливает TimeoutMiddleware
+	select {
+	case <-time.After(5 * time.Second):
+		return core.NewMessage("✅ Slow operation completed!")
+	case <-ctx.Context().Done():
+		m.logger.Info("Slow operation cancelled", "error", ctx.Context().Err())
+		return core.NewSilentResponse()
 	}
+}
 
-	time.Sleep(5 * time.Second)
-	return core.NewMessage("✅ Slow operation completed (no context)")
+func (m *MiddlewareTestModule) handleStats(ctx core.UniversalContext) core.Response {
+	// Повторный запрос в течение минуты получит закешированный ответ
+	name, _ := ctx.GetStringParam("name")
+	return core.NewMessage(fmt.Sprintf("📊 Статистика %s на %s", name, time.Now().Format(time.TimeOnly)))
 }
 
 func (m *MiddlewareTestModule) handleAuth(ctx core.UniversalContext) core.Response {
@@ -231,7 +256,7 @@ func (m *MiddlewareTestModule) handleAuth(ctx core.UniversalContext) core.Respon
 
 // DemoMiddlewareChain демонстрирует создание цепочки middleware
 func DemoMiddlewareChain() {
-	logger := NewSimpleLogger()
+	logger := NewLogger()
 
 	// Создаем цепочку middleware
 	middlewares := []routing.Middleware{
@@ -306,50 +331,63 @@ func (m *CustomAuthMiddleware) Process(ctx core.UniversalContext, next core.Hand
 	return next(ctx)
 }
 
-// CachingMiddleware пример middleware для кеширования
-type CachingMiddleware struct {
-	cache    map[string]core.Response
-	ttl      time.Duration
-	priority int
+// SimpleCache простой in-memory core.Cache для примеров
+type SimpleCache struct {
+	mu      sync.Mutex
+	items   map[string]interface{}
+	expires map[string]time.Time
 }
 
-func NewCachingMiddleware(ttl time.Duration) *CachingMiddleware {
-	return &CachingMiddleware{
-		cache:    make(map[string]core.Response),
-		ttl:      ttl,
-		priority: 75,
+func NewSimpleCache() *SimpleCache {
+	return &SimpleCache{
+		items:   make(map[string]interface{}),
+		expires: make(map[string]time.Time),
 	}
 }
 
-func (m *CachingMiddleware) Name() string {
-	return "caching"
-}
+func (c *SimpleCache) Get(ctx context.Context, key string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-func (m *CachingMiddleware) Priority() int {
-	return m.priority
+	if exp, ok := c.expires[key]; ok && time.Now().After(exp) {
+		delete(c.items, key)
+		delete(c.expires, key)
+	}
+	return c.items[key], nil
 }
 
-func (m *CachingMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
-	// Создаем ключ кеша
-	key := fmt.Sprintf("%d:%s", ctx.GetUserID(), ctx.GetText())
+func (c *SimpleCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// Проверяем кеш
-	if cached, ok := m.cache[key]; ok {
-		// Возвращаем закешированный ответ
-		return cached
+	c.items[key] = value
+	if ttl > 0 {
+		c.expires[key] = time.Now().Add(time.Duration(ttl) * time.Second)
+	} else {
+		delete(c.expires, key)
 	}
+	return nil
+}
 
-	// Выполняем обработчик
-	response := next(ctx)
+func (c *SimpleCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// Кешируем ответ
-	m.cache[key] = response
+	delete(c.items, key)
+	delete(c.expires, key)
+	return nil
+}
+
+func (c *SimpleCache) Exists(ctx context.Context, key string) (bool, error) {
+	value, _ := c.Get(ctx, key)
+	return value != nil, nil
+}
 
-	// Очищаем кеш через TTL
-	go func() {
-		time.Sleep(m.ttl)
-		delete(m.cache, key)
-	}()
+func (c *SimpleCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return response
+	c.items = make(map[string]interface{})
+	c.expires = make(map[string]time.Time)
+	return nil
 }
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/andranikuz/botkit/adapters/websocket"
+	"github.com/andranikuz/botkit/config"
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/di"
 	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/logging"
 	"github.com/andranikuz/botkit/routing"
 	"github.com/gorilla/mux"
 )
@@ -16,15 +20,15 @@ import (
 // WebSocketExample пример использования WebSocket адаптера
 func WebSocketExample() {
 	// Создаем зависимости
-	logger := NewSimpleLogger()
+	logger := NewLogger()
 	eventBus := events.NewEventBus(logger, nil)
-	config := NewSimpleConfig()
+	cfg := NewConfig()
 
 	// Создаем роутер
-	router := routing.NewRouter(eventBus, logger, config)
+	router := routing.NewRouter(eventBus, logger, cfg)
 
 	// Создаем зависимости для модулей
-	deps := NewSimpleDependencies(eventBus, logger, config)
+	deps := NewSimpleDependencies(eventBus, logger, cfg)
 	router.SetDependencies(deps)
 
 	// Регистрируем модули
@@ -37,7 +41,7 @@ func WebSocketExample() {
 	}
 
 	// Создаем WebSocket адаптер
-	wsAdapter := websocket.NewAdapter(logger, config)
+	wsAdapter := websocket.NewAdapter(logger, cfg)
 	wsAdapter.UseRouter(router)
 
 	// Создаем HTTP роутер
@@ -320,64 +324,33 @@ func (m *ChatModule) handleHello(ctx core.UniversalContext) core.Response {
 
 // Helper implementations
 
-type SimpleLogger struct{}
-
-func NewSimpleLogger() *SimpleLogger {
-	return &SimpleLogger{}
-}
-
-func (l *SimpleLogger) Debug(msg string, fields ...interface{}) {
-	log.Printf("[DEBUG] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Info(msg string, fields ...interface{}) {
-	log.Printf("[INFO] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Warn(msg string, fields ...interface{}) {
-	log.Printf("[WARN] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Error(msg string, fields ...interface{}) {
-	log.Printf("[ERROR] %s %v", msg, fields)
-}
-func (l *SimpleLogger) Fatal(msg string, fields ...interface{}) {
-	log.Fatalf("[FATAL] %s %v", msg, fields)
-}
-
-func (l *SimpleLogger) WithField(key string, value interface{}) core.Logger  { return l }
-func (l *SimpleLogger) WithFields(fields map[string]interface{}) core.Logger { return l }
-func (l *SimpleLogger) WithError(err error) core.Logger                      { return l }
-
-type SimpleConfig struct{}
-
-func NewSimpleConfig() *SimpleConfig {
-	return &SimpleConfig{}
+// NewLogger создает логгер примеров: текстовый вывод с отладочными записями,
+// повторяющиеся отладочные записи ограничены 10 в секунду
+func NewLogger() core.Logger {
+	return logging.New(logging.Options{
+		Format: logging.FormatText,
+		Level:  slog.LevelDebug,
+		Sampling: &logging.Sampling{
+			Level: slog.LevelDebug,
+			First: 10,
+		},
+	})
 }
 
-func (c *SimpleConfig) Get(key string) interface{}                { return nil }
-func (c *SimpleConfig) GetString(key string) string               { return "" }
-func (c *SimpleConfig) GetInt(key string) int                     { return 0 }
-func (c *SimpleConfig) GetBool(key string) bool                   { return false }
-func (c *SimpleConfig) GetStringSlice(key string) []string        { return nil }
-func (c *SimpleConfig) GetStringMap(key string) map[string]string { return nil }
-func (c *SimpleConfig) Set(key string, value interface{})         {}
-func (c *SimpleConfig) IsSet(key string) bool                     { return false }
-
-type SimpleDependencies struct {
-	eventBus core.EventBus
-	logger   core.Logger
-	config   core.Config
+// NewConfig создает конфигурацию примеров: значения переопределяются
+// переменными окружения BOTKIT_* (BOTKIT_MODULES_ARENA_MAX_OPPONENTS=3)
+func NewConfig() *config.Config {
+	cfg := config.New()
+	cfg.SetEnvPrefix("BOTKIT")
+	return cfg
 }
 
-func NewSimpleDependencies(eventBus core.EventBus, logger core.Logger, config core.Config) *SimpleDependencies {
-	return &SimpleDependencies{
-		eventBus: eventBus,
-		logger:   logger,
-		config:   config,
-	}
+// NewSimpleDependencies создает контейнер зависимостей с общими сервисами
+// Модули получают дочерние контейнеры и достают зависимости типизированно: di.Resolve[T]
+func NewSimpleDependencies(eventBus core.EventBus, logger core.Logger, config core.Config) *di.Container {
+	deps := di.New()
+	_ = di.ProvideValue[core.EventBus](deps, eventBus)
+	_ = di.ProvideValue[core.Logger](deps, logger)
+	_ = di.ProvideValue[core.Config](deps, config)
+	return deps
 }
-
-func (d *SimpleDependencies) Database() interface{}              { return nil }
-func (d *SimpleDependencies) EventBus() core.EventBus            { return d.eventBus }
-func (d *SimpleDependencies) Logger() core.Logger                { return d.logger }
-func (d *SimpleDependencies) Config() core.Config                { return d.config }
-func (d *SimpleDependencies) Get(key string) (interface{}, bool) { return nil, false }
-func (d *SimpleDependencies) Set(key string, value interface{})  {}
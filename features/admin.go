@@ -0,0 +1,256 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/routing"
+)
+
+var (
+	_ core.Module    = (*AdminModule)(nil)
+	_ core.APIModule = (*AdminModule)(nil)
+)
+
+// AdminModule команды и HTTP API для управления флагами во время работы
+//
+// Команды (только для ролей администратора):
+//
+//	/feature {name}                   - состояние флага
+//	/feature on {name}, /feature off {name}
+//	/feature rollout {name} {text}    - процент раскатки (0 - никому, 2.5 - 2.5%)
+//	/feature reset {name}             - вернуть значение из конфигурации
+//
+// HTTP API (/api/v1/features/...): GET /flags, PUT /flags, DELETE /flags?name=.
+// Изменения действуют до перезапуска; постоянные значения задаются в конфигурации.
+// Каждое изменение публикуется событием user.action (module "features").
+type AdminModule struct {
+	flags    *Flags
+	logger   core.Logger
+	roles    []string
+	eventBus core.EventBus
+}
+
+// NewAdminModule создает модуль управления флагами
+// roles - роли, которым доступны команды (по умолчанию "admin")
+func NewAdminModule(flags *Flags, logger core.Logger, roles ...string) *AdminModule {
+	if len(roles) == 0 {
+		roles = []string{"admin"}
+	}
+
+	return &AdminModule{
+		flags:  flags,
+		logger: logger,
+		roles:  roles,
+	}
+}
+
+func (m *AdminModule) Name() string    { return "features" }
+func (m *AdminModule) Version() string { return "1.0.0" }
+
+func (m *AdminModule) Init(deps core.Dependencies) error {
+	if deps != nil {
+		m.eventBus = deps.EventBus()
+	}
+	return nil
+}
+
+func (m *AdminModule) Start(ctx context.Context) error { return nil }
+func (m *AdminModule) Stop(ctx context.Context) error  { return nil }
+
+// Routes возвращает админские команды
+func (m *AdminModule) Routes() []core.RoutePattern {
+	return []core.RoutePattern{
+		routing.NewRoute("/feature {name}").
+			Handler(m.handleStatus).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("feature_status", "Состояние флага").
+			Hidden().
+			Build(),
+		routing.NewRoute("/feature on {name}", "/feature off {name}").
+			Handler(m.handleToggle).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("feature_toggle", "Включить/выключить флаг").
+			Hidden().
+			Build(),
+		routing.NewRoute("/feature rollout {name} {text}").
+			Handler(m.handleRollout).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("feature_rollout", "Процент раскатки флага").
+			Hidden().
+			Build(),
+		routing.NewRoute("/feature reset {name}").
+			Handler(m.handleReset).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("feature_reset", "Вернуть флаг из конфигурации").
+			Hidden().
+			Build(),
+	}
+}
+
+// APIHandlers возвращает HTTP endpoints
+func (m *AdminModule) APIHandlers() []core.APIHandler {
+	return []core.APIHandler{
+		{Method: "GET", Path: "/flags", Handler: m.apiList, Admin: true, Description: "Список флагов"},
+		{Method: "PUT", Path: "/flags", Handler: m.apiSet, Admin: true, Description: "Изменить флаг до перезапуска"},
+		{Method: "DELETE", Path: "/flags", Handler: m.apiReset, Admin: true, Description: "Вернуть флаг из конфигурации"},
+	}
+}
+
+func (m *AdminModule) handleStatus(ctx core.UniversalContext) core.Response {
+	name := param(ctx, "name")
+
+	flag, ok := m.flags.Get(name)
+	if !ok {
+		return core.NewMessage(fmt.Sprintf("❓ Флаг %s не найден", name))
+	}
+	if !flag.Enabled {
+		return core.NewMessage(fmt.Sprintf("⚪️ %s выключен", name))
+	}
+	if percentage, ok := flag.Rollout(); ok {
+		return core.NewMessage(fmt.Sprintf("🟡 %s включен для %g%% пользователей", name, percentage))
+	}
+	return core.NewMessage(fmt.Sprintf("🟢 %s включен", name))
+}
+
+func (m *AdminModule) handleToggle(ctx core.UniversalContext) core.Response {
+	name := param(ctx, "name")
+	pattern := param(ctx, "_pattern")
+	enabled := pattern == "/feature on {name}"
+
+	flag := m.flag(name)
+	flag.Enabled = enabled
+	m.set(ctx.Context(), flag, ctx.GetUserID())
+
+	if enabled {
+		return core.NewMessage(fmt.Sprintf("🟢 %s включен", name))
+	}
+	return core.NewMessage(fmt.Sprintf("⚪️ %s выключен", name))
+}
+
+func (m *AdminModule) handleRollout(ctx core.UniversalContext) core.Response {
+	name := param(ctx, "name")
+	percentage, err := parsePercentage(param(ctx, "text"))
+	if err != nil {
+		return core.NewMessage("❌ Укажите процент от 0 до 100")
+	}
+
+	flag := m.flag(name)
+	flag.Enabled = true
+	flag.Percentage = Percent(percentage)
+	m.set(ctx.Context(), flag, ctx.GetUserID())
+
+	return core.NewMessage(fmt.Sprintf("🟡 %s включен для %g%% пользователей", name, percentage))
+}
+
+func (m *AdminModule) handleReset(ctx core.UniversalContext) core.Response {
+	name := param(ctx, "name")
+	m.reset(ctx.Context(), name, ctx.GetUserID())
+	return core.NewMessage(fmt.Sprintf("↩️ %s: действует значение из конфигурации", name))
+}
+
+func (m *AdminModule) apiList(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	return core.APIResponse{Body: m.flags.List()}, nil
+}
+
+func (m *AdminModule) apiSet(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	data, err := json.Marshal(req.Body)
+	if err != nil {
+		return core.APIResponse{}, err
+	}
+
+	var flag Flag
+	if err := json.Unmarshal(data, &flag); err != nil || flag.Name == "" {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": "flag name is required"}}, nil
+	}
+	if flag.Percentage != nil && !validPercentage(*flag.Percentage) {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": "percentage must be between 0 and 100"}}, nil
+	}
+
+	m.set(ctx, flag, req.UserID)
+	return core.APIResponse{Body: flag}, nil
+}
+
+func (m *AdminModule) apiReset(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	name := req.Query["name"]
+	if name == "" {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": "name is required"}}, nil
+	}
+
+	m.reset(ctx, name, req.UserID)
+	return core.APIResponse{Body: map[string]bool{"success": true}}, nil
+}
+
+// parsePercentage разбирает процент раскатки: "10", "2.5", "2,5", "5%"
+func parsePercentage(value string) (float64, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	percentage, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if err != nil {
+		return 0, err
+	}
+	if !validPercentage(percentage) {
+		return 0, fmt.Errorf("percentage %g is out of range", percentage)
+	}
+	return percentage, nil
+}
+
+// validPercentage проверяет, что процент от 0 до 100
+func validPercentage(percentage float64) bool {
+	return percentage >= 0 && percentage <= 100
+}
+
+// flag возвращает действующий флаг или новый выключенный
+func (m *AdminModule) flag(name string) Flag {
+	if flag, ok := m.flags.Get(name); ok {
+		return flag
+	}
+	return Flag{Name: name}
+}
+
+// set переопределяет флаг, пишет изменение в лог и публикует user.action "feature.set"
+func (m *AdminModule) set(ctx context.Context, flag Flag, userID int64) {
+	m.flags.Set(flag)
+	m.publish(ctx, userID, "feature.set", flag.Name, flag)
+	if m.logger != nil {
+		fields := []interface{}{
+			"flag", flag.Name,
+			"enabled", flag.Enabled,
+			"user_id", userID,
+		}
+		if percentage, ok := flag.Rollout(); ok {
+			fields = append(fields, "percentage", percentage)
+		}
+		m.logger.Info("Feature flag changed", fields...)
+	}
+}
+
+// reset возвращает флаг из конфигурации и публикует user.action "feature.reset"
+func (m *AdminModule) reset(ctx context.Context, name string, userID int64) {
+	m.flags.Reset(name)
+	m.publish(ctx, userID, "feature.reset", name, nil)
+}
+
+// publish сообщает об изменении флага событием user.action
+func (m *AdminModule) publish(ctx context.Context, userID int64, action, name string, value interface{}) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.PublishAsync(context.WithoutCancel(ctx), events.NewAdminActionEvent(userID, m.Name(), action, name, value))
+}
+
+// param читает строковый параметр маршрута
+func param(ctx core.UniversalContext, name string) string {
+	value, _ := ctx.GetParam(name)
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,131 @@
+package features
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+)
+
+// recordingBus core.EventBus, запоминающий опубликованные события
+type recordingBus struct {
+	mu     sync.Mutex
+	events []core.Event
+}
+
+func (b *recordingBus) Subscribe(eventType string, handler core.EventHandlerFunc) error   { return nil }
+func (b *recordingBus) Unsubscribe(eventType string, handler core.EventHandlerFunc) error { return nil }
+func (b *recordingBus) Start(ctx context.Context) error                                   { return nil }
+func (b *recordingBus) Stop(ctx context.Context) error                                    { return nil }
+
+func (b *recordingBus) Publish(ctx context.Context, event core.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, event)
+	return nil
+}
+
+func (b *recordingBus) PublishAsync(ctx context.Context, event core.Event) {
+	_ = b.Publish(ctx, event)
+}
+
+func adminContext(params map[string]string) core.UniversalContext {
+	ctx := core.NewBaseContext(context.Background())
+	ctx.SetUserID(7)
+	for key, value := range params {
+		ctx.SetParam(key, value)
+	}
+	return ctx
+}
+
+func TestAdminModulePublishesActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func(m *AdminModule)
+		action   string
+		resource string
+		enabled  bool
+	}{
+		{
+			name: "command on",
+			run: func(m *AdminModule) {
+				m.handleToggle(adminContext(map[string]string{"name": "arena", "_pattern": "/feature on {name}"}))
+			},
+			action: "feature.set", resource: "arena", enabled: true,
+		},
+		{
+			name: "command rollout",
+			run: func(m *AdminModule) {
+				m.handleRollout(adminContext(map[string]string{"name": "arena", "text": "2,5"}))
+			},
+			action: "feature.set", resource: "arena", enabled: true,
+		},
+		{
+			name: "command reset",
+			run: func(m *AdminModule) {
+				m.handleReset(adminContext(map[string]string{"name": "arena"}))
+			},
+			action: "feature.reset", resource: "arena",
+		},
+		{
+			name: "api set",
+			run: func(m *AdminModule) {
+				_, _ = m.apiSet(context.Background(), core.APIRequest{UserID: 7, Body: map[string]interface{}{"name": "shop", "enabled": true}})
+			},
+			action: "feature.set", resource: "shop", enabled: true,
+		},
+		{
+			name: "api reset",
+			run: func(m *AdminModule) {
+				_, _ = m.apiReset(context.Background(), core.APIRequest{UserID: 7, Query: map[string]string{"name": "shop"}})
+			},
+			action: "feature.reset", resource: "shop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &recordingBus{}
+			m := NewAdminModule(New(), nil)
+			m.eventBus = bus
+
+			tt.run(m)
+
+			if len(bus.events) != 1 {
+				t.Fatalf("published %d events, want 1", len(bus.events))
+			}
+			event, ok := bus.events[0].(*events.UserActionEvent)
+			if !ok {
+				t.Fatalf("published %T, want *events.UserActionEvent", bus.events[0])
+			}
+			if event.UserID() != 7 || event.Action != tt.action || event.Resource != tt.resource {
+				t.Fatalf("event = user %d, %s %s, want user 7, %s %s", event.UserID(), event.Action, event.Resource, tt.action, tt.resource)
+			}
+			if module, _ := event.GetData("module"); module != "features" {
+				t.Errorf("module = %v, want features", module)
+			}
+			if flag, ok := event.Data()["value"].(Flag); ok && flag.Enabled != tt.enabled {
+				t.Errorf("value.Enabled = %v, want %v", flag.Enabled, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestAdminModuleSkipsRejectedChanges(t *testing.T) {
+	bus := &recordingBus{}
+	m := NewAdminModule(New(), nil)
+	m.eventBus = bus
+
+	m.handleRollout(adminContext(map[string]string{"name": "arena", "text": "150"}))
+	resp, _ := m.apiSet(context.Background(), core.APIRequest{Body: map[string]interface{}{"enabled": true}})
+	if resp.Status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", resp.Status)
+	}
+
+	if len(bus.events) != 0 {
+		t.Fatalf("published %d events for rejected changes", len(bus.events))
+	}
+}
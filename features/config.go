@@ -0,0 +1,75 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+)
+
+// DefaultConfigKey секция конфигурации с флагами
+const DefaultConfigKey = "features"
+
+// LoadConfig загружает флаги из секции key конфигурации:
+//
+//	features:
+//	  new_arena:
+//	    enabled: true
+//	    percentage: 10
+//	    roles: [tester]
+//
+// При ошибке в любом флаге действуют прежние флаги
+func (f *Flags) LoadConfig(cfg core.Config, key string) error {
+	sectioned, ok := cfg.(core.SectionedConfig)
+	if !ok {
+		return fmt.Errorf("feature flags require sectioned config, got %T", cfg)
+	}
+
+	section, _ := cfg.Get(key).(map[string]interface{})
+
+	names := make([]string, 0, len(section))
+	for name := range section {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	flags := make([]Flag, 0, len(names))
+	var errs []error
+	for _, name := range names {
+		var flag Flag
+		if err := sectioned.Bind(key+"."+name, &flag); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		flag.Name = name
+		flags = append(flags, flag)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid feature flags: %w", errors.Join(errs...))
+	}
+
+	f.Replace(flags)
+	return nil
+}
+
+// WatchConfig загружает флаги из секции key и перечитывает их
+// при событии "config.changed" этой секции
+func (f *Flags) WatchConfig(cfg core.Config, key string, bus core.EventBus) error {
+	if err := f.LoadConfig(cfg, key); err != nil {
+		return err
+	}
+	if bus == nil {
+		return nil
+	}
+
+	return bus.Subscribe("config.changed", func(ctx context.Context, event core.Event) error {
+		if changed, ok := event.(*events.ConfigChangedEvent); ok && !changed.Affects(key) {
+			return nil
+		}
+		return f.LoadConfig(cfg, key)
+	})
+}
@@ -0,0 +1,202 @@
+package features
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+var _ core.FeatureFlags = (*Flags)(nil)
+
+// Flag флаг функциональности
+//
+// Флаг проверяется для запроса по правилам:
+//  1. выключенный флаг (Enabled = false) выключен для всех
+//  2. пользователям из Users флаг включен всегда
+//  3. Sources и Locales, если заданы, ограничивают аудиторию
+//  4. роль из Roles включает флаг (например, "tester")
+//  5. Percentage включает флаг доле пользователей: пользователь попадает
+//     в долю по хешу ID и имени флага, поэтому решение стабильно между запросами.
+//     Percentage 0 - флаг выключен для всех, кроме Users и Roles
+//
+// Флаг без Users, Roles и Percentage (nil) включен для всех подходящих пользователей
+type Flag struct {
+	Name        string   `json:"name" config:"-"`
+	Description string   `json:"description,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	Percentage  *float64 `json:"percentage,omitempty" validate:"min=0,max=100"`
+	Users       []int64  `json:"users,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Sources     []string `json:"sources,omitempty"`
+	Locales     []string `json:"locales,omitempty"`
+}
+
+// Evaluate проверяет флаг для запроса
+func (f Flag) Evaluate(ctx core.UniversalContext) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	userID := ctx.GetUserID()
+	for _, id := range f.Users {
+		if id == userID {
+			return true
+		}
+	}
+
+	if len(f.Sources) > 0 && !containsFold(f.Sources, ctx.GetSource()) {
+		return false
+	}
+	if len(f.Locales) > 0 && !matchLocale(f.Locales, ctx.GetLocale()) {
+		return false
+	}
+
+	if len(f.Users) == 0 && len(f.Roles) == 0 && f.Percentage == nil {
+		return true
+	}
+
+	for _, role := range ctx.GetRoles() {
+		if containsFold(f.Roles, role) {
+			return true
+		}
+	}
+
+	percentage, ok := f.Rollout()
+	return ok && Bucket(f.Name, userID) < percentage
+}
+
+// Rollout возвращает процент раскатки (false - процент не задан)
+func (f Flag) Rollout() (float64, bool) {
+	if f.Percentage == nil {
+		return 0, false
+	}
+	return *f.Percentage, true
+}
+
+// Percent возвращает указатель на процент раскатки для Flag.Percentage
+func Percent(value float64) *float64 {
+	return &value
+}
+
+// Bucket возвращает позицию пользователя в раскатке флага: число от 0 до 100
+// Пользователь с Bucket 7.3 видит флаг при Percentage больше 7.3
+func Bucket(flag string, userID int64) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(flag))
+	h.Write([]byte{':'})
+	h.Write([]byte(strconv.FormatInt(userID, 10)))
+	return float64(h.Sum32()%10000) / 100
+}
+
+// Flags набор флагов функциональности (реализует core.FeatureFlags)
+//
+// Флаги приходят из конфигурации (LoadConfig, WatchConfig) и могут быть
+// переопределены во время работы (Set, AdminModule) до перезапуска или Reset.
+// Неизвестный флаг выключен
+type Flags struct {
+	mu         sync.RWMutex
+	configured map[string]Flag
+	overrides  map[string]Flag
+}
+
+// New создает набор флагов
+func New(flags ...Flag) *Flags {
+	f := &Flags{
+		configured: make(map[string]Flag),
+		overrides:  make(map[string]Flag),
+	}
+	f.Replace(flags)
+	return f
+}
+
+// Enabled проверяет, включен ли флаг для пользователя запроса
+func (f *Flags) Enabled(ctx core.UniversalContext, name string) bool {
+	flag, ok := f.Get(name)
+	return ok && flag.Evaluate(ctx)
+}
+
+// Get возвращает действующий флаг (переопределение или флаг конфигурации)
+func (f *Flags) Get(name string) (Flag, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if flag, ok := f.overrides[name]; ok {
+		return flag, true
+	}
+	flag, ok := f.configured[name]
+	return flag, ok
+}
+
+// List возвращает действующие флаги, отсортированные по имени
+func (f *Flags) List() []Flag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	result := make([]Flag, 0, len(f.configured)+len(f.overrides))
+	for name, flag := range f.configured {
+		if _, overridden := f.overrides[name]; !overridden {
+			result = append(result, flag)
+		}
+	}
+	for _, flag := range f.overrides {
+		result = append(result, flag)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Set переопределяет флаг во время работы (до перезапуска или Reset)
+func (f *Flags) Set(flag Flag) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.overrides[flag.Name] = flag
+}
+
+// Reset снимает переопределение: действует значение из конфигурации
+func (f *Flags) Reset(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.overrides, name)
+}
+
+// Replace заменяет флаги конфигурации (переопределения сохраняются)
+func (f *Flags) Replace(flags []Flag) {
+	configured := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		configured[flag.Name] = flag
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.configured = configured
+}
+
+// containsFold проверяет наличие строки без учета регистра
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLocale проверяет локаль: "ru" подходит для "ru", "ru-RU" и "ru_RU"
+func matchLocale(locales []string, locale string) bool {
+	if locale == "" {
+		return false
+	}
+
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	for _, l := range locales {
+		if strings.EqualFold(l, locale) || strings.EqualFold(l, language) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andranikuz/botkit/config"
+	"github.com/andranikuz/botkit/core"
+)
+
+func flagContext(userID int64, roles ...string) core.UniversalContext {
+	ctx := core.NewBaseContext(context.Background())
+	ctx.SetUserID(userID)
+	ctx.SetRoles(roles)
+	ctx.SetSource("telegram")
+	ctx.SetLocale("ru-RU")
+	return ctx
+}
+
+func TestFlagEvaluate(t *testing.T) {
+	tests := []struct {
+		name string
+		flag Flag
+		ctx  core.UniversalContext
+		want bool
+	}{
+		{name: "disabled", flag: Flag{Name: "f"}, ctx: flagContext(1), want: false},
+		{name: "enabled for everyone", flag: Flag{Name: "f", Enabled: true}, ctx: flagContext(1), want: true},
+		{name: "zero percent", flag: Flag{Name: "f", Enabled: true, Percentage: Percent(0)}, ctx: flagContext(1), want: false},
+		{name: "full percent", flag: Flag{Name: "f", Enabled: true, Percentage: Percent(100)}, ctx: flagContext(1), want: true},
+		{name: "listed user with zero percent", flag: Flag{Name: "f", Enabled: true, Percentage: Percent(0), Users: []int64{1}}, ctx: flagContext(1), want: true},
+		{name: "role with zero percent", flag: Flag{Name: "f", Enabled: true, Percentage: Percent(0), Roles: []string{"tester"}}, ctx: flagContext(1, "Tester"), want: true},
+		{name: "role required", flag: Flag{Name: "f", Enabled: true, Roles: []string{"tester"}}, ctx: flagContext(1), want: false},
+		{name: "other user", flag: Flag{Name: "f", Enabled: true, Users: []int64{2}}, ctx: flagContext(1), want: false},
+		{name: "source matches", flag: Flag{Name: "f", Enabled: true, Sources: []string{"Telegram"}}, ctx: flagContext(1), want: true},
+		{name: "source differs", flag: Flag{Name: "f", Enabled: true, Sources: []string{"http"}}, ctx: flagContext(1), want: false},
+		{name: "language matches locale", flag: Flag{Name: "f", Enabled: true, Locales: []string{"ru"}}, ctx: flagContext(1), want: true},
+		{name: "locale differs", flag: Flag{Name: "f", Enabled: true, Locales: []string{"en"}}, ctx: flagContext(1), want: false},
+		{name: "listed user ignores source", flag: Flag{Name: "f", Enabled: true, Users: []int64{1}, Sources: []string{"http"}}, ctx: flagContext(1), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.Evaluate(tt.ctx); got != tt.want {
+				t.Fatalf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagRolloutShare(t *testing.T) {
+	tests := []struct {
+		percentage float64
+		min, max   int
+	}{
+		{percentage: 0, min: 0, max: 0},
+		{percentage: 10, min: 50, max: 150},
+		{percentage: 50, min: 400, max: 600},
+		{percentage: 100, min: 1000, max: 1000},
+	}
+
+	for _, tt := range tests {
+		flag := Flag{Name: "arena", Enabled: true, Percentage: Percent(tt.percentage)}
+
+		enabled := 0
+		for id := int64(1); id <= 1000; id++ {
+			if flag.Evaluate(flagContext(id)) {
+				enabled++
+			}
+		}
+		if enabled < tt.min || enabled > tt.max {
+			t.Errorf("percentage %g: %d of 1000 users enabled, want %d..%d", tt.percentage, enabled, tt.min, tt.max)
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	for id := int64(0); id < 1000; id++ {
+		bucket := Bucket("arena", id)
+		if bucket < 0 || bucket >= 100 {
+			t.Fatalf("Bucket(%d) = %g, want [0, 100)", id, bucket)
+		}
+		if bucket != Bucket("arena", id) {
+			t.Fatalf("Bucket(%d) is not stable", id)
+		}
+	}
+
+	if Bucket("arena", 42) == Bucket("tournament", 42) && Bucket("arena", 43) == Bucket("tournament", 43) {
+		t.Fatal("Bucket does not depend on the flag name")
+	}
+}
+
+func TestParsePercentage(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "0", want: 0},
+		{value: "10", want: 10},
+		{value: "2.5", want: 2.5},
+		{value: "2,5", want: 2.5},
+		{value: "5%", want: 5},
+		{value: "100", want: 100},
+		{value: "-1", wantErr: true},
+		{value: "101", wantErr: true},
+		{value: "NaN", wantErr: true},
+		{value: "half", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePercentage(tt.value)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("parsePercentage(%q) = %g, %v, want %g (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg := config.FromMap(map[string]interface{}{
+		"features": map[string]interface{}{
+			"everyone": map[string]interface{}{"enabled": true},
+			"nobody":   map[string]interface{}{"enabled": true, "percentage": 0},
+			"half":     map[string]interface{}{"enabled": true, "percentage": 50.5},
+		},
+	})
+
+	flags := New()
+	if err := flags.LoadConfig(cfg, DefaultConfigKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if flag, _ := flags.Get("everyone"); flag.Percentage != nil {
+		t.Errorf("everyone: percentage = %v, want unset", *flag.Percentage)
+	}
+	if flag, _ := flags.Get("nobody"); flag.Percentage == nil || *flag.Percentage != 0 {
+		t.Errorf("nobody: percentage = %v, want 0", flag.Percentage)
+	}
+	if flag, _ := flags.Get("half"); flag.Percentage == nil || *flag.Percentage != 50.5 {
+		t.Errorf("half: percentage = %v, want 50.5", flag.Percentage)
+	}
+	if flags.Enabled(flagContext(1), "nobody") {
+		t.Error("flag with 0% is enabled")
+	}
+
+	invalid := config.FromMap(map[string]interface{}{
+		"features": map[string]interface{}{
+			"broken": map[string]interface{}{"enabled": true, "percentage": 150},
+		},
+	})
+	if err := flags.LoadConfig(invalid, DefaultConfigKey); err == nil {
+		t.Error("percentage 150 accepted")
+	}
+}
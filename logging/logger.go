@@ -0,0 +1,291 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+var _ core.Logger = (*Logger)(nil)
+
+// Format формат вывода логов
+type Format string
+
+const (
+	// FormatJSON одна JSON-запись на строку (по умолчанию)
+	FormatJSON Format = "json"
+	// FormatText записи вида key=value
+	FormatText Format = "text"
+)
+
+// LevelFatal уровень Fatal (после записи процесс завершается)
+const LevelFatal = slog.Level(12)
+
+// Options настройки логгера
+type Options struct {
+	// Output куда писать записи (по умолчанию os.Stderr)
+	Output io.Writer
+
+	// Format формат записей (по умолчанию FormatJSON)
+	Format Format
+
+	// Level минимальный уровень (по умолчанию Info), можно изменить позже через SetLevel
+	Level slog.Level
+
+	// AddSource добавляет файл и строку вызова
+	AddSource bool
+
+	// Sampling ограничивает частые записи (nil - без ограничений)
+	Sampling *Sampling
+
+	// Exit завершает процесс после Fatal (по умолчанию os.Exit)
+	Exit func(code int)
+}
+
+// Logger реализация core.Logger поверх log/slog
+//
+// WithField, WithFields и WithError возвращают новый логгер с полями,
+// исходный логгер не изменяется. Производные логгеры разделяют уровень (SetLevel)
+type Logger struct {
+	handler slog.Handler
+	level   *slog.LevelVar
+	exit    func(code int)
+}
+
+// New создает логгер
+func New(opts Options) *Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	level := new(slog.LevelVar)
+	level.Set(opts.Level)
+
+	handlerOpts := &slog.HandlerOptions{
+		AddSource:   opts.AddSource,
+		Level:       level,
+		ReplaceAttr: replaceLevel,
+	}
+
+	var handler slog.Handler
+	if opts.Format == FormatText {
+		handler = slog.NewTextHandler(output, handlerOpts)
+	} else {
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	}
+
+	if opts.Sampling != nil {
+		handler = newSamplingHandler(handler, *opts.Sampling)
+	}
+
+	exit := opts.Exit
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	return &Logger{
+		handler: handler,
+		level:   level,
+		exit:    exit,
+	}
+}
+
+// NewFromConfig создает логгер по секции конфигурации key:
+//
+//	log:
+//	  level: debug        # debug, info, warn, error
+//	  format: text        # json, text
+//	  add_source: false
+//	  sampling:
+//	    level: debug      # сэмплируются записи этого уровня и ниже
+//	    first: 10
+//	    thereafter: 100
+//	    tick: 1s
+func NewFromConfig(cfg core.Config, key string, output io.Writer) (*Logger, error) {
+	opts := Options{Output: output}
+
+	level, err := ParseLevel(cfg.GetString(key + ".level"))
+	if err != nil {
+		return nil, err
+	}
+	opts.Level = level
+
+	format, err := ParseFormat(cfg.GetString(key + ".format"))
+	if err != nil {
+		return nil, err
+	}
+	opts.Format = format
+	opts.AddSource = cfg.GetBool(key + ".add_source")
+
+	if sectioned, ok := cfg.(core.SectionedConfig); ok && cfg.Get(key+".sampling") != nil {
+		var sampling samplingConfig
+		if err := sectioned.Bind(key+".sampling", &sampling); err != nil {
+			return nil, err
+		}
+
+		samplingLevel, err := ParseLevel(sampling.Level)
+		if err != nil {
+			return nil, err
+		}
+		opts.Sampling = &Sampling{
+			Level:      samplingLevel,
+			First:      sampling.First,
+			Thereafter: sampling.Thereafter,
+			Tick:       sampling.Tick,
+		}
+	}
+
+	return New(opts), nil
+}
+
+// samplingConfig секция sampling конфигурации логгера
+type samplingConfig struct {
+	Level      string        `default:"debug"`
+	First      int           `default:"10" validate:"min=0"`
+	Thereafter int           `default:"100" validate:"min=0"`
+	Tick       time.Duration `default:"1s"`
+}
+
+// ParseLevel разбирает уровень: debug, info, warn, error, fatal (пустая строка - info)
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// ParseFormat разбирает формат: json или text (пустая строка - json)
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatText:
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("unknown log format %q", s)
+}
+
+// SetLevel изменяет минимальный уровень во время работы
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// Level возвращает текущий минимальный уровень
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// Slog возвращает *slog.Logger с теми же полями и выводом
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(l.handler)
+}
+
+// Debug пишет отладочную запись
+func (l *Logger) Debug(msg string, fields ...interface{}) {
+	l.log(slog.LevelDebug, msg, fields)
+}
+
+// Info пишет информационную запись
+func (l *Logger) Info(msg string, fields ...interface{}) {
+	l.log(slog.LevelInfo, msg, fields)
+}
+
+// Warn пишет предупреждение
+func (l *Logger) Warn(msg string, fields ...interface{}) {
+	l.log(slog.LevelWarn, msg, fields)
+}
+
+// Error пишет ошибку
+func (l *Logger) Error(msg string, fields ...interface{}) {
+	l.log(slog.LevelError, msg, fields)
+}
+
+// Fatal пишет запись и завершает процесс
+func (l *Logger) Fatal(msg string, fields ...interface{}) {
+	l.log(LevelFatal, msg, fields)
+	l.exit(1)
+}
+
+// WithField возвращает логгер с полем
+func (l *Logger) WithField(key string, value interface{}) core.Logger {
+	return l.with(key, value)
+}
+
+// WithFields возвращает логгер с полями (в порядке ключей)
+func (l *Logger) WithFields(fields map[string]interface{}) core.Logger {
+	if len(fields) == 0 {
+		return l
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]interface{}, 0, len(fields)*2)
+	for _, key := range keys {
+		args = append(args, key, fields[key])
+	}
+	return l.with(args...)
+}
+
+// WithError возвращает логгер с полем error
+func (l *Logger) WithError(err error) core.Logger {
+	if err == nil {
+		return l
+	}
+	return l.with("error", err)
+}
+
+// with возвращает логгер с дополнительными атрибутами
+func (l *Logger) with(args ...interface{}) *Logger {
+	return &Logger{
+		handler: slog.New(l.handler).With(args...).Handler(),
+		level:   l.level,
+		exit:    l.exit,
+	}
+}
+
+// log формирует запись с местом вызова (минуя обертки логгера)
+func (l *Logger) log(level slog.Level, msg string, fields []interface{}) {
+	ctx := context.Background()
+	if !l.handler.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // runtime.Callers, log, Debug/Info/...
+
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add(fields...)
+	_ = l.handler.Handle(ctx, record)
+}
+
+// replaceLevel выводит LevelFatal как "FATAL"
+func replaceLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level >= LevelFatal {
+			attr.Value = slog.StringValue("FATAL")
+		}
+	}
+	return attr
+}
@@ -0,0 +1,209 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// records разбирает JSON-записи, по одной на строку
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+func TestLoggerSource(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Options{Output: &buf, AddSource: true})
+
+	logger.Info("started")
+	logger.WithField("module", "arena").Warn("slow")
+
+	for _, record := range records(t, &buf) {
+		source, _ := record["source"].(map[string]interface{})
+		file, _ := source["file"].(string)
+		function, _ := source["function"].(string)
+		if filepath.Base(file) != "logger_test.go" || !strings.HasSuffix(function, "TestLoggerSource") {
+			t.Fatalf("record %q source = %v, want caller in logger_test.go", record["msg"], source)
+		}
+	}
+}
+
+func TestLoggerFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Options{Output: &buf})
+
+	request := logger.WithFields(map[string]interface{}{"user_id": 42, "chat_id": 7})
+	request.WithField("module", "arena").Info("matched", "route", "/duel")
+	request.Info("done")
+	logger.Info("plain")
+
+	tests := []struct {
+		msg    string
+		fields map[string]interface{}
+		absent []string
+	}{
+		{
+			msg:    "matched",
+			fields: map[string]interface{}{"user_id": 42.0, "chat_id": 7.0, "module": "arena", "route": "/duel"},
+		},
+		{
+			msg:    "done",
+			fields: map[string]interface{}{"user_id": 42.0, "chat_id": 7.0},
+			absent: []string{"module", "route"},
+		},
+		{
+			msg:    "plain",
+			absent: []string{"user_id", "chat_id", "module"},
+		},
+	}
+
+	got := records(t, &buf)
+	if len(got) != len(tests) {
+		t.Fatalf("got %d records, want %d", len(got), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			record := got[i]
+			if record["msg"] != tt.msg {
+				t.Fatalf("msg = %v, want %q", record["msg"], tt.msg)
+			}
+			for key, want := range tt.fields {
+				if record[key] != want {
+					t.Fatalf("%s = %v, want %v", key, record[key], want)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := record[key]; ok {
+					t.Fatalf("unexpected field %s = %v", key, record[key])
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerFatal(t *testing.T) {
+	var buf bytes.Buffer
+	code := -1
+	logger := New(Options{Output: &buf, Exit: func(c int) { code = c }})
+
+	logger.Fatal("config is broken")
+
+	got := records(t, &buf)
+	if len(got) != 1 || got[0]["level"] != "FATAL" {
+		t.Fatalf("records = %v, want one FATAL record", got)
+	}
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+}
+
+func TestLoggerSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Options{
+		Output:   &buf,
+		Level:    slog.LevelDebug,
+		Sampling: &Sampling{Level: slog.LevelDebug, First: 2, Thereafter: 3, Tick: time.Hour},
+	})
+
+	for i := 0; i < 10; i++ {
+		logger.Debug("poll")
+		logger.WithField("attempt", i).Debug("poll")
+		logger.Info("tick")
+	}
+
+	counts := make(map[string]int)
+	for _, record := range records(t, &buf) {
+		counts[record["msg"].(string)]++
+	}
+
+	// 20 одинаковых отладочных записей (производные логгеры сэмплируются вместе): 1, 2, 5, 8, ...
+	if counts["poll"] != 8 {
+		t.Fatalf("poll records = %d, want 8", counts["poll"])
+	}
+	if counts["tick"] != 10 {
+		t.Fatalf("tick records = %d, want 10 (Info is above the sampling level)", counts["tick"])
+	}
+}
+
+func TestSamplerAllow(t *testing.T) {
+	start := time.Unix(0, 0)
+
+	tests := []struct {
+		name     string
+		sampling Sampling
+		at       []time.Duration
+		want     []bool
+	}{
+		{
+			name:     "first then every third",
+			sampling: Sampling{First: 2, Thereafter: 3, Tick: time.Second},
+			at:       []time.Duration{0, 0, 0, 0, 0, 0},
+			want:     []bool{true, true, false, false, true, false},
+		},
+		{
+			name:     "drop after first",
+			sampling: Sampling{First: 1, Tick: time.Second},
+			at:       []time.Duration{0, 0, 0},
+			want:     []bool{true, false, false},
+		},
+		{
+			name:     "new tick resets counters",
+			sampling: Sampling{First: 1, Tick: time.Second},
+			at:       []time.Duration{0, 500 * time.Millisecond, time.Second, time.Second},
+			want:     []bool{true, false, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSamplingHandler(nil, tt.sampling).sampler
+			for i, offset := range tt.at {
+				if got := s.allow(slog.LevelDebug, "poll", start.Add(offset)); got != tt.want[i] {
+					t.Fatalf("record %d: allow = %v, want %v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  slog.Level
+		err   bool
+	}{
+		{input: "", want: slog.LevelInfo},
+		{input: "DEBUG", want: slog.LevelDebug},
+		{input: "warning", want: slog.LevelWarn},
+		{input: " error ", want: slog.LevelError},
+		{input: "fatal", want: LevelFatal},
+		{input: "verbose", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLevel(tt.input)
+			if (err != nil) != tt.err || got != tt.want {
+				t.Fatalf("ParseLevel(%q) = %v, %v, want %v, error %v", tt.input, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
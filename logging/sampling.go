@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sampling ограничивает частые записи низких уровней
+//
+// В каждом интервале Tick записи с одинаковым сообщением и уровнем не выше Level
+// пишутся первые First раз, затем каждая Thereafter-я (0 - остальные отбрасываются).
+// Записи выше Level пишутся всегда
+type Sampling struct {
+	// Level сэмплируются записи этого уровня и ниже (обычно slog.LevelDebug)
+	Level slog.Level
+
+	// First сколько одинаковых записей писать в интервале без ограничений
+	First int
+
+	// Thereafter каждая какая запись пишется после First
+	Thereafter int
+
+	// Tick интервал подсчета (по умолчанию секунда)
+	Tick time.Duration
+}
+
+// samplingHandler обертка slog.Handler с сэмплированием
+type samplingHandler struct {
+	next    slog.Handler
+	sampler *sampler
+}
+
+func newSamplingHandler(next slog.Handler, sampling Sampling) *samplingHandler {
+	if sampling.Tick <= 0 {
+		sampling.Tick = time.Second
+	}
+
+	return &samplingHandler{
+		next: next,
+		sampler: &sampler{
+			config: sampling,
+			counts: make(map[sampleKey]int),
+		},
+	}
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level <= h.sampler.config.Level && !h.sampler.allow(record.Level, record.Message, record.Time) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs и WithGroup сохраняют общий счетчик: производные логгеры
+// (например, логгеры запросов) сэмплируются вместе
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{next: h.next.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{next: h.next.WithGroup(name), sampler: h.sampler}
+}
+
+// sampleKey одинаковые записи
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+// sampler счетчики записей текущего интервала
+type sampler struct {
+	config Sampling
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[sampleKey]int
+}
+
+// allow решает, писать ли запись
+func (s *sampler) allow(level slog.Level, message string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.start) >= s.config.Tick {
+		s.start = now
+		s.counts = make(map[sampleKey]int, len(s.counts))
+	}
+
+	key := sampleKey{level: level, message: message}
+	s.counts[key]++
+	n := s.counts[key]
+
+	if n <= s.config.First {
+		return true
+	}
+	return s.config.Thereafter > 0 && (n-s.config.First)%s.config.Thereafter == 0
+}
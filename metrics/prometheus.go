@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType тип содержимого текстового формата Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ServeHTTP отдает метрики в текстовом формате Prometheus (endpoint /metrics)
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = r.WritePrometheus(w)
+}
+
+// WritePrometheus пишет метрики в текстовом формате Prometheus
+// Метрики и наборы меток упорядочены по имени. Значения копируются под блокировкой,
+// а пишутся без нее: медленный клиент не задерживает запись метрик
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	families, help := r.snapshot()
+
+	out := bufio.NewWriter(w)
+	for _, f := range families {
+		if text, ok := help[f.name]; ok {
+			out.WriteString("# HELP " + f.name + " " + escapeHelp(text) + "\n")
+		}
+		out.WriteString("# TYPE " + f.name + " " + string(f.kind) + "\n")
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := f.series[key]
+			if f.kind != kindHistogram {
+				writeSample(out, f.name, s.labels, "", "", s.value)
+				continue
+			}
+
+			for i, bound := range f.buckets {
+				writeSample(out, f.name+"_bucket", s.labels, "le", formatFloat(bound), float64(s.counts[i]))
+			}
+			writeSample(out, f.name+"_bucket", s.labels, "le", "+Inf", float64(s.count))
+			writeSample(out, f.name+"_sum", s.labels, "", "", s.sum)
+			writeSample(out, f.name+"_count", s.labels, "", "", float64(s.count))
+		}
+	}
+
+	return out.Flush()
+}
+
+// snapshot возвращает копии метрик, отсортированные по имени, и их описания
+func (r *Registry) snapshot() ([]*family, map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		copied := &family{
+			name:    f.name,
+			kind:    f.kind,
+			buckets: f.buckets,
+			series:  make(map[string]*series, len(f.series)),
+		}
+		for key, s := range f.series {
+			c := *s
+			c.counts = append([]uint64(nil), s.counts...)
+			copied.series[key] = &c
+		}
+		families = append(families, copied)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	help := make(map[string]string, len(r.help))
+	for name, text := range r.help {
+		help[name] = text
+	}
+
+	return families, help
+}
+
+// writeSample пишет строку значения: name{labels} value
+func writeSample(out *bufio.Writer, name string, labels []label, extraName, extraValue string, value float64) {
+	out.WriteString(name)
+
+	if len(labels) > 0 || extraName != "" {
+		out.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(l.name + `="` + escapeLabel(l.value) + `"`)
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(extraName + `="` + extraValue + `"`)
+		}
+		out.WriteByte('}')
+	}
+
+	out.WriteByte(' ')
+	out.WriteString(formatFloat(value))
+	out.WriteByte('\n')
+}
+
+// formatFloat форматирует число для Prometheus
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
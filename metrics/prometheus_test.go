@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingWriter io.Writer, который не возвращается до release
+type blockingWriter struct {
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	close(w.writing)
+	<-w.release
+	return len(p), nil
+}
+
+func TestWritePrometheus(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		record func(r *Registry)
+		want   string
+	}{
+		{
+			name: "counter with labels",
+			opts: Options{Namespace: "botkit"},
+			record: func(r *Registry) {
+				r.Describe("events.published", "Published events")
+				r.Counter("events.published", 2, "type", "command.executed")
+				r.Counter("events.published", 3, "type", "command.executed")
+				r.Counter("events.published", 1, "type", "user.action")
+			},
+			want: `# HELP botkit_events_published_total Published events
+# TYPE botkit_events_published_total counter
+botkit_events_published_total{type="command.executed"} 5
+botkit_events_published_total{type="user.action"} 1
+`,
+		},
+		{
+			name: "gauge keeps last value, labels sorted",
+			record: func(r *Registry) {
+				r.Gauge("modules.active", 3, "state", "running", "adapter", "telegram")
+				r.Gauge("modules.active", 4, "state", "running", "adapter", "telegram")
+			},
+			want: `# TYPE modules_active gauge
+modules_active{adapter="telegram",state="running"} 4
+`,
+		},
+		{
+			name: "histogram buckets are cumulative",
+			record: func(r *Registry) {
+				r.SetBuckets("payload", 10, 1, 10)
+				r.Histogram("payload", 0.5)
+				r.Histogram("payload", 5)
+				r.Histogram("payload", 50)
+			},
+			want: `# TYPE payload histogram
+payload_bucket{le="1"} 1
+payload_bucket{le="10"} 2
+payload_bucket{le="+Inf"} 3
+payload_sum 55.5
+payload_count 3
+`,
+		},
+		{
+			name: "timing in seconds",
+			opts: Options{Buckets: []float64{0.1, 1}},
+			record: func(r *Registry) {
+				r.Timing("command.duration", 250, "module", "arena")
+			},
+			want: `# TYPE command_duration_seconds histogram
+command_duration_seconds_bucket{module="arena",le="0.1"} 0
+command_duration_seconds_bucket{module="arena",le="1"} 1
+command_duration_seconds_bucket{module="arena",le="+Inf"} 1
+command_duration_seconds_sum{module="arena"} 0.25
+command_duration_seconds_count{module="arena"} 1
+`,
+		},
+		{
+			name: "escaping and sanitizing",
+			record: func(r *Registry) {
+				r.Describe("errors", "Errors by\nreason \\ type")
+				r.Counter("errors", 1, "error-type", "bad \"input\"\n")
+			},
+			want: `# HELP errors_total Errors by\nreason \\ type
+# TYPE errors_total counter
+errors_total{error_type="bad \"input\"\n"} 1
+`,
+		},
+		{
+			name: "type conflict is ignored",
+			record: func(r *Registry) {
+				r.Gauge("queue", 5)
+				r.Histogram("queue", 1)
+			},
+			want: `# TYPE queue gauge
+queue 5
+`,
+		},
+		{
+			name: "odd tag is dropped",
+			record: func(r *Registry) {
+				r.Counter("requests_total", 1, "adapter")
+			},
+			want: `# TYPE requests_total counter
+requests_total 1
+`,
+		},
+		{
+			name: "families sorted by name",
+			record: func(r *Registry) {
+				r.Gauge("b", 2)
+				r.Gauge("a", 1)
+			},
+			want: `# TYPE a gauge
+a 1
+# TYPE b gauge
+b 2
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.opts)
+			tt.record(r)
+
+			var out strings.Builder
+			if err := r.WritePrometheus(&out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Fatalf("output:\n%s\nwant:\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := New(Options{})
+	r.Counter("updates", 1)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatalf("Content-Type = %q, want %q", ct, ContentType)
+	}
+	if !strings.Contains(rec.Body.String(), "updates_total 1\n") {
+		t.Fatalf("body = %q", rec.Body.String())
+	}
+}
+
+func TestWritePrometheusDoesNotBlockRecording(t *testing.T) {
+	r := New(Options{})
+	r.Counter("updates", 1)
+
+	w := &blockingWriter{writing: make(chan struct{}), release: make(chan struct{})}
+	written := make(chan error, 1)
+	go func() { written <- r.WritePrometheus(w) }()
+	<-w.writing
+
+	recorded := make(chan struct{})
+	go func() {
+		r.Counter("updates", 1)
+		r.Timing("command.duration", 10)
+		close(recorded)
+	}()
+
+	select {
+	case <-recorded:
+	case <-time.After(time.Second):
+		t.Fatal("recording metrics is blocked by a stalled scrape")
+	}
+
+	close(w.release)
+	if err := <-written; err != nil {
+		t.Fatal(err)
+	}
+}
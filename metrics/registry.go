@@ -0,0 +1,306 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+var _ core.Metrics = (*Registry)(nil)
+
+// DefaultBuckets границы гистограмм по умолчанию (секунды, как в клиентах Prometheus)
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Options настройки реестра
+type Options struct {
+	// Namespace префикс имен метрик (например, "botkit" - botkit_requests_total)
+	Namespace string
+
+	// Buckets границы гистограмм по умолчанию (nil - DefaultBuckets)
+	Buckets []float64
+}
+
+// Registry реестр метрик в памяти процесса (реализует core.Metrics)
+//
+// Имена переводятся в формат Prometheus: точки и другие недопустимые символы
+// заменяются на "_" ("events.published" - events_published_total).
+// Пары тегов ("type", "command") становятся метками {type="command"}.
+//
+//   - Counter - счетчик с суффиксом _total
+//   - Gauge - текущее значение
+//   - Histogram - гистограмма значений в единицах вызывающего кода
+//   - Timing - гистограмма длительностей: миллисекунды переводятся в секунды,
+//     к имени добавляется суффикс _seconds
+//
+// Значения отдаются в текстовом формате Prometheus через ServeHTTP или WritePrometheus
+type Registry struct {
+	namespace string
+	buckets   []float64
+
+	mu       sync.Mutex
+	families map[string]*family
+	custom   map[string][]float64
+	help     map[string]string
+}
+
+// kind тип метрики
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// family метрика со всеми наборами меток
+type family struct {
+	name    string
+	kind    kind
+	buckets []float64
+	series  map[string]*series
+}
+
+// series значения метрики для одного набора меток
+type series struct {
+	labels []label
+
+	// value значение счетчика или gauge
+	value float64
+
+	// counts, sum, count значения гистограммы (counts по границам buckets)
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// label метка Prometheus
+type label struct {
+	name  string
+	value string
+}
+
+// New создает реестр метрик
+func New(opts Options) *Registry {
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	return &Registry{
+		namespace: sanitizeName(opts.Namespace),
+		buckets:   normalizeBuckets(buckets),
+		families:  make(map[string]*family),
+		custom:    make(map[string][]float64),
+		help:      make(map[string]string),
+	}
+}
+
+// SetBuckets задает границы гистограммы name (имя, переданное в Histogram или Timing)
+// Уже собранные значения этой метрики сбрасываются
+func (r *Registry) SetBuckets(name string, buckets ...float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.custom[name] = normalizeBuckets(buckets)
+	delete(r.families, r.histogramName(name))
+	delete(r.families, r.timingName(name))
+}
+
+// Describe задает описание метрики (строка # HELP)
+func (r *Registry) Describe(name, help string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.help[r.counterName(name)] = help
+	r.help[r.fullName(name)] = help
+	r.help[r.timingName(name)] = help
+}
+
+// Counter увеличивает счетчик
+func (r *Registry) Counter(name string, value int64, tags ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s := r.series(r.counterName(name), kindCounter, nil, tags); s != nil {
+		s.value += float64(value)
+	}
+}
+
+// Gauge устанавливает значение
+func (r *Registry) Gauge(name string, value float64, tags ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s := r.series(r.fullName(name), kindGauge, nil, tags); s != nil {
+		s.value = value
+	}
+}
+
+// Histogram записывает значение в гистограмму
+func (r *Registry) Histogram(name string, value float64, tags ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.observe(r.histogramName(name), r.bucketsOf(name), value, tags)
+}
+
+// Timing записывает длительность в миллисекундах
+func (r *Registry) Timing(name string, duration int64, tags ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.observe(r.timingName(name), r.bucketsOf(name), float64(duration)/1000, tags)
+}
+
+// observe записывает значение гистограммы
+func (r *Registry) observe(name string, buckets []float64, value float64, tags []string) {
+	s := r.series(name, kindHistogram, buckets, tags)
+	if s == nil {
+		return
+	}
+
+	for i, bound := range buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// series возвращает значения метрики для набора меток
+// Метрика, уже зарегистрированная с другим типом, не изменяется (nil)
+func (r *Registry) series(name string, k kind, buckets []float64, tags []string) *series {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{
+			name:    name,
+			kind:    k,
+			buckets: buckets,
+			series:  make(map[string]*series),
+		}
+		r.families[name] = f
+	}
+	if f.kind != k {
+		return nil
+	}
+
+	labels := labelsOf(tags)
+	key := labelsKey(labels)
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: labels}
+		if k == kindHistogram {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// bucketsOf возвращает границы гистограммы метрики
+func (r *Registry) bucketsOf(name string) []float64 {
+	if buckets, ok := r.custom[name]; ok {
+		return buckets
+	}
+	return r.buckets
+}
+
+// fullName имя метрики Prometheus с префиксом
+func (r *Registry) fullName(name string) string {
+	name = sanitizeName(name)
+	if r.namespace != "" {
+		return r.namespace + "_" + name
+	}
+	return name
+}
+
+func (r *Registry) counterName(name string) string {
+	full := r.fullName(name)
+	if strings.HasSuffix(full, "_total") {
+		return full
+	}
+	return full + "_total"
+}
+
+func (r *Registry) histogramName(name string) string {
+	return r.fullName(name)
+}
+
+func (r *Registry) timingName(name string) string {
+	full := r.fullName(name)
+	if strings.HasSuffix(full, "_seconds") {
+		return full
+	}
+	return full + "_seconds"
+}
+
+// labelsOf переводит пары тегов в метки, отсортированные по имени
+// Тег без значения (нечетное число тегов) отбрасывается
+func labelsOf(tags []string) []label {
+	if len(tags) < 2 {
+		return nil
+	}
+
+	labels := make([]label, 0, len(tags)/2)
+	for i := 0; i+1 < len(tags); i += 2 {
+		labels = append(labels, label{name: sanitizeLabel(tags[i]), value: tags[i+1]})
+	}
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return labels
+}
+
+// labelsKey ключ набора меток
+func labelsKey(labels []label) string {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.name)
+		b.WriteByte(0)
+		b.WriteString(l.value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// normalizeBuckets сортирует границы и убирает повторы
+func normalizeBuckets(buckets []float64) []float64 {
+	result := append([]float64(nil), buckets...)
+	sort.Float64s(result)
+
+	unique := result[:0]
+	for i, bound := range result {
+		if i == 0 || bound != result[i-1] {
+			unique = append(unique, bound)
+		}
+	}
+	return unique
+}
+
+// sanitizeName приводит имя к [a-zA-Z_:][a-zA-Z0-9_:]*
+func sanitizeName(name string) string {
+	return sanitize(name, true)
+}
+
+// sanitizeLabel приводит имя метки к [a-zA-Z_][a-zA-Z0-9_]*
+func sanitizeLabel(name string) string {
+	return sanitize(name, false)
+}
+
+func sanitize(name string, colon bool) string {
+	if name == "" {
+		return ""
+	}
+
+	b := []byte(name)
+	for i, c := range b {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(i > 0 && c >= '0' && c <= '9') || (colon && c == ':')
+		if !valid {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
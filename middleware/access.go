@@ -0,0 +1,453 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/routing"
+)
+
+var _ routing.Middleware = (*AccessMiddleware)(nil)
+
+// Области записей доступа
+const (
+	AccessScopeUser = "user"
+	AccessScopeChat = "chat"
+)
+
+// Причины отказа
+const (
+	AccessDeniedUserBanned  = "user_banned"
+	AccessDeniedChatBanned  = "chat_banned"
+	AccessDeniedNotAllowed  = "not_allowed"
+	AccessDeniedMaintenance = "maintenance"
+)
+
+// accessPrefix префикс ключей в хранилище
+const accessPrefix = "access:"
+
+// accessCleanupInterval интервал удаления истекших банов
+const accessCleanupInterval = 10 * time.Minute
+
+// DefaultMaintenanceMessage текст режима обслуживания
+const DefaultMaintenanceMessage = "🛠 Бот на техническом обслуживании. Попробуйте позже."
+
+// AccessEntry запись бан/allow списка
+type AccessEntry struct {
+	Scope     string    `json:"scope"` // user, chat
+	ID        int64     `json:"id"`
+	Reason    string    `json:"reason,omitempty"`
+	Until     time.Time `json:"until,omitempty"` // нулевое значение - бессрочно
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active проверяет, действует ли запись
+func (e AccessEntry) Active(now time.Time) bool {
+	return e.Until.IsZero() || now.Before(e.Until)
+}
+
+// AccessDenial информация об отказе для ответа
+type AccessDenial struct {
+	// Reason причина (user_banned, chat_banned, not_allowed, maintenance)
+	Reason string
+
+	// Entry запись бана (для user_banned, chat_banned)
+	Entry *AccessEntry
+
+	// Message текст режима обслуживания
+	Message string
+}
+
+// BlockedReplyFunc формирует ответ заблокированному пользователю
+type BlockedReplyFunc func(ctx core.UniversalContext, denial AccessDenial) core.Response
+
+// MaintenanceState состояние режима обслуживания
+type MaintenanceState struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+}
+
+// AccessMiddleware бан/allow списки пользователей и чатов и режим обслуживания
+type AccessMiddleware struct {
+	storage  core.Storage
+	priority int
+
+	mu            sync.RWMutex
+	banned        map[string]AccessEntry
+	allowed       map[string]AccessEntry
+	allowListOnly bool
+	maintenance   MaintenanceState
+	lastCleanup   time.Time
+
+	adminRoles   []string
+	adminCheck   func(ctx core.UniversalContext) bool
+	blockedReply BlockedReplyFunc
+}
+
+// NewAccessMiddleware создает middleware контроля доступа
+// storage хранит списки и режим обслуживания между перезапусками (опционально)
+func NewAccessMiddleware(storage core.Storage, priority int) *AccessMiddleware {
+	return &AccessMiddleware{
+		storage:      storage,
+		priority:     priority,
+		banned:       make(map[string]AccessEntry),
+		allowed:      make(map[string]AccessEntry),
+		adminRoles:   []string{"admin"},
+		blockedReply: defaultBlockedReply,
+	}
+}
+
+// Load загружает списки и режим обслуживания из хранилища
+func (m *AccessMiddleware) Load(ctx context.Context) error {
+	if m.storage == nil {
+		return nil
+	}
+
+	keys, err := m.storage.List(ctx, accessPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list access entries: %w", err)
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, accessPrefix)
+
+		if name == "maintenance" {
+			var state MaintenanceState
+			if err := m.storage.Load(ctx, key, &state); err != nil {
+				return fmt.Errorf("failed to load maintenance state: %w", err)
+			}
+			m.maintenance = state
+			continue
+		}
+
+		var entry AccessEntry
+		if err := m.storage.Load(ctx, key, &entry); err != nil {
+			return fmt.Errorf("failed to load access entry %s: %w", key, err)
+		}
+		if !entry.Active(now) {
+			// Истекший бан больше не нужен ни в памяти, ни в хранилище
+			_ = m.storage.Delete(ctx, key)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(name, "ban:"):
+			m.banned[strings.TrimPrefix(name, "ban:")] = entry
+		case strings.HasPrefix(name, "allow:"):
+			m.allowed[strings.TrimPrefix(name, "allow:")] = entry
+		}
+	}
+
+	return nil
+}
+
+// SetAdminRoles устанавливает роли, обслуживаемые в режиме обслуживания
+func (m *AccessMiddleware) SetAdminRoles(roles ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.adminRoles = roles
+}
+
+// SetAdminCheck устанавливает кастомную проверку администратора
+func (m *AccessMiddleware) SetAdminCheck(check func(ctx core.UniversalContext) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.adminCheck = check
+}
+
+// SetBlockedReply устанавливает ответ заблокированным пользователям
+func (m *AccessMiddleware) SetBlockedReply(reply BlockedReplyFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blockedReply = reply
+}
+
+// SetAllowListOnly включает режим белого списка (обслуживаются только разрешенные)
+func (m *AccessMiddleware) SetAllowListOnly(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.allowListOnly = enabled
+}
+
+// SetMaintenance включает или выключает режим обслуживания
+// Пустое сообщение заменяется DefaultMaintenanceMessage
+func (m *AccessMiddleware) SetMaintenance(ctx context.Context, enabled bool, message string) error {
+	state := MaintenanceState{Enabled: enabled, Message: message}
+
+	if m.storage != nil {
+		if err := m.storage.Save(ctx, accessPrefix+"maintenance", state); err != nil {
+			return fmt.Errorf("failed to save maintenance state: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	m.maintenance = state
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Maintenance возвращает состояние режима обслуживания
+func (m *AccessMiddleware) Maintenance() MaintenanceState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.maintenance
+}
+
+// Ban блокирует пользователя или чат (duration 0 - бессрочно)
+func (m *AccessMiddleware) Ban(ctx context.Context, scope string, id int64, duration time.Duration, reason string) error {
+	entry := AccessEntry{
+		Scope:     scope,
+		ID:        id,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if duration > 0 {
+		entry.Until = entry.CreatedAt.Add(duration)
+	}
+
+	return m.put(ctx, m.banned, "ban:", entry)
+}
+
+// Unban снимает блокировку
+func (m *AccessMiddleware) Unban(ctx context.Context, scope string, id int64) error {
+	return m.remove(ctx, m.banned, "ban:", scope, id)
+}
+
+// Allow добавляет пользователя или чат в белый список
+func (m *AccessMiddleware) Allow(ctx context.Context, scope string, id int64) error {
+	entry := AccessEntry{
+		Scope:     scope,
+		ID:        id,
+		CreatedAt: time.Now(),
+	}
+
+	return m.put(ctx, m.allowed, "allow:", entry)
+}
+
+// Disallow удаляет пользователя или чат из белого списка
+func (m *AccessMiddleware) Disallow(ctx context.Context, scope string, id int64) error {
+	return m.remove(ctx, m.allowed, "allow:", scope, id)
+}
+
+// Bans возвращает активные блокировки
+func (m *AccessMiddleware) Bans() []AccessEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	result := make([]AccessEntry, 0, len(m.banned))
+	for _, entry := range m.banned {
+		if entry.Active(now) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// IsBanned проверяет блокировку
+func (m *AccessMiddleware) IsBanned(scope string, id int64) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.banned[accessKey(scope, id)]
+	return ok && entry.Active(time.Now())
+}
+
+// Name возвращает имя
+func (m *AccessMiddleware) Name() string {
+	return "access"
+}
+
+// Priority возвращает приоритет
+func (m *AccessMiddleware) Priority() int {
+	return m.priority
+}
+
+// Process проверяет доступ
+func (m *AccessMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
+	denial, ok, cleanup := m.check(ctx)
+	if cleanup {
+		m.cleanup(requestContext(ctx), time.Now())
+	}
+	if ok {
+		return next(ctx)
+	}
+
+	m.mu.RLock()
+	reply := m.blockedReply
+	m.mu.RUnlock()
+
+	return reply(ctx, denial)
+}
+
+// check возвращает отказ, если запрос не должен обслуживаться
+// cleanup=true, если встретился истекший бан или пора удалить истекшие баны
+func (m *AccessMiddleware) check(ctx core.UniversalContext) (denial AccessDenial, ok bool, cleanup bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	userKey := accessKey(AccessScopeUser, ctx.GetUserID())
+	chatKey := accessKey(AccessScopeChat, ctx.GetChatID())
+	cleanup = now.Sub(m.lastCleanup) >= accessCleanupInterval
+
+	if entry, found := m.banned[userKey]; found {
+		if entry.Active(now) {
+			return AccessDenial{Reason: AccessDeniedUserBanned, Entry: &entry}, false, cleanup
+		}
+		cleanup = true
+	}
+	if entry, found := m.banned[chatKey]; found && ctx.GetChatID() != 0 {
+		if entry.Active(now) {
+			return AccessDenial{Reason: AccessDeniedChatBanned, Entry: &entry}, false, cleanup
+		}
+		cleanup = true
+	}
+
+	_, userAllowed := m.allowed[userKey]
+	_, chatAllowed := m.allowed[chatKey]
+	allowed := userAllowed || (chatAllowed && ctx.GetChatID() != 0)
+	admin := m.isAdmin(ctx)
+
+	if m.maintenance.Enabled && !admin && !allowed {
+		message := m.maintenance.Message
+		if message == "" {
+			message = DefaultMaintenanceMessage
+		}
+		return AccessDenial{Reason: AccessDeniedMaintenance, Message: message}, false, cleanup
+	}
+
+	if m.allowListOnly && !admin && !allowed {
+		return AccessDenial{Reason: AccessDeniedNotAllowed}, false, cleanup
+	}
+
+	return AccessDenial{}, true, cleanup
+}
+
+// cleanup удаляет истекшие баны из памяти и хранилища
+func (m *AccessMiddleware) cleanup(ctx context.Context, now time.Time) {
+	var expired []string
+
+	m.mu.Lock()
+	m.lastCleanup = now
+	for key, entry := range m.banned {
+		if !entry.Active(now) {
+			delete(m.banned, key)
+			expired = append(expired, key)
+		}
+	}
+	m.mu.Unlock()
+
+	// Хранилище вызывается без блокировки, чтобы не задерживать остальные запросы
+	if m.storage != nil {
+		for _, key := range expired {
+			_ = m.storage.Delete(ctx, accessPrefix+"ban:"+key)
+		}
+	}
+}
+
+// isAdmin проверяет администратора (вызывается под mu)
+func (m *AccessMiddleware) isAdmin(ctx core.UniversalContext) bool {
+	if m.adminCheck != nil {
+		return m.adminCheck(ctx)
+	}
+
+	for _, role := range ctx.GetRoles() {
+		for _, adminRole := range m.adminRoles {
+			if role == adminRole {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// put сохраняет запись в список и хранилище
+func (m *AccessMiddleware) put(ctx context.Context, list map[string]AccessEntry, prefix string, entry AccessEntry) error {
+	if entry.Scope != AccessScopeUser && entry.Scope != AccessScopeChat {
+		return fmt.Errorf("unknown access scope: %s", entry.Scope)
+	}
+
+	key := accessKey(entry.Scope, entry.ID)
+
+	// Сначала хранилище: при ошибке запись не начинает действовать
+	if m.storage != nil {
+		if err := m.storage.Save(ctx, accessPrefix+prefix+key, entry); err != nil {
+			return fmt.Errorf("failed to save access entry: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	list[key] = entry
+	m.mu.Unlock()
+
+	return nil
+}
+
+// remove удаляет запись из списка и хранилища
+func (m *AccessMiddleware) remove(ctx context.Context, list map[string]AccessEntry, prefix, scope string, id int64) error {
+	key := accessKey(scope, id)
+
+	// Сначала хранилище: при ошибке запись продолжает действовать и после перезапуска
+	if m.storage != nil {
+		if err := m.storage.Delete(ctx, accessPrefix+prefix+key); err != nil {
+			return fmt.Errorf("failed to delete access entry: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	delete(list, key)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// accessKey ключ записи
+func accessKey(scope string, id int64) string {
+	return fmt.Sprintf("%s:%d", scope, id)
+}
+
+// defaultBlockedReply ответ по умолчанию
+// В группах заблокированным отвечаем молча, чтобы не засорять чат
+func defaultBlockedReply(ctx core.UniversalContext, denial AccessDenial) core.Response {
+	var message string
+
+	switch denial.Reason {
+	case AccessDeniedMaintenance:
+		message = denial.Message
+	case AccessDeniedChatBanned:
+		return core.NewSilentResponse()
+	case AccessDeniedUserBanned:
+		if ctx.GetChatType().IsGroup() {
+			return core.NewSilentResponse()
+		}
+		message = "🚫 Доступ к боту ограничен"
+		if denial.Entry != nil && !denial.Entry.Until.IsZero() {
+			message += " до " + denial.Entry.Until.Format("02.01.2006 15:04")
+		}
+	default:
+		if ctx.GetChatType().IsGroup() {
+			return core.NewSilentResponse()
+		}
+		message = "🚫 Бот доступен только по приглашению"
+	}
+
+	if ctx.IsCallback() {
+		return core.NewCallbackAnswer(message, true)
+	}
+	return core.NewMessage(message)
+}
@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/routing"
+)
+
+var (
+	_ core.Module    = (*AccessAdminModule)(nil)
+	_ core.APIModule = (*AccessAdminModule)(nil)
+)
+
+// AccessAdminModule команды и HTTP API для управления AccessMiddleware
+//
+// Команды (только для ролей администратора):
+//
+//	/maintenance, /maintenance on, /maintenance off
+//	/ban {user}, /ban {user} {minutes}, /unban {user}
+//
+// HTTP API (/api/v1/access/...): GET|POST /maintenance, GET|POST|DELETE /bans.
+// Каждое изменение публикуется событием user.action (module "access")
+type AccessAdminModule struct {
+	access   *AccessMiddleware
+	roles    []string
+	eventBus core.EventBus
+}
+
+// NewAccessAdminModule создает модуль управления доступом
+// roles - роли, которым доступны команды (по умолчанию "admin")
+func NewAccessAdminModule(access *AccessMiddleware, roles ...string) *AccessAdminModule {
+	if len(roles) == 0 {
+		roles = []string{"admin"}
+	}
+
+	return &AccessAdminModule{
+		access: access,
+		roles:  roles,
+	}
+}
+
+func (m *AccessAdminModule) Name() string    { return "access" }
+func (m *AccessAdminModule) Version() string { return "1.0.0" }
+
+// Init загружает списки из хранилища
+func (m *AccessAdminModule) Init(deps core.Dependencies) error {
+	if deps != nil {
+		m.eventBus = deps.EventBus()
+	}
+	return m.access.Load(context.Background())
+}
+
+func (m *AccessAdminModule) Start(ctx context.Context) error { return nil }
+func (m *AccessAdminModule) Stop(ctx context.Context) error  { return nil }
+
+// Routes возвращает админские команды
+func (m *AccessAdminModule) Routes() []core.RoutePattern {
+	return []core.RoutePattern{
+		routing.NewRoute("/maintenance on", "/maintenance off", "/maintenance").
+			Handler(m.handleMaintenance).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("maintenance", "Режим обслуживания").
+			Hidden().
+			Build(),
+		routing.NewRoute("/ban {user}", "/ban {user} {amount}").
+			Handler(m.handleBan).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("ban", "Заблокировать пользователя (минуты - опционально)").
+			Hidden().
+			Build(),
+		routing.NewRoute("/unban {user}").
+			Handler(m.handleUnban).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("unban", "Разблокировать пользователя").
+			Hidden().
+			Build(),
+	}
+}
+
+// APIHandlers возвращает HTTP endpoints
+func (m *AccessAdminModule) APIHandlers() []core.APIHandler {
+	return []core.APIHandler{
+		{Method: "GET", Path: "/maintenance", Handler: m.apiGetMaintenance, Admin: true, Description: "Состояние режима обслуживания"},
+		{Method: "POST", Path: "/maintenance", Handler: m.apiSetMaintenance, Admin: true, Description: "Включить/выключить режим обслуживания"},
+		{Method: "GET", Path: "/bans", Handler: m.apiListBans, Admin: true, Description: "Список блокировок"},
+		{Method: "POST", Path: "/bans", Handler: m.apiBan, Admin: true, Description: "Заблокировать пользователя или чат"},
+		{Method: "DELETE", Path: "/bans", Handler: m.apiUnban, Admin: true, Description: "Снять блокировку"},
+	}
+}
+
+func (m *AccessAdminModule) handleMaintenance(ctx core.UniversalContext) core.Response {
+	pattern, _ := ctx.GetParam("_pattern")
+
+	var enabled bool
+	switch pattern {
+	case "/maintenance on":
+		enabled = true
+	case "/maintenance off":
+		enabled = false
+	default:
+		if m.access.Maintenance().Enabled {
+			return core.NewMessage("🛠 Режим обслуживания включен")
+		}
+		return core.NewMessage("✅ Режим обслуживания выключен")
+	}
+
+	if err := m.access.SetMaintenance(ctx.Context(), enabled, m.access.Maintenance().Message); err != nil {
+		return core.NewMessage("❌ Не удалось сохранить режим обслуживания")
+	}
+	m.publish(ctx.Context(), ctx.GetUserID(), "maintenance", "maintenance", enabled)
+
+	if enabled {
+		return core.NewMessage("🛠 Режим обслуживания включен. Бот отвечает только администраторам")
+	}
+	return core.NewMessage("✅ Режим обслуживания выключен")
+}
+
+func (m *AccessAdminModule) handleBan(ctx core.UniversalContext) core.Response {
+	userID, err := paramInt(ctx, "user")
+	if err != nil {
+		return core.NewMessage("❌ Укажите ID пользователя")
+	}
+
+	var duration time.Duration
+	if minutes, err := paramInt(ctx, "amount"); err == nil {
+		duration = time.Duration(minutes) * time.Minute
+	}
+
+	reason := fmt.Sprintf("banned by %d", ctx.GetUserID())
+	if err := m.access.Ban(ctx.Context(), AccessScopeUser, userID, duration, reason); err != nil {
+		return core.NewMessage("❌ Не удалось заблокировать пользователя")
+	}
+	m.publish(ctx.Context(), ctx.GetUserID(), "ban", accessKey(AccessScopeUser, userID), banValue(duration, reason))
+
+	if duration > 0 {
+		return core.NewMessage(fmt.Sprintf("🚫 Пользователь %d заблокирован на %d мин.", userID, int(duration.Minutes())))
+	}
+	return core.NewMessage(fmt.Sprintf("🚫 Пользователь %d заблокирован", userID))
+}
+
+func (m *AccessAdminModule) handleUnban(ctx core.UniversalContext) core.Response {
+	userID, err := paramInt(ctx, "user")
+	if err != nil {
+		return core.NewMessage("❌ Укажите ID пользователя")
+	}
+
+	if err := m.access.Unban(ctx.Context(), AccessScopeUser, userID); err != nil {
+		return core.NewMessage("❌ Не удалось разблокировать пользователя")
+	}
+	m.publish(ctx.Context(), ctx.GetUserID(), "unban", accessKey(AccessScopeUser, userID), nil)
+	return core.NewMessage(fmt.Sprintf("✅ Пользователь %d разблокирован", userID))
+}
+
+func (m *AccessAdminModule) apiGetMaintenance(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	return core.APIResponse{Body: m.access.Maintenance()}, nil
+}
+
+func (m *AccessAdminModule) apiSetMaintenance(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	body, _ := req.Body.(map[string]interface{})
+	enabled, _ := body["enabled"].(bool)
+	message, _ := body["message"].(string)
+
+	if err := m.access.SetMaintenance(ctx, enabled, message); err != nil {
+		return core.APIResponse{}, err
+	}
+	m.publish(ctx, req.UserID, "maintenance", "maintenance", enabled)
+	return core.APIResponse{Body: m.access.Maintenance()}, nil
+}
+
+func (m *AccessAdminModule) apiListBans(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	return core.APIResponse{Body: m.access.Bans()}, nil
+}
+
+func (m *AccessAdminModule) apiBan(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	body, _ := req.Body.(map[string]interface{})
+	scope, _ := body["scope"].(string)
+	id, _ := body["id"].(float64)
+	seconds, _ := body["duration"].(float64)
+	reason, _ := body["reason"].(string)
+
+	if scope == "" {
+		scope = AccessScopeUser
+	}
+	if id == 0 {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": "id is required"}}, nil
+	}
+
+	duration := time.Duration(seconds) * time.Second
+	if err := m.access.Ban(ctx, scope, int64(id), duration, reason); err != nil {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": err.Error()}}, nil
+	}
+	m.publish(ctx, req.UserID, "ban", accessKey(scope, int64(id)), banValue(duration, reason))
+	return core.APIResponse{Status: http.StatusCreated, Body: map[string]bool{"success": true}}, nil
+}
+
+func (m *AccessAdminModule) apiUnban(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	scope := req.Query["scope"]
+	if scope == "" {
+		scope = AccessScopeUser
+	}
+
+	id, err := strconv.ParseInt(req.Query["id"], 10, 64)
+	if err != nil {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": "id is required"}}, nil
+	}
+
+	if err := m.access.Unban(ctx, scope, id); err != nil {
+		return core.APIResponse{}, err
+	}
+	m.publish(ctx, req.UserID, "unban", accessKey(scope, id), nil)
+	return core.APIResponse{Body: map[string]bool{"success": true}}, nil
+}
+
+// publish сообщает об изменении событием user.action: target - "user:42", "chat:-100" или "maintenance"
+func (m *AccessAdminModule) publish(ctx context.Context, userID int64, action, target string, value interface{}) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.PublishAsync(context.WithoutCancel(ctx), events.NewAdminActionEvent(userID, m.Name(), action, target, value))
+}
+
+// banValue значение события блокировки (duration 0 - бессрочно)
+func banValue(duration time.Duration, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"duration_seconds": int64(duration.Seconds()),
+		"reason":           reason,
+	}
+}
+
+// paramInt читает числовой параметр маршрута
+func paramInt(ctx core.UniversalContext, name string) (int64, error) {
+	value, ok := ctx.GetParam(name)
+	if !ok {
+		return 0, fmt.Errorf("param %s not found", name)
+	}
+	return strconv.ParseInt(fmt.Sprint(value), 10, 64)
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// failingStorage хранилище, которое не сохраняет и не удаляет записи
+type failingStorage struct {
+	*memoryStorage
+}
+
+func (s failingStorage) Save(ctx context.Context, key string, data interface{}) error {
+	return errors.New("storage is unavailable")
+}
+
+func (s failingStorage) Delete(ctx context.Context, key string) error {
+	return errors.New("storage is unavailable")
+}
+
+func accessContext(userID int64) *core.BaseContext {
+	ctx := core.NewBaseContext(context.Background())
+	ctx.SetUserID(userID)
+	return ctx
+}
+
+func TestAccessExpiredBansAreDeleted(t *testing.T) {
+	storage := newMemoryStorage()
+	mw := NewAccessMiddleware(storage, 85)
+	ctx := context.Background()
+
+	if err := mw.Ban(ctx, AccessScopeUser, 1, time.Hour, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Ban(ctx, AccessScopeUser, 2, time.Hour, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Ban(ctx, AccessScopeUser, 3, 0, "spam"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Баны 1 и 2 истекли
+	mw.mu.Lock()
+	for _, id := range []int64{1, 2} {
+		entry := mw.banned[accessKey(AccessScopeUser, id)]
+		entry.Until = time.Now().Add(-time.Minute)
+		mw.banned[accessKey(AccessScopeUser, id)] = entry
+		_ = storage.Save(ctx, accessPrefix+"ban:"+accessKey(AccessScopeUser, id), entry)
+	}
+	mw.mu.Unlock()
+
+	if response := mw.Process(accessContext(1), passed); response.Content().Text != "passed" {
+		t.Fatalf("expired ban still blocks: %q", response.Content().Text)
+	}
+
+	mw.mu.RLock()
+	remaining := len(mw.banned)
+	mw.mu.RUnlock()
+	if remaining != 1 {
+		t.Fatalf("%d bans in memory, want 1", remaining)
+	}
+	if keys, _ := storage.List(ctx, accessPrefix+"ban:"); len(keys) != 1 {
+		t.Fatalf("bans in storage: %v", keys)
+	}
+}
+
+func TestAccessLoadDeletesExpiredBans(t *testing.T) {
+	storage := newMemoryStorage()
+	ctx := context.Background()
+	_ = storage.Save(ctx, accessPrefix+"ban:user:1", AccessEntry{Scope: AccessScopeUser, ID: 1, Until: time.Now().Add(-time.Hour)})
+	_ = storage.Save(ctx, accessPrefix+"ban:user:2", AccessEntry{Scope: AccessScopeUser, ID: 2})
+
+	mw := NewAccessMiddleware(storage, 85)
+	if err := mw.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if mw.IsBanned(AccessScopeUser, 1) || !mw.IsBanned(AccessScopeUser, 2) {
+		t.Fatalf("bans after load: %+v", mw.Bans())
+	}
+	if keys, _ := storage.List(ctx, accessPrefix); len(keys) != 1 {
+		t.Fatalf("keys after load: %v", keys)
+	}
+}
+
+func TestAccessStorageErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(mw *AccessMiddleware) error
+		banned bool
+	}{
+		{
+			name:   "failed ban does not apply",
+			change: func(mw *AccessMiddleware) error { return mw.Ban(context.Background(), AccessScopeUser, 2, 0, "spam") },
+			banned: false,
+		},
+		{
+			name:   "failed unban keeps the ban",
+			change: func(mw *AccessMiddleware) error { return mw.Unban(context.Background(), AccessScopeUser, 1) },
+			banned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newMemoryStorage()
+			mw := NewAccessMiddleware(storage, 85)
+			if err := mw.Ban(context.Background(), AccessScopeUser, 1, 0, "spam"); err != nil {
+				t.Fatal(err)
+			}
+			mw.storage = failingStorage{storage}
+
+			if err := tt.change(mw); err == nil {
+				t.Fatal("storage error is not returned")
+			}
+
+			id := int64(1)
+			if !tt.banned {
+				id = 2
+			}
+			if mw.IsBanned(AccessScopeUser, id) != tt.banned {
+				t.Fatalf("IsBanned(%d) = %v, want %v", id, !tt.banned, tt.banned)
+			}
+		})
+	}
+}
@@ -0,0 +1,525 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/routing"
+)
+
+var _ routing.Middleware = (*AntiSpamMiddleware)(nil)
+
+// SpamAction действие при нарушении
+type SpamAction string
+
+const (
+	// SpamActionIgnore молча пропустить сообщение
+	SpamActionIgnore SpamAction = "ignore"
+
+	// SpamActionWarn предупредить пользователя
+	SpamActionWarn SpamAction = "warn"
+
+	// SpamActionMute временно игнорировать все сообщения пользователя
+	SpamActionMute SpamAction = "mute"
+
+	// SpamActionBan заблокировать пользователя (сохраняется в core.Storage)
+	SpamActionBan SpamAction = "ban"
+)
+
+// Причины нарушений
+const (
+	SpamReasonRepeat        = "repeat"
+	SpamReasonCallbackFlood = "callback_flood"
+	SpamReasonLinks         = "links"
+	SpamReasonForward       = "forward"
+	SpamReasonManual        = "manual"
+)
+
+// spamBanPrefix префикс ключей банов в хранилище
+const spamBanPrefix = "antispam:ban:"
+
+// spamCleanupInterval интервал очистки неактивных счетчиков
+const spamCleanupInterval = 10 * time.Minute
+
+// linkPattern ссылки в тексте
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|t\.me/)\S+`)
+
+// SpamPolicy политика антиспама
+type SpamPolicy struct {
+	// MaxRepeats максимум одинаковых сообщений подряд (0 - не проверять)
+	MaxRepeats int
+
+	// RepeatWindow окно, в котором сообщения считаются повтором
+	RepeatWindow time.Duration
+
+	// MaxCallbacks максимум нажатий кнопок за CallbackWindow (0 - не проверять)
+	MaxCallbacks int
+
+	// CallbackWindow окно подсчета нажатий
+	CallbackWindow time.Duration
+
+	// MaxLinks максимум ссылок в сообщении (0 - не проверять)
+	MaxLinks int
+
+	// BlockForwards считать пересланные сообщения нарушением
+	BlockForwards bool
+
+	// Escalation действия по номеру нарушения (последнее повторяется)
+	Escalation []SpamAction
+
+	// MuteDuration длительность мьюта
+	MuteDuration time.Duration
+
+	// BanDuration длительность бана (0 - бессрочно)
+	BanDuration time.Duration
+
+	// ViolationTTL время, через которое нарушения забываются
+	ViolationTTL time.Duration
+
+	// WarnMessage текст предупреждения
+	WarnMessage string
+
+	// ExemptAdmins не применять политику к администраторам чата
+	ExemptAdmins bool
+}
+
+// DefaultSpamPolicy возвращает политику по умолчанию
+func DefaultSpamPolicy() SpamPolicy {
+	return SpamPolicy{
+		MaxRepeats:     3,
+		RepeatWindow:   time.Minute,
+		MaxCallbacks:   10,
+		CallbackWindow: 10 * time.Second,
+		MaxLinks:       3,
+		Escalation:     []SpamAction{SpamActionWarn, SpamActionMute, SpamActionBan},
+		MuteDuration:   5 * time.Minute,
+		ViolationTTL:   time.Hour,
+		WarnMessage:    "⚠️ Пожалуйста, не флудите",
+		ExemptAdmins:   true,
+	}
+}
+
+// SpamBan запись о бане
+type SpamBan struct {
+	UserID    int64     `json:"user_id"`
+	ChatID    int64     `json:"chat_id"`
+	Reason    string    `json:"reason"`
+	Until     time.Time `json:"until"` // нулевое значение - бессрочно
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Active проверяет, действует ли бан
+func (b SpamBan) Active(now time.Time) bool {
+	return b.Until.IsZero() || now.Before(b.Until)
+}
+
+// spamState состояние пользователя в чате
+type spamState struct {
+	lastText    string
+	lastTextAt  time.Time
+	repeats     int
+	callbacks   []time.Time
+	violations  int
+	violationAt time.Time
+	mutedUntil  time.Time
+	lastSeen    time.Time
+}
+
+// AntiSpamMiddleware защита от флуда на уровне чата
+type AntiSpamMiddleware struct {
+	defaultPolicy SpamPolicy
+	policies      map[core.ChatType]SpamPolicy
+	storage       core.Storage
+	eventBus      core.EventBus
+	priority      int
+
+	mu          sync.Mutex
+	states      map[string]*spamState
+	bans        map[string]SpamBan
+	lastCleanup time.Time
+}
+
+// NewAntiSpamMiddleware создает middleware антиспама
+// storage хранит баны между перезапусками, eventBus получает события "moderation" (оба опциональны).
+// Сохраненные баны загружаются вызовом Load при запуске
+func NewAntiSpamMiddleware(storage core.Storage, eventBus core.EventBus, priority int) *AntiSpamMiddleware {
+	return &AntiSpamMiddleware{
+		defaultPolicy: DefaultSpamPolicy(),
+		policies:      make(map[core.ChatType]SpamPolicy),
+		storage:       storage,
+		eventBus:      eventBus,
+		priority:      priority,
+		states:        make(map[string]*spamState),
+		bans:          make(map[string]SpamBan),
+		lastCleanup:   time.Now(),
+	}
+}
+
+// SetDefaultPolicy устанавливает политику по умолчанию
+func (m *AntiSpamMiddleware) SetDefaultPolicy(policy SpamPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.defaultPolicy = policy
+}
+
+// SetPolicy устанавливает политику для типа чата
+func (m *AntiSpamMiddleware) SetPolicy(chatType core.ChatType, policy SpamPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.policies[chatType] = policy
+}
+
+// Name возвращает имя
+func (m *AntiSpamMiddleware) Name() string {
+	return "antispam"
+}
+
+// Priority возвращает приоритет
+func (m *AntiSpamMiddleware) Priority() int {
+	return m.priority
+}
+
+// Process проверяет запрос на флуд
+func (m *AntiSpamMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
+	if ctx.GetUserID() == 0 {
+		return next(ctx)
+	}
+
+	key := spamKey(ctx.GetChatID(), ctx.GetUserID())
+	now := time.Now()
+
+	m.mu.Lock()
+	m.cleanup(now)
+
+	// Забаненные и замьюченные пользователи игнорируются
+	expired := false
+	if ban, ok := m.bans[key]; ok {
+		if ban.Active(now) {
+			m.mu.Unlock()
+			return core.NewSilentResponse()
+		}
+		delete(m.bans, key)
+		expired = true
+	}
+
+	state := m.state(key, now)
+	muted := now.Before(state.mutedUntil)
+
+	var policy SpamPolicy
+	var reason string
+	if !muted {
+		policy = m.policyFor(ctx.GetChatType())
+		reason = detectSpam(ctx, policy, state, now)
+	}
+	m.mu.Unlock()
+
+	// Хранилище вызывается без блокировки, чтобы не задерживать остальные запросы
+	if expired {
+		m.deleteBan(requestContext(ctx), key)
+	}
+	if muted {
+		return core.NewSilentResponse()
+	}
+
+	if reason == "" {
+		return next(ctx)
+	}
+
+	if policy.ExemptAdmins && ctx.GetChatType().IsGroup() && ctx.IsChatAdmin() {
+		return next(ctx)
+	}
+
+	return m.punish(ctx, policy, key, reason, now)
+}
+
+// Ban блокирует пользователя в чате (duration 0 - бессрочно)
+func (m *AntiSpamMiddleware) Ban(ctx context.Context, chatID, userID int64, duration time.Duration, reason string) error {
+	now := time.Now()
+	ban := SpamBan{
+		UserID:    userID,
+		ChatID:    chatID,
+		Reason:    reason,
+		CreatedAt: now,
+	}
+	if duration > 0 {
+		ban.Until = now.Add(duration)
+	}
+
+	key := spamKey(chatID, userID)
+
+	m.mu.Lock()
+	m.bans[key] = ban
+	m.mu.Unlock()
+
+	if m.storage != nil {
+		if err := m.storage.Save(ctx, spamBanPrefix+key, ban); err != nil {
+			return fmt.Errorf("failed to save ban: %w", err)
+		}
+	}
+
+	m.publish(ctx, events.NewModerationEvent(userID, chatID, string(SpamActionBan), reason).SetUntil(unixOrZero(ban.Until)))
+	return nil
+}
+
+// Unban снимает бан и сбрасывает нарушения
+func (m *AntiSpamMiddleware) Unban(ctx context.Context, chatID, userID int64) error {
+	key := spamKey(chatID, userID)
+
+	m.mu.Lock()
+	delete(m.bans, key)
+	delete(m.states, key)
+	m.mu.Unlock()
+
+	if m.storage != nil {
+		if err := m.storage.Delete(ctx, spamBanPrefix+key); err != nil {
+			return fmt.Errorf("failed to delete ban: %w", err)
+		}
+	}
+
+	m.publish(ctx, events.NewModerationEvent(userID, chatID, "unban", SpamReasonManual))
+	return nil
+}
+
+// IsBanned проверяет, забанен ли пользователь в чате
+func (m *AntiSpamMiddleware) IsBanned(chatID, userID int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ban, ok := m.bans[spamKey(chatID, userID)]
+	return ok && ban.Active(time.Now())
+}
+
+// punish применяет действие эскалации
+func (m *AntiSpamMiddleware) punish(ctx core.UniversalContext, policy SpamPolicy, key, reason string, now time.Time) core.Response {
+	m.mu.Lock()
+	state := m.state(key, now)
+	if now.Sub(state.violationAt) > policy.ViolationTTL {
+		state.violations = 0
+	}
+	state.violations++
+	state.violationAt = now
+
+	action := SpamActionIgnore
+	if len(policy.Escalation) > 0 {
+		idx := state.violations - 1
+		if idx >= len(policy.Escalation) {
+			idx = len(policy.Escalation) - 1
+		}
+		action = policy.Escalation[idx]
+	}
+
+	if action == SpamActionMute {
+		state.mutedUntil = now.Add(policy.MuteDuration)
+	}
+	violations, mutedUntil := state.violations, state.mutedUntil
+	m.mu.Unlock()
+
+	event := events.NewModerationEvent(ctx.GetUserID(), ctx.GetChatID(), string(action), reason)
+	event.SetData("violations", violations)
+
+	switch action {
+	case SpamActionWarn:
+		m.publish(requestContext(ctx), event)
+		if ctx.IsCallback() {
+			return core.NewCallbackAnswer(policy.WarnMessage, true)
+		}
+		return core.NewMessage(policy.WarnMessage)
+
+	case SpamActionMute:
+		m.publish(requestContext(ctx), event.SetUntil(mutedUntil.Unix()))
+
+	case SpamActionBan:
+		// Ban сам публикует событие
+		if err := m.Ban(requestContext(ctx), ctx.GetChatID(), ctx.GetUserID(), policy.BanDuration, reason); err != nil {
+			return core.NewSilentResponse()
+		}
+
+	default:
+		m.publish(requestContext(ctx), event)
+	}
+
+	return core.NewSilentResponse()
+}
+
+// detectSpam возвращает причину нарушения или пустую строку
+func detectSpam(ctx core.UniversalContext, policy SpamPolicy, state *spamState, now time.Time) string {
+	if ctx.IsCallback() {
+		if policy.MaxCallbacks <= 0 {
+			return ""
+		}
+
+		recent := state.callbacks[:0]
+		for _, at := range state.callbacks {
+			if now.Sub(at) < policy.CallbackWindow {
+				recent = append(recent, at)
+			}
+		}
+		state.callbacks = append(recent, now)
+
+		if len(state.callbacks) > policy.MaxCallbacks {
+			return SpamReasonCallbackFlood
+		}
+		return ""
+	}
+
+	if policy.BlockForwards {
+		if value, ok := ctx.Get(core.ForwardedKey); ok {
+			if forwarded, _ := value.(bool); forwarded {
+				return SpamReasonForward
+			}
+		}
+	}
+
+	text := ctx.GetText()
+
+	if policy.MaxLinks > 0 && countLinks(ctx, text) > policy.MaxLinks {
+		return SpamReasonLinks
+	}
+
+	if policy.MaxRepeats > 0 {
+		normalized := strings.ToLower(strings.TrimSpace(text))
+		if normalized == "" {
+			return ""
+		}
+
+		if normalized == state.lastText && now.Sub(state.lastTextAt) < policy.RepeatWindow {
+			state.repeats++
+		} else {
+			state.lastText = normalized
+			state.repeats = 1
+		}
+		state.lastTextAt = now
+
+		if state.repeats > policy.MaxRepeats {
+			return SpamReasonRepeat
+		}
+	}
+
+	return ""
+}
+
+// countLinks считает ссылки в тексте и в сущностях адаптера
+func countLinks(ctx core.UniversalContext, text string) int {
+	count := len(linkPattern.FindAllStringIndex(text, -1))
+	if links, ok := ctx.Get(core.LinksKey); ok {
+		if n, ok := links.(int); ok && n > count {
+			count = n
+		}
+	}
+	return count
+}
+
+// state возвращает состояние пользователя (вызывается под mu)
+func (m *AntiSpamMiddleware) state(key string, now time.Time) *spamState {
+	state, ok := m.states[key]
+	if !ok {
+		state = &spamState{}
+		m.states[key] = state
+	}
+
+	state.lastSeen = now
+	return state
+}
+
+// policyFor возвращает политику для типа чата (вызывается под mu)
+func (m *AntiSpamMiddleware) policyFor(chatType core.ChatType) SpamPolicy {
+	if policy, ok := m.policies[chatType]; ok {
+		return policy
+	}
+	return m.defaultPolicy
+}
+
+// cleanup удаляет неактивные состояния (вызывается под mu)
+func (m *AntiSpamMiddleware) cleanup(now time.Time) {
+	if now.Sub(m.lastCleanup) < spamCleanupInterval {
+		return
+	}
+	m.lastCleanup = now
+
+	for key, state := range m.states {
+		idle := now.Sub(state.lastSeen) > spamCleanupInterval && now.After(state.mutedUntil)
+		forgiven := state.violations == 0 || now.Sub(state.violationAt) > m.defaultPolicy.ViolationTTL
+		if idle && forgiven {
+			delete(m.states, key)
+		}
+	}
+}
+
+// Load загружает баны из хранилища (вызывается при запуске)
+// Баны, выданные до загрузки, сохраняются
+func (m *AntiSpamMiddleware) Load(ctx context.Context) error {
+	if m.storage == nil {
+		return nil
+	}
+
+	keys, err := m.storage.List(ctx, spamBanPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list bans: %w", err)
+	}
+
+	now := time.Now()
+	loaded := make(map[string]SpamBan, len(keys))
+	for _, key := range keys {
+		var ban SpamBan
+		if err := m.storage.Load(ctx, key, &ban); err != nil {
+			return fmt.Errorf("failed to load ban %s: %w", key, err)
+		}
+		if ban.Active(now) {
+			loaded[strings.TrimPrefix(key, spamBanPrefix)] = ban
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, ban := range loaded {
+		if _, ok := m.bans[key]; !ok {
+			m.bans[key] = ban
+		}
+	}
+	return nil
+}
+
+// deleteBan удаляет истекший бан из хранилища
+func (m *AntiSpamMiddleware) deleteBan(ctx context.Context, key string) {
+	if m.storage != nil {
+		_ = m.storage.Delete(ctx, spamBanPrefix+key)
+	}
+}
+
+// publish публикует событие модерации
+// Подписчики обрабатывают его асинхронно, поэтому отмена запроса на них не влияет
+func (m *AntiSpamMiddleware) publish(ctx context.Context, event core.Event) {
+	if m.eventBus != nil {
+		m.eventBus.PublishAsync(context.WithoutCancel(ctx), event)
+	}
+}
+
+// spamKey ключ пользователя в чате
+func spamKey(chatID, userID int64) string {
+	return fmt.Sprintf("%d:%d", chatID, userID)
+}
+
+// unixOrZero возвращает unix время или 0 для нулевого времени
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// requestContext возвращает context.Context запроса
+func requestContext(ctx core.UniversalContext) context.Context {
+	if c := ctx.Context(); c != nil {
+		return c
+	}
+	return context.Background()
+}
@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// memoryStorage core.Storage в памяти для тестов
+type memoryStorage struct {
+	mu      sync.Mutex
+	data    map[string][]byte
+	listErr error
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+func (s *memoryStorage) Save(ctx context.Context, key string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = encoded
+	return nil
+}
+
+func (s *memoryStorage) Load(ctx context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	encoded, ok := s.data[key]
+	if !ok {
+		return fmt.Errorf("key %s not found", key)
+	}
+	return json.Unmarshal(encoded, dest)
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memoryStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// contextBus core.EventBus, запоминающий контексты асинхронных публикаций
+type contextBus struct {
+	mu       sync.Mutex
+	contexts []context.Context
+}
+
+func (b *contextBus) Subscribe(eventType string, handler core.EventHandlerFunc) error   { return nil }
+func (b *contextBus) Unsubscribe(eventType string, handler core.EventHandlerFunc) error { return nil }
+func (b *contextBus) Start(ctx context.Context) error                                   { return nil }
+func (b *contextBus) Stop(ctx context.Context) error                                    { return nil }
+func (b *contextBus) Publish(ctx context.Context, event core.Event) error               { return nil }
+
+func (b *contextBus) PublishAsync(ctx context.Context, event core.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.contexts = append(b.contexts, ctx)
+}
+
+func spamContext(userID int64, text string) *core.BaseContext {
+	ctx := core.NewBaseContext(context.Background())
+	ctx.SetUserID(userID)
+	ctx.SetChatID(-100)
+	ctx.SetChatType(core.ChatTypeSupergroup)
+	ctx.SetText(text)
+	return ctx
+}
+
+func passed(ctx core.UniversalContext) core.Response {
+	return core.NewMessage("passed")
+}
+
+func TestAntiSpamForwardValue(t *testing.T) {
+	policy := DefaultSpamPolicy()
+	policy.BlockForwards = true
+	policy.ExemptAdmins = false
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		blocked bool
+	}{
+		{name: "forwarded", value: true, blocked: true},
+		{name: "not forwarded", value: false, blocked: false},
+		{name: "string value", value: "yes", blocked: false},
+		{name: "number value", value: 1, blocked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := NewAntiSpamMiddleware(nil, nil, 82)
+			mw.SetDefaultPolicy(policy)
+
+			ctx := spamContext(1, "hello")
+			ctx.Set(core.ForwardedKey, tt.value)
+
+			response := mw.Process(ctx, passed)
+			if blocked := response.Content().Text != "passed"; blocked != tt.blocked {
+				t.Fatalf("blocked = %v, want %v", blocked, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestAntiSpamEscalation(t *testing.T) {
+	storage := newMemoryStorage()
+	mw := NewAntiSpamMiddleware(storage, nil, 82)
+	policy := DefaultSpamPolicy()
+	policy.MaxRepeats = 1
+	policy.ExemptAdmins = false
+	mw.SetDefaultPolicy(policy)
+
+	want := []string{"passed", policy.WarnMessage, "", "", ""}
+	for i, text := range want {
+		response := mw.Process(spamContext(1, "spam"), passed)
+		if got := response.Content().Text; got != text {
+			t.Fatalf("message %d: got %q, want %q", i+1, got, text)
+		}
+	}
+
+	// Мьют не доходит до эскалации, бан выдается после него
+	mw.mu.Lock()
+	mw.states["-100:1"].mutedUntil = time.Time{}
+	mw.mu.Unlock()
+	mw.Process(spamContext(1, "spam"), passed)
+
+	if !mw.IsBanned(-100, 1) {
+		t.Fatal("user is not banned after escalation")
+	}
+	if keys, _ := storage.List(context.Background(), spamBanPrefix); len(keys) != 1 {
+		t.Fatalf("ban is not persisted: %v", keys)
+	}
+}
+
+func TestAntiSpamEventsOutliveRequest(t *testing.T) {
+	bus := &contextBus{}
+	mw := NewAntiSpamMiddleware(newMemoryStorage(), bus, 82)
+	policy := DefaultSpamPolicy()
+	policy.MaxRepeats = 1
+	policy.ExemptAdmins = false
+	mw.SetDefaultPolicy(policy)
+
+	// Запрос уже отменен (таймаут или отключение клиента)
+	requestCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 3; i++ {
+		ctx := spamContext(1, "spam")
+		ctx.WithContext(requestCtx)
+		mw.Process(ctx, passed)
+	}
+
+	if len(bus.contexts) != 2 {
+		t.Fatalf("published %d events, want warn and mute", len(bus.contexts))
+	}
+	for i, ctx := range bus.contexts {
+		if ctx.Err() != nil {
+			t.Fatalf("event %d context: %v", i+1, ctx.Err())
+		}
+	}
+}
+
+func TestAntiSpamLoad(t *testing.T) {
+	storage := newMemoryStorage()
+	ctx := context.Background()
+
+	_ = storage.Save(ctx, spamBanPrefix+"-100:1", SpamBan{UserID: 1, ChatID: -100})
+	_ = storage.Save(ctx, spamBanPrefix+"-100:2", SpamBan{UserID: 2, ChatID: -100, Until: time.Now().Add(-time.Minute)})
+
+	mw := NewAntiSpamMiddleware(storage, nil, 82)
+	if err := mw.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !mw.IsBanned(-100, 1) {
+		t.Error("persisted ban is not loaded")
+	}
+	if mw.IsBanned(-100, 2) {
+		t.Error("expired ban is loaded")
+	}
+	if response := mw.Process(spamContext(1, "hi"), passed); !response.IsSilent() {
+		t.Error("banned user is not ignored")
+	}
+
+	storage.listErr = errors.New("storage is down")
+	if err := NewAntiSpamMiddleware(storage, nil, 82).Load(ctx); err == nil {
+		t.Error("Load ignores storage errors")
+	}
+}
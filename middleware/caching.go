@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/routing"
+)
+
+var _ routing.ResponseCache = (*CachingMiddleware)(nil)
+
+// DefaultResponseCacheTTL время жизни ответа, если в политике маршрута TTL не задан
+const DefaultResponseCacheTTL = 5 * time.Minute
+
+// CacheStats статистика кеша ответов
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// CachingMiddleware кеширует ответы маршрутов в core.Cache
+//
+// Кешируются только маршруты с политикой (RouteBuilder.Cache, CacheWith, CacheKey).
+// Middleware передает себя маршрутам через контекст, а решение о кешировании
+// принимается после сопоставления маршрута и проверок безопасности.
+// Ответы сериализуются (core.MarshalResponse), поэтому подходит любой core.Cache,
+// в том числе внешний. Маршруты с InvalidatesCache сбрасывают кеш своего модуля
+type CachingMiddleware struct {
+	cache      core.Cache
+	defaultTTL time.Duration
+	metrics    core.Metrics
+	priority   int
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingMiddleware создает middleware кеширования ответов
+func NewCachingMiddleware(cache core.Cache, priority int) *CachingMiddleware {
+	return &CachingMiddleware{
+		cache:      cache,
+		defaultTTL: DefaultResponseCacheTTL,
+		priority:   priority,
+	}
+}
+
+// SetDefaultTTL устанавливает TTL для политик без TTL
+func (m *CachingMiddleware) SetDefaultTTL(ttl time.Duration) {
+	if ttl > 0 {
+		m.defaultTTL = ttl
+	}
+}
+
+// SetMetrics включает метрики cache.hits и cache.misses (с тегом module)
+func (m *CachingMiddleware) SetMetrics(metrics core.Metrics) {
+	m.metrics = metrics
+}
+
+// Name возвращает имя
+func (m *CachingMiddleware) Name() string {
+	return "caching"
+}
+
+// Priority возвращает приоритет
+func (m *CachingMiddleware) Priority() int {
+	return m.priority
+}
+
+// Process делает кеш доступным маршрутам запроса
+func (m *CachingMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
+	if m.cache != nil {
+		ctx.Set(routing.ResponseCacheKey, routing.ResponseCache(m))
+	}
+	return next(ctx)
+}
+
+// Stats возвращает статистику попаданий
+func (m *CachingMiddleware) Stats() CacheStats {
+	return CacheStats{
+		Hits:   m.hits.Load(),
+		Misses: m.misses.Load(),
+	}
+}
+
+// Lookup возвращает сохраненный ответ маршрута
+func (m *CachingMiddleware) Lookup(ctx core.UniversalContext, module, key string) (core.Response, bool) {
+	reqCtx := requestContext(ctx)
+
+	value, err := m.cache.Get(reqCtx, m.entryKey(reqCtx, module, key))
+	response, ok := decodeResponse(value)
+	if err != nil || !ok {
+		m.record("cache.misses", module, &m.misses)
+		return nil, false
+	}
+
+	m.record("cache.hits", module, &m.hits)
+	ctx.Set(core.CacheHitKey, true)
+	return response, true
+}
+
+// Store сохраняет ответ маршрута
+func (m *CachingMiddleware) Store(ctx core.UniversalContext, module, key string, ttl time.Duration, response core.Response) {
+	data, err := core.MarshalResponse(response)
+	if err != nil {
+		return
+	}
+
+	if ttl <= 0 {
+		ttl = m.defaultTTL
+	}
+
+	reqCtx := requestContext(ctx)
+	_ = m.cache.Set(reqCtx, m.entryKey(reqCtx, module, key), data, int(ttl.Seconds()))
+}
+
+// Invalidate сбрасывает все ответы модуля (меняет поколение ключей)
+func (m *CachingMiddleware) Invalidate(ctx core.UniversalContext, module string) {
+	m.InvalidateModule(requestContext(ctx), module)
+}
+
+// InvalidateModule сбрасывает все ответы модуля вне обработки запроса
+// (например, из обработчика события). Старые записи истекают по TTL
+func (m *CachingMiddleware) InvalidateModule(ctx context.Context, module string) error {
+	genKey := "respcache:gen:" + module
+
+	if counter, ok := m.cache.(core.CounterCache); ok {
+		_, err := counter.Increment(ctx, genKey, 1, 0)
+		return err
+	}
+	return m.cache.Set(ctx, genKey, strconv.FormatInt(time.Now().UnixNano(), 10), 0)
+}
+
+// entryKey ключ записи с учетом поколения модуля
+func (m *CachingMiddleware) entryKey(ctx context.Context, module, key string) string {
+	generation := "0"
+	if value, err := m.cache.Get(ctx, "respcache:gen:"+module); err == nil && value != nil {
+		generation = fmt.Sprint(value)
+	}
+
+	sum := sha1.Sum([]byte(key))
+	return "respcache:" + module + ":" + generation + ":" + hex.EncodeToString(sum[:])
+}
+
+// record учитывает попадание или промах
+func (m *CachingMiddleware) record(metric, module string, counter *atomic.Int64) {
+	counter.Add(1)
+	if m.metrics != nil {
+		m.metrics.Counter(metric, 1, "module", module)
+	}
+}
+
+// decodeResponse восстанавливает ответ, сохраненный в core.Cache
+// Внешние кеши возвращают сериализованный ответ ([]byte или string),
+// in-memory кеши могут вернуть сам ответ
+func decodeResponse(value interface{}) (core.Response, bool) {
+	switch v := value.(type) {
+	case []byte:
+		response, err := core.UnmarshalResponse(v)
+		return response, err == nil
+	case string:
+		response, err := core.UnmarshalResponse([]byte(v))
+		return response, err == nil
+	case core.Response:
+		return v, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/routing"
+)
+
+var _ routing.Middleware = (*ChatActionMiddleware)(nil)
+
+// DefaultChatActionInterval интервал повтора действия (Telegram показывает его ~5 секунд)
+const DefaultChatActionInterval = 4 * time.Second
+
+// ChatActionMiddleware показывает индикатор действия, пока обработчик работает дольше порога
+type ChatActionMiddleware struct {
+	action    core.ChatAction
+	threshold time.Duration
+	interval  time.Duration
+	priority  int
+}
+
+// NewChatActionMiddleware создает middleware индикатора действия
+func NewChatActionMiddleware(action core.ChatAction, threshold time.Duration, priority int) *ChatActionMiddleware {
+	return &ChatActionMiddleware{
+		action:    action,
+		threshold: threshold,
+		interval:  DefaultChatActionInterval,
+		priority:  priority,
+	}
+}
+
+// SetInterval устанавливает интервал повтора действия
+func (m *ChatActionMiddleware) SetInterval(interval time.Duration) {
+	if interval > 0 {
+		m.interval = interval
+	}
+}
+
+// Name возвращает имя
+func (m *ChatActionMiddleware) Name() string {
+	return "chat_action"
+}
+
+// Priority возвращает приоритет
+func (m *ChatActionMiddleware) Priority() int {
+	return m.priority
+}
+
+// Process запускает индикатор, если обработчик не уложился в порог
+// Индикатор повторяется до завершения обработчика
+func (m *ChatActionMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		timer := time.NewTimer(m.threshold)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-done:
+			return
+		}
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		// Ошибка отправки (429, сеть) не останавливает индикатор: следующий тик повторит
+		// действие. В лог попадает первая ошибка подряд, а не каждая
+		failing := false
+		for {
+			if err := ctx.SendChatAction(m.action); err != nil {
+				if !failing {
+					if logger := core.LoggerFrom(ctx, nil); logger != nil {
+						logger.Warn("Failed to send chat action", "action", m.action, "error", err)
+					}
+				}
+				failing = true
+			} else {
+				failing = false
+			}
+
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return next(ctx)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+func TestChatActionSurvivesSendErrors(t *testing.T) {
+	var sent atomic.Int32
+
+	ctx := core.NewBaseContext(context.Background())
+	ctx.SetChatActionSender(func(action core.ChatAction) error {
+		// Первые отправки отклоняются (429), индикатор продолжает тикать
+		if sent.Add(1) <= 2 {
+			return errors.New("too many requests")
+		}
+		return nil
+	})
+
+	mw := NewChatActionMiddleware(core.ChatActionTyping, 0, 10)
+	mw.SetInterval(5 * time.Millisecond)
+
+	mw.Process(ctx, func(ctx core.UniversalContext) core.Response {
+		deadline := time.Now().Add(time.Second)
+		for sent.Load() < 4 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		return core.NewMessage("done")
+	})
+
+	if n := sent.Load(); n < 4 {
+		t.Fatalf("sent %d chat actions, want the indicator to continue after errors", n)
+	}
+
+	// После ответа индикатор останавливается
+	time.Sleep(20 * time.Millisecond)
+	stopped := sent.Load()
+	time.Sleep(20 * time.Millisecond)
+	if sent.Load() != stopped {
+		t.Fatal("chat action is sent after the handler returned")
+	}
+}
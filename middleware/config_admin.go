@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andranikuz/botkit/config"
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/routing"
+)
+
+var (
+	_ core.Module    = (*ConfigAdminModule)(nil)
+	_ core.APIModule = (*ConfigAdminModule)(nil)
+)
+
+// ConfigAdminModule команды и HTTP API для перезагрузки конфигурации
+//
+// Команды (только для ролей администратора):
+//
+//	/config reload             - перечитать файлы конфигурации
+//	/config set {key} {value}  - изменить значение до перезапуска
+//
+// HTTP API (/api/v1/config/...): POST /reload, POST /values.
+// Подписчики узнают об изменениях из события "config.changed", кто их сделал -
+// из user.action (module "config", action "config.set" или "config.reload").
+type ConfigAdminModule struct {
+	config   *config.Config
+	logger   core.Logger
+	roles    []string
+	eventBus core.EventBus
+}
+
+// NewConfigAdminModule создает модуль управления конфигурацией
+// roles - роли, которым доступны команды (по умолчанию "admin")
+func NewConfigAdminModule(cfg *config.Config, logger core.Logger, roles ...string) *ConfigAdminModule {
+	if len(roles) == 0 {
+		roles = []string{"admin"}
+	}
+
+	return &ConfigAdminModule{
+		config: cfg,
+		logger: logger,
+		roles:  roles,
+	}
+}
+
+func (m *ConfigAdminModule) Name() string    { return "config" }
+func (m *ConfigAdminModule) Version() string { return "1.0.0" }
+
+func (m *ConfigAdminModule) Init(deps core.Dependencies) error {
+	if deps != nil {
+		m.eventBus = deps.EventBus()
+	}
+	return nil
+}
+
+func (m *ConfigAdminModule) Start(ctx context.Context) error { return nil }
+func (m *ConfigAdminModule) Stop(ctx context.Context) error  { return nil }
+
+// Routes возвращает админские команды
+func (m *ConfigAdminModule) Routes() []core.RoutePattern {
+	return []core.RoutePattern{
+		routing.NewRoute("/config reload").
+			Handler(m.handleReload).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("config_reload", "Перечитать конфигурацию").
+			Hidden().
+			Build(),
+		routing.NewRoute("/config set {text}").
+			Handler(m.handleSet).
+			Priority(100).
+			RequireRoles(m.roles...).
+			Meta("config_set", "Изменить значение конфигурации").
+			Hidden().
+			Build(),
+	}
+}
+
+// APIHandlers возвращает HTTP endpoints
+func (m *ConfigAdminModule) APIHandlers() []core.APIHandler {
+	return []core.APIHandler{
+		{Method: "POST", Path: "/reload", Handler: m.apiReload, Admin: true, Description: "Перечитать файлы конфигурации"},
+		{Method: "POST", Path: "/values", Handler: m.apiSet, Admin: true, Description: "Изменить значение конфигурации"},
+	}
+}
+
+func (m *ConfigAdminModule) handleReload(ctx core.UniversalContext) core.Response {
+	if err := m.reload(); err != nil {
+		return core.NewMessage("❌ Не удалось перечитать конфигурацию, действуют прежние значения")
+	}
+	m.publish(ctx.Context(), ctx.GetUserID(), "config.reload", "files", nil)
+	return core.NewMessage("✅ Конфигурация перечитана")
+}
+
+func (m *ConfigAdminModule) handleSet(ctx core.UniversalContext) core.Response {
+	param, _ := ctx.GetParam("text")
+	text, _ := param.(string)
+	key, value, ok := strings.Cut(strings.TrimSpace(text), " ")
+	if !ok || key == "" {
+		return core.NewMessage("❌ Укажите ключ и значение: /config set ratelimit.requests 20")
+	}
+
+	m.config.Set(key, strings.TrimSpace(value))
+	if m.logger != nil {
+		m.logger.Info("Config value changed", "key", key, "user_id", ctx.GetUserID())
+	}
+	m.publish(ctx.Context(), ctx.GetUserID(), "config.set", key, strings.TrimSpace(value))
+	return core.NewMessage(fmt.Sprintf("✅ %s изменен до перезапуска", key))
+}
+
+func (m *ConfigAdminModule) apiReload(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	if err := m.reload(); err != nil {
+		return core.APIResponse{Status: http.StatusUnprocessableEntity, Body: map[string]string{"error": err.Error()}}, nil
+	}
+	m.publish(ctx, req.UserID, "config.reload", "files", nil)
+	return core.APIResponse{Body: map[string]bool{"success": true}}, nil
+}
+
+func (m *ConfigAdminModule) apiSet(ctx context.Context, req core.APIRequest) (core.APIResponse, error) {
+	body, _ := req.Body.(map[string]interface{})
+	key, _ := body["key"].(string)
+	value, ok := body["value"]
+
+	if key == "" || !ok {
+		return core.APIResponse{Status: http.StatusBadRequest, Body: map[string]string{"error": "key and value are required"}}, nil
+	}
+
+	m.config.Set(key, value)
+	if m.logger != nil {
+		m.logger.Info("Config value changed", "key", key)
+	}
+	m.publish(ctx, req.UserID, "config.set", key, value)
+	return core.APIResponse{Body: map[string]bool{"success": true}}, nil
+}
+
+// publish сообщает об изменении конфигурации событием user.action
+func (m *ConfigAdminModule) publish(ctx context.Context, userID int64, action, key string, value interface{}) {
+	if m.eventBus == nil {
+		return
+	}
+	m.eventBus.PublishAsync(context.WithoutCancel(ctx), events.NewAdminActionEvent(userID, m.Name(), action, key, value))
+}
+
+// reload перечитывает конфигурацию по команде администратора
+func (m *ConfigAdminModule) reload() error {
+	err := m.config.Reload("admin")
+	if m.logger != nil {
+		if err != nil {
+			m.logger.Error("Failed to reload config, keeping previous values", "error", err, "reason", "admin")
+		} else {
+			m.logger.Info("Config reloaded", "reason", "admin")
+		}
+	}
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
 	"github.com/andranikuz/botkit/routing"
 )
 
@@ -81,10 +82,8 @@ func (m *LoggingMiddleware) Priority() int {
 func (m *LoggingMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
 	start := time.Now()
 
-	// Логируем входящий запрос
-	m.logger.Info("Request received",
-		"user_id", ctx.GetUserID(),
-		"chat_id", ctx.GetChatID(),
+	// Логгер запроса уже содержит пользователя, чат, источник и request ID
+	core.LoggerFrom(ctx, m.logger).Info("Request received",
 		"text", ctx.GetText(),
 		"is_command", ctx.IsCommand(),
 		"is_callback", ctx.IsCallback(),
@@ -93,10 +92,9 @@ func (m *LoggingMiddleware) Process(ctx core.UniversalContext, next core.Handler
 	// Выполняем обработчик
 	response := next(ctx)
 
-	// Логируем ответ
+	// Логируем ответ (после маршрутизации в логгере есть модуль и маршрут)
 	duration := time.Since(start)
-	m.logger.Info("Request processed",
-		"user_id", ctx.GetUserID(),
+	core.LoggerFrom(ctx, m.logger).Info("Request processed",
 		"duration_ms", duration.Milliseconds(),
 		"response_type", fmt.Sprintf("%T", response),
 	)
@@ -133,10 +131,9 @@ func (m *RecoveryMiddleware) Process(ctx core.UniversalContext, next core.Handle
 	defer func() {
 		if r := recover(); r != nil {
 			// Логируем панику
-			m.logger.Error("Panic recovered",
+			core.LoggerFrom(ctx, m.logger).Error("Panic recovered",
 				"panic", r,
 				"stack", string(debug.Stack()),
-				"user_id", ctx.GetUserID(),
 				"text", ctx.GetText(),
 			)
 
@@ -207,13 +204,52 @@ func (m *RateLimitMiddleware) Priority() int {
 
 // Process проверяет rate limit
 func (m *RateLimitMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
-	if !m.limiter.Allow(ctx) {
-		return core.NewMessage("⏱️ Слишком много запросов. Подождите немного.")
+	state := m.limiter.Take(ctx)
+	if !state.Allowed {
+		return core.NewMessage(routing.SecurityFailureMessage(ctx, &routing.RateLimitError{State: state}))
 	}
 
 	return next(ctx)
 }
 
+// WatchConfig применяет лимиты из секции key конфигурации (requests, window в секундах,
+// burst_size, strategy) и перечитывает их при событии "config.changed" этой секции.
+// Незаданные ключи сохраняют текущие значения
+func (m *RateLimitMiddleware) WatchConfig(cfg core.Config, key string, bus core.EventBus) error {
+	m.applyConfig(cfg, key)
+	if bus == nil {
+		return nil
+	}
+
+	return bus.Subscribe("config.changed", func(ctx context.Context, event core.Event) error {
+		if changed, ok := event.(*events.ConfigChangedEvent); ok && !changed.Affects(key) {
+			return nil
+		}
+		m.applyConfig(cfg, key)
+		return nil
+	})
+}
+
+// applyConfig обновляет лимиты ограничителя из конфигурации
+func (m *RateLimitMiddleware) applyConfig(cfg core.Config, key string) {
+	limits := m.limiter.Config()
+
+	if cfg.IsSet(key + ".requests") {
+		limits.Requests = cfg.GetInt(key + ".requests")
+	}
+	if cfg.IsSet(key + ".window") {
+		limits.Window = cfg.GetInt(key + ".window")
+	}
+	if cfg.IsSet(key + ".burst_size") {
+		limits.BurstSize = cfg.GetInt(key + ".burst_size")
+	}
+	if cfg.IsSet(key + ".strategy") {
+		limits.Strategy = cfg.GetString(key + ".strategy")
+	}
+
+	m.limiter.Update(limits)
+}
+
 // MetricsMiddleware middleware для сбора метрик
 type MetricsMiddleware struct {
 	metrics  core.Metrics
@@ -297,21 +333,29 @@ func (m *ContextMiddleware) Priority() int {
 	return m.priority
 }
 
-// Process добавляет контекст
+// Process подменяет контекст Go на время обработки запроса
+// Дальнейшие обработчики получают его через ctx.Context().
+// Для ограничения времени выполнения используйте TimeoutMiddleware
 func (m *ContextMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
-	// Создаем контекст с таймаутом
-	reqCtx := m.contextFunc(ctx)
+	parent := ctx.Context()
 
-	// Сохраняем контекст в UniversalContext
-	ctx.SetParam("_context", reqCtx)
-
-	// Проверяем отмену контекста
-	select {
-	case <-reqCtx.Done():
-		return core.NewMessage("⏱️ Время обработки запроса истекло.")
-	default:
+	reqCtx := m.contextFunc(ctx)
+	if reqCtx == nil {
 		return next(ctx)
 	}
+
+	// Запрос уже отменен - обработчик не вызываем
+	if reqCtx.Err() != nil {
+		if parent != nil && parent.Err() != nil {
+			return core.NewSilentResponse()
+		}
+		return core.NewMessage(DefaultTimeoutMessage)
+	}
+
+	ctx.WithContext(reqCtx)
+	defer ctx.WithContext(parent)
+
+	return next(ctx)
 }
 
 // ValidationMiddleware middleware для валидации данных
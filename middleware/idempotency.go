@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// DefaultIdempotencyTTL сколько хранится ответ на запрос
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// DefaultIdempotencyLockTTL сколько держится блокировка на время обработки
+// (защищает от параллельной обработки дубля другим экземпляром бота)
+const DefaultIdempotencyLockTTL = time.Minute
+
+// IdempotencyKeyFunc возвращает ключ идемпотентности запроса ("" - не проверять)
+type IdempotencyKeyFunc func(ctx core.UniversalContext) string
+
+// IdempotencyKeyFromContext ключ, установленный адаптером (core.IdempotencyKey)
+func IdempotencyKeyFromContext(ctx core.UniversalContext) string {
+	if value, ok := ctx.Get(core.IdempotencyKey); ok {
+		if key, ok := value.(string); ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// IdempotencyMiddleware подавляет повторную обработку одного и того же запроса
+//
+// Ключ берется из контекста: адаптеры заполняют его из update ID (Telegram),
+// заголовка Idempotency-Key (HTTP) или ID сообщения (WebSocket). Ответ обработчика
+// сериализуется в core.Cache, и дубли получают тот же ответ без повторного вызова обработчика.
+// Пока запрос обрабатывается, дубли на этом экземпляре ждут его ответ,
+// а на других экземплярах получают тихий ответ.
+//
+// Потоковые ответы не сохраняются - их нельзя воспроизвести, ответы с ошибкой
+// (core.ErrorResponse) - чтобы повтор после сбоя выполнил обработчик заново
+type IdempotencyMiddleware struct {
+	cache    core.Cache
+	ttl      time.Duration
+	lockTTL  time.Duration
+	keyFunc  IdempotencyKeyFunc
+	priority int
+
+	mu       sync.Mutex
+	inflight map[string]*idempotentCall
+}
+
+// idempotentCall запрос, который сейчас обрабатывается
+type idempotentCall struct {
+	done     chan struct{}
+	response core.Response
+}
+
+// NewIdempotencyMiddleware создает middleware идемпотентности
+// ttl - сколько хранить ответ (0 - DefaultIdempotencyTTL)
+func NewIdempotencyMiddleware(cache core.Cache, ttl time.Duration, priority int) *IdempotencyMiddleware {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return &IdempotencyMiddleware{
+		cache:    cache,
+		ttl:      ttl,
+		lockTTL:  DefaultIdempotencyLockTTL,
+		keyFunc:  IdempotencyKeyFromContext,
+		priority: priority,
+		inflight: make(map[string]*idempotentCall),
+	}
+}
+
+// SetKeyFunc устанавливает функцию получения ключа
+func (m *IdempotencyMiddleware) SetKeyFunc(keyFunc IdempotencyKeyFunc) {
+	if keyFunc != nil {
+		m.keyFunc = keyFunc
+	}
+}
+
+// SetLockTTL устанавливает время блокировки на время обработки
+// Должно быть больше максимального времени выполнения обработчика
+func (m *IdempotencyMiddleware) SetLockTTL(ttl time.Duration) {
+	if ttl > 0 {
+		m.lockTTL = ttl
+	}
+}
+
+// Name возвращает имя
+func (m *IdempotencyMiddleware) Name() string {
+	return "idempotency"
+}
+
+// Priority возвращает приоритет
+func (m *IdempotencyMiddleware) Priority() int {
+	return m.priority
+}
+
+// Process обрабатывает запрос один раз, дубли получают сохраненный ответ
+func (m *IdempotencyMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
+	key := m.keyFunc(ctx)
+	if key == "" || m.cache == nil {
+		return next(ctx)
+	}
+
+	reqCtx := requestContext(ctx)
+	cacheKey := "idempotency:" + key
+
+	// Запрос уже обработан
+	if response, ok := m.load(reqCtx, cacheKey); ok {
+		ctx.Set(core.IdempotentReplayKey, true)
+		return response
+	}
+
+	// Запрос обрабатывается на этом экземпляре - ждем ответ
+	m.mu.Lock()
+	if call, ok := m.inflight[key]; ok {
+		m.mu.Unlock()
+		return m.wait(ctx, call)
+	}
+	call := &idempotentCall{done: make(chan struct{})}
+	m.inflight[key] = call
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		delete(m.inflight, key)
+		m.mu.Unlock()
+		close(call.done)
+	}()
+
+	// Запрос обрабатывается другим экземпляром
+	lockKey := cacheKey + ":lock"
+	if !m.lock(reqCtx, lockKey) {
+		ctx.Set(core.IdempotentReplayKey, true)
+		return core.NewSilentResponse()
+	}
+	defer m.cache.Delete(context.Background(), lockKey)
+
+	response := next(ctx)
+	call.response = response
+
+	// Ответ сохраняем даже если клиент отключился - он повторит запрос.
+	// Ответ с ошибкой не сохраняем: повтор должен выполнить обработчик заново.
+	// Потоковые ответы не сериализуются (core.ErrResponseNotSerializable)
+	if failed, ok := response.(core.ErrorResponse); ok && failed.Err() != nil {
+		return response
+	}
+	if data, err := core.MarshalResponse(response); err == nil {
+		_ = m.cache.Set(context.Background(), cacheKey, data, int(m.ttl.Seconds()))
+	}
+
+	return response
+}
+
+// wait ждет ответ на запрос, обрабатываемый параллельно
+func (m *IdempotencyMiddleware) wait(ctx core.UniversalContext, call *idempotentCall) core.Response {
+	ctx.Set(core.IdempotentReplayKey, true)
+
+	select {
+	case <-call.done:
+		if call.response == nil || call.response.Type() == core.ResponseTypeStream {
+			return core.NewSilentResponse()
+		}
+		return call.response
+	case <-requestContext(ctx).Done():
+		return core.NewSilentResponse()
+	}
+}
+
+// load читает сохраненный ответ
+func (m *IdempotencyMiddleware) load(ctx context.Context, cacheKey string) (core.Response, bool) {
+	value, err := m.cache.Get(ctx, cacheKey)
+	if err != nil {
+		return nil, false
+	}
+	return decodeResponse(value)
+}
+
+// lock захватывает блокировку обработки в кеше
+// С core.CounterCache захват атомарный, иначе - проверка и запись (best effort)
+func (m *IdempotencyMiddleware) lock(ctx context.Context, lockKey string) bool {
+	ttl := int(m.lockTTL.Seconds())
+
+	if counter, ok := m.cache.(core.CounterCache); ok {
+		n, err := counter.Increment(ctx, lockKey, 1, ttl)
+		if err != nil {
+			return true // fail open: лучше обработать дважды, чем потерять запрос
+		}
+		return n == 1
+	}
+
+	if exists, err := m.cache.Exists(ctx, lockKey); err == nil && exists {
+		return false
+	}
+	_ = m.cache.Set(ctx, lockKey, true, ttl)
+	return true
+}
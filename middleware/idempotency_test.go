@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// memoryCache core.Cache в памяти для тестов (без TTL)
+type memoryCache struct {
+	mu   sync.Mutex
+	data map[string]interface{}
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{data: make(map[string]interface{})}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.data[key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", key)
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+	return nil
+}
+
+func (c *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.data[key]
+	return ok, nil
+}
+
+func (c *memoryCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data = make(map[string]interface{})
+	return nil
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func(call int) core.Response
+		calls    int
+	}{
+		{
+			name:     "success is replayed",
+			response: func(call int) core.Response { return core.NewMessage(fmt.Sprint("done ", call)) },
+			calls:    1,
+		},
+		{
+			name: "failure is retried",
+			response: func(call int) core.Response {
+				return core.NewErrorResponse("try again", errors.New("payment gateway timeout"))
+			},
+			calls: 3,
+		},
+		{
+			name:     "error response without error is replayed",
+			response: func(call int) core.Response { return core.NewErrorResponse("not found", nil) },
+			calls:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := NewIdempotencyMiddleware(newMemoryCache(), 0, 5)
+
+			calls := 0
+			handler := func(ctx core.UniversalContext) core.Response {
+				calls++
+				return tt.response(calls)
+			}
+
+			var first string
+			for i := 0; i < 3; i++ {
+				ctx := core.NewBaseContext(context.Background())
+				ctx.Set(core.IdempotencyKey, "http:1:order-42")
+
+				text := mw.Process(ctx, handler).Content().Text
+				if i == 0 {
+					first = text
+				} else if tt.calls == 1 && text != first {
+					t.Fatalf("replay %d = %q, want %q", i, text, first)
+				}
+			}
+
+			if calls != tt.calls {
+				t.Fatalf("handler called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/di"
+)
+
+// DependencyScopeMiddleware открывает область зависимостей на время запроса
+//
+// Зависимости di.AsScoped (например, транзакция БД) создаются один раз на update
+// и освобождаются после ответа: di.Disposable получает ошибку запроса
+// (паника или core.ErrorResponse), поэтому транзакция откатывается при ошибке.
+// Обработчики получают зависимости через di.FromContext[T](ctx)
+type DependencyScopeMiddleware struct {
+	container *di.Container
+	logger    core.Logger
+	priority  int
+}
+
+// NewDependencyScopeMiddleware создает middleware области зависимостей запроса
+func NewDependencyScopeMiddleware(container *di.Container, logger core.Logger, priority int) *DependencyScopeMiddleware {
+	return &DependencyScopeMiddleware{
+		container: container,
+		logger:    logger,
+		priority:  priority,
+	}
+}
+
+// Name возвращает имя
+func (m *DependencyScopeMiddleware) Name() string {
+	return "dependency_scope"
+}
+
+// Priority возвращает приоритет
+func (m *DependencyScopeMiddleware) Priority() int {
+	return m.priority
+}
+
+// Process создает Scope запроса и освобождает его после обработки
+func (m *DependencyScopeMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) (response core.Response) {
+	scope := m.container.NewScope(requestContext(ctx))
+	ctx.Set(di.ScopeKey, scope)
+
+	defer func() {
+		var reqErr error
+		p := recover()
+
+		switch {
+		case p != nil:
+			reqErr = fmt.Errorf("panic: %v", p)
+		case response != nil:
+			if failed, ok := response.(core.ErrorResponse); ok {
+				reqErr = failed.Err()
+			}
+		}
+
+		if err := scope.Close(reqErr); err != nil && m.logger != nil {
+			m.logger.Error("Failed to close dependency scope", "error", err, "user_id", ctx.GetUserID())
+		}
+
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	return next(ctx)
+}
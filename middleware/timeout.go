@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// DefaultTimeoutMessage ответ по умолчанию при превышении времени обработки
+const DefaultTimeoutMessage = "⏱️ Время обработки запроса истекло. Попробуйте позже"
+
+// TimeoutFallbackFunc формирует ответ при превышении времени обработки
+type TimeoutFallbackFunc func(ctx core.UniversalContext) core.Response
+
+// TimeoutMiddleware ограничивает время выполнения обработчика
+//
+// Обработчик получает контекст с дедлайном через ctx.Context() и запускается
+// в отдельной горутине. Если дедлайн наступил раньше, чем обработчик вернул ответ,
+// пользователь получает fallback-ответ, а результат обработчика отбрасывается.
+// Обработчик должен сам завершаться по ctx.Context().Done() - принудительно
+// остановить горутину нельзя. Контекст core.BaseContext безопасно изменять
+// из опоздавшего обработчика: его параметры и значения защищены мьютексом.
+//
+// Если отменен родительский контекст (клиент отключился), возвращается тихий ответ
+type TimeoutMiddleware struct {
+	timeout  time.Duration
+	fallback TimeoutFallbackFunc
+	priority int
+}
+
+// NewTimeoutMiddleware создает middleware с таймаутом
+func NewTimeoutMiddleware(timeout time.Duration, priority int) *TimeoutMiddleware {
+	return &TimeoutMiddleware{
+		timeout: timeout,
+		fallback: func(ctx core.UniversalContext) core.Response {
+			return core.NewMessage(DefaultTimeoutMessage)
+		},
+		priority: priority,
+	}
+}
+
+// SetFallback устанавливает ответ при превышении времени обработки
+func (m *TimeoutMiddleware) SetFallback(fallback TimeoutFallbackFunc) {
+	if fallback != nil {
+		m.fallback = fallback
+	}
+}
+
+// Name возвращает имя
+func (m *TimeoutMiddleware) Name() string {
+	return "timeout"
+}
+
+// Priority возвращает приоритет
+func (m *TimeoutMiddleware) Priority() int {
+	return m.priority
+}
+
+// timeoutResult результат обработчика, запущенного в горутине
+type timeoutResult struct {
+	response core.Response
+	panicked interface{}
+}
+
+// Process запускает обработчик и ждет ответ не дольше таймаута
+func (m *TimeoutMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
+	if m.timeout <= 0 {
+		return next(ctx)
+	}
+
+	parent := requestContext(ctx)
+	reqCtx, cancel := context.WithTimeout(parent, m.timeout)
+	defer cancel()
+
+	ctx.WithContext(reqCtx)
+
+	done := make(chan timeoutResult, 1)
+	go func() {
+		var result timeoutResult
+		defer func() {
+			// Паника передается в вызывающую горутину, чтобы ее обработал RecoveryMiddleware
+			if r := recover(); r != nil {
+				result.panicked = r
+			}
+			done <- result
+		}()
+
+		result.response = next(ctx)
+	}()
+
+	select {
+	case result := <-done:
+		ctx.WithContext(parent)
+		if result.panicked != nil {
+			panic(result.panicked)
+		}
+		return result.response
+
+	case <-reqCtx.Done():
+		// Контекст не восстанавливаем: обработчик еще может его читать
+		if parent.Err() != nil {
+			return core.NewSilentResponse()
+		}
+		return m.fallback(ctx)
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+func TestTimeoutMiddlewareFallback(t *testing.T) {
+	mw := NewTimeoutMiddleware(20*time.Millisecond, 65)
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	ctx := core.NewBaseContext(context.Background())
+
+	response := mw.Process(ctx, func(c core.UniversalContext) core.Response {
+		defer close(finished)
+		<-release
+		// Обработчик продолжает менять контекст после таймаута
+		for i := 0; i < 100; i++ {
+			c.Set("key", i)
+			c.SetParam("param", i)
+			c.WithContext(context.Background())
+		}
+		return core.NewMessage("late")
+	})
+
+	if response == nil || response.Content().Text != DefaultTimeoutMessage {
+		t.Fatalf("expected timeout fallback, got %+v", response)
+	}
+
+	close(release)
+	for i := 0; i < 100; i++ {
+		ctx.Set("key", -i)
+		ctx.GetParam("param")
+		_ = ctx.Context()
+	}
+	<-finished
+}
+
+func TestTimeoutMiddlewarePassesResponse(t *testing.T) {
+	mw := NewTimeoutMiddleware(time.Second, 65)
+	ctx := core.NewBaseContext(context.Background())
+
+	response := mw.Process(ctx, func(c core.UniversalContext) core.Response {
+		if _, ok := c.Context().Deadline(); !ok {
+			t.Error("handler context has no deadline")
+		}
+		return core.NewMessage("ok")
+	})
+
+	if response.Content().Text != "ok" {
+		t.Fatalf("expected handler response, got %q", response.Content().Text)
+	}
+	if _, ok := ctx.Context().Deadline(); ok {
+		t.Fatal("parent context was not restored")
+	}
+}
@@ -0,0 +1,191 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// ResponseCacheKey ключ значения (ctx.Get), под которым middleware кеширования
+// передает хранилище ответов маршрутам с CachePolicy
+const ResponseCacheKey = "response_cache"
+
+// CacheKeyPart часть ключа кеша ответа
+type CacheKeyPart string
+
+const (
+	// CacheKeyParams параметры маршрута ({id}, {name}, ...)
+	CacheKeyParams CacheKeyPart = "params"
+
+	// CacheKeyUser пользователь
+	CacheKeyUser CacheKeyPart = "user"
+
+	// CacheKeyChat чат
+	CacheKeyChat CacheKeyPart = "chat"
+
+	// CacheKeyLocale локаль пользователя
+	CacheKeyLocale CacheKeyPart = "locale"
+)
+
+// CachePolicy политика кеширования ответа маршрута
+type CachePolicy struct {
+	// TTL время жизни ответа
+	TTL time.Duration
+
+	// KeyBy из чего строится ключ (по умолчанию - параметры маршрута)
+	// Без Shared к ключу всегда добавляются пользователь и чат
+	KeyBy []CacheKeyPart
+
+	// Shared один ответ для всех пользователей и чатов (рейтинги, справка)
+	// Включайте только для ответов без персональных данных
+	Shared bool
+
+	// Key собственная функция ключа (заменяет KeyBy)
+	Key func(ctx core.UniversalContext) string
+
+	// Callbacks кешировать и нажатия кнопок (по умолчанию callback запросы идут мимо кеша)
+	Callbacks bool
+
+	// Invalidate маршрут изменяет состояние: ответ не кешируется,
+	// после выполнения кеш модуля сбрасывается
+	Invalidate bool
+}
+
+// ResponseCache хранилище ответов маршрутов
+// Реализуется middleware.CachingMiddleware
+type ResponseCache interface {
+	// Lookup возвращает сохраненный ответ
+	Lookup(ctx core.UniversalContext, module, key string) (core.Response, bool)
+
+	// Store сохраняет ответ
+	Store(ctx core.UniversalContext, module, key string, ttl time.Duration, response core.Response)
+
+	// Invalidate сбрасывает все ответы модуля
+	Invalidate(ctx core.UniversalContext, module string)
+}
+
+// key строит ключ кеша ответа маршрута
+func (p *CachePolicy) key(ctx core.UniversalContext, r *RoutePattern) string {
+	route := r.routeName()
+	if p.Key != nil {
+		return route + "|" + p.Key(ctx)
+	}
+
+	parts := p.KeyBy
+	if len(parts) == 0 {
+		parts = []CacheKeyPart{CacheKeyParams}
+	}
+	if !p.Shared {
+		parts = withCacheScope(parts)
+	}
+
+	var b strings.Builder
+	b.WriteString(route)
+	for _, part := range parts {
+		b.WriteString("|")
+		switch part {
+		case CacheKeyParams:
+			b.WriteString(r.paramsKey(ctx))
+		case CacheKeyUser:
+			fmt.Fprintf(&b, "u%d", ctx.GetUserID())
+		case CacheKeyChat:
+			fmt.Fprintf(&b, "c%d", ctx.GetChatID())
+		case CacheKeyLocale:
+			b.WriteString(ctx.GetLocale())
+		}
+	}
+
+	return b.String()
+}
+
+// withCacheScope добавляет к частям ключа пользователя и чат, если их нет
+func withCacheScope(parts []CacheKeyPart) []CacheKeyPart {
+	scoped := append([]CacheKeyPart(nil), parts...)
+	for _, scope := range []CacheKeyPart{CacheKeyUser, CacheKeyChat} {
+		found := false
+		for _, part := range parts {
+			if part == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			scoped = append(scoped, scope)
+		}
+	}
+	return scoped
+}
+
+// paramsKey параметры маршрута в стабильном порядке
+func (r *RoutePattern) paramsKey(ctx core.UniversalContext) string {
+	names := []string{"_pattern"}
+	for _, re := range r.compiled {
+		for _, name := range re.SubexpNames() {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	last := ""
+	for _, name := range names {
+		if name == last {
+			continue
+		}
+		last = name
+		if value, ok := ctx.GetParam(name); ok {
+			fmt.Fprintf(&b, "%s=%v;", name, value)
+		}
+	}
+	return b.String()
+}
+
+// cached выполняет обработчик с учетом политики кеширования
+func (r *RoutePattern) cached(ctx core.UniversalContext) core.Response {
+	policy := r.Cache
+
+	value, _ := ctx.Get(ResponseCacheKey)
+	cache, ok := value.(ResponseCache)
+	if !ok {
+		return r.Handler(ctx)
+	}
+
+	// Изменяющий маршрут сбрасывает кеш модуля
+	if policy.Invalidate {
+		response := r.Handler(ctx)
+		cache.Invalidate(ctx, r.Module)
+		return response
+	}
+
+	if ctx.IsCallback() && !policy.Callbacks {
+		return r.Handler(ctx)
+	}
+
+	key := policy.key(ctx, r)
+	if response, ok := cache.Lookup(ctx, r.Module, key); ok {
+		return response
+	}
+
+	response := r.Handler(ctx)
+	if cacheable(response) {
+		cache.Store(ctx, r.Module, key, policy.TTL, response)
+	}
+	return response
+}
+
+// cacheable проверяет, можно ли сохранить ответ
+// Потоки, перенаправления и ответы с ошибкой не кешируются
+func cacheable(response core.Response) bool {
+	if response == nil || response.Type() == core.ResponseTypeStream || response.Type() == core.ResponseTypeRedirect {
+		return false
+	}
+	if failed, ok := response.(core.ErrorResponse); ok && failed.Err() != nil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+func cacheContext(userID, chatID int64, id string) core.UniversalContext {
+	ctx := core.NewBaseContext(context.Background())
+	ctx.SetUserID(userID)
+	ctx.SetChatID(chatID)
+	ctx.SetParam("_pattern", "/profile {id}")
+	ctx.SetParam("id", id)
+	return ctx
+}
+
+func TestCachePolicyKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   *RouteBuilder
+		a, b    core.UniversalContext
+		sameKey bool
+	}{
+		{
+			name:    "default is per user",
+			route:   NewRoute("/profile {id}").Cache(time.Minute),
+			a:       cacheContext(1, 1, "x"),
+			b:       cacheContext(2, 2, "x"),
+			sameKey: false,
+		},
+		{
+			name:    "default is per chat",
+			route:   NewRoute("/profile {id}").Cache(time.Minute),
+			a:       cacheContext(1, 10, "x"),
+			b:       cacheContext(1, 20, "x"),
+			sameKey: false,
+		},
+		{
+			name:    "explicit parts stay per user",
+			route:   NewRoute("/profile {id}").Cache(time.Minute).CacheKey(CacheKeyParams, CacheKeyLocale),
+			a:       cacheContext(1, 1, "x"),
+			b:       cacheContext(2, 2, "x"),
+			sameKey: false,
+		},
+		{
+			name:    "same user and params",
+			route:   NewRoute("/profile {id}").Cache(time.Minute),
+			a:       cacheContext(1, 1, "x"),
+			b:       cacheContext(1, 1, "x"),
+			sameKey: true,
+		},
+		{
+			name:    "params differ",
+			route:   NewRoute("/profile {id}").Cache(time.Minute).CacheShared(),
+			a:       cacheContext(1, 1, "x"),
+			b:       cacheContext(1, 1, "y"),
+			sameKey: false,
+		},
+		{
+			name:    "shared across users",
+			route:   NewRoute("/profile {id}").Cache(time.Minute).CacheShared(),
+			a:       cacheContext(1, 1, "x"),
+			b:       cacheContext(2, 2, "x"),
+			sameKey: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := tt.route.Build()
+			if err := route.Compile(); err != nil {
+				t.Fatal(err)
+			}
+
+			keyA := route.Cache.key(tt.a, &route)
+			keyB := route.Cache.key(tt.b, &route)
+			if (keyA == keyB) != tt.sameKey {
+				t.Fatalf("keys %q and %q: same = %v, want %v", keyA, keyB, keyA == keyB, tt.sameKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+var (
+	// ErrDependencyMissing обязательная зависимость не зарегистрирована
+	ErrDependencyMissing = errors.New("module dependency missing")
+
+	// ErrDependencyVersion версия зависимости не подходит под ограничение
+	ErrDependencyVersion = errors.New("module dependency version mismatch")
+
+	// ErrDependencyCycle циклическая зависимость модулей
+	ErrDependencyCycle = errors.New("module dependency cycle")
+
+	// ErrModuleRequired модуль нельзя выгрузить - от него зависят другие
+	ErrModuleRequired = errors.New("module is required by other modules")
+)
+
+// DependencyCycleError циклическая зависимость модулей
+type DependencyCycleError struct {
+	// Cycle модули цикла, первый повторяется в конце: a -> b -> a
+	Cycle []string
+}
+
+// Error возвращает текст ошибки
+func (e *DependencyCycleError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDependencyCycle, strings.Join(e.Cycle, " -> "))
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrDependencyCycle)
+func (e *DependencyCycleError) Unwrap() error {
+	return ErrDependencyCycle
+}
+
+// dependenciesOf возвращает зависимости модуля
+func dependenciesOf(module core.Module) []core.ModuleDependency {
+	if dependent, ok := module.(core.DependentModule); ok {
+		return dependent.DependsOn()
+	}
+	return nil
+}
+
+// checkDependency проверяет, что зависимость есть и подходит по версии
+func checkDependency(module string, dep core.ModuleDependency, modules map[string]core.Module) error {
+	target, ok := modules[dep.Name]
+	if !ok {
+		if dep.Optional {
+			return nil
+		}
+		return fmt.Errorf("%w: %s requires %s", ErrDependencyMissing, module, dep.Name)
+	}
+
+	if dep.Version == "" {
+		return nil
+	}
+
+	matched, err := MatchVersion(target.Version(), dep.Version)
+	if err != nil {
+		return fmt.Errorf("module %s: invalid version constraint for %s: %w", module, dep.Name, err)
+	}
+	if !matched {
+		return fmt.Errorf("%w: %s requires %s %s, found %s",
+			ErrDependencyVersion, module, dep.Name, dep.Version, target.Version())
+	}
+
+	return nil
+}
+
+// moduleOrder проверяет зависимости и возвращает порядок запуска модулей
+// Зависимости идут раньше зависящих от них модулей, независимые - по имени
+func moduleOrder(modules map[string]core.Module) ([]string, error) {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// dependents[a] - модули, которые нужно запускать после a
+	dependents := make(map[string][]string, len(modules))
+	indegree := make(map[string]int, len(modules))
+
+	for _, name := range names {
+		for _, dep := range dependenciesOf(modules[name]) {
+			if dep.Name == name {
+				return nil, &DependencyCycleError{Cycle: []string{name, name}}
+			}
+			if err := checkDependency(name, dep, modules); err != nil {
+				return nil, err
+			}
+			if _, ok := modules[dep.Name]; !ok {
+				continue
+			}
+			dependents[dep.Name] = append(dependents[dep.Name], name)
+			indegree[name]++
+		}
+	}
+
+	// Алгоритм Кана
+	ready := make([]string, 0, len(names))
+	for _, name := range names {
+		if indegree[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := make([]string, 0, len(names))
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, next := range dependents[name] {
+			indegree[next]--
+			if indegree[next] == 0 {
+				ready = append(ready, next)
+				sort.Strings(ready)
+			}
+		}
+	}
+
+	if len(order) < len(names) {
+		return nil, &DependencyCycleError{Cycle: findCycle(names, modules, indegree)}
+	}
+
+	return order, nil
+}
+
+// findCycle находит цикл среди модулей, оставшихся после сортировки
+func findCycle(names []string, modules map[string]core.Module, indegree map[string]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	color := make(map[string]int, len(names))
+	stack := make([]string, 0)
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		color[name] = visiting
+		stack = append(stack, name)
+
+		for _, dep := range dependenciesOf(modules[name]) {
+			if _, ok := modules[dep.Name]; !ok {
+				continue
+			}
+			switch color[dep.Name] {
+			case visiting:
+				for i, n := range stack {
+					if n == dep.Name {
+						cycle := append([]string{}, stack[i:]...)
+						return append(cycle, dep.Name)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep.Name); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		color[name] = done
+		return nil
+	}
+
+	for _, name := range names {
+		if indegree[name] > 0 && color[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// requiredBy возвращает модули, для которых name - обязательная зависимость
+func requiredBy(name string, modules map[string]core.Module) []string {
+	var result []string
+	for other, module := range modules {
+		if other == name {
+			continue
+		}
+		for _, dep := range dependenciesOf(module) {
+			if dep.Name == name && !dep.Optional {
+				result = append(result, other)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// semver версия major.minor.patch (пре-релиз и метаданные сборки не учитываются)
+type semver [3]int
+
+// compare сравнивает версии (-1, 0, 1)
+func (v semver) compare(other semver) int {
+	for i := range v {
+		switch {
+		case v[i] < other[i]:
+			return -1
+		case v[i] > other[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseVersion разбирает версию "v1.2.3", "1.2", "1.x"
+// Возвращает версию и количество явно заданных частей (x и * не считаются)
+func parseVersion(s string) (semver, int, error) {
+	var v semver
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "" {
+		return v, 0, fmt.Errorf("empty version")
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("invalid version %q", s)
+	}
+
+	specified := 0
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, 0, fmt.Errorf("invalid version %q", s)
+		}
+		v[i] = n
+		specified++
+	}
+
+	return v, specified, nil
+}
+
+// MatchVersion проверяет версию на соответствие ограничению
+//
+// Поддерживаются операторы =, !=, >, >=, <, <=, ^ (совместимые изменения),
+// ~ (только патчи), шаблоны "1.x" и "1.2.*", несколько условий через запятую
+// или пробел (все должны выполняться) и альтернативы через "||"
+func MatchVersion(version, constraint string) (bool, error) {
+	v, _, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	for _, alternative := range strings.Split(constraint, "||") {
+		matched, err := matchAll(v, alternative)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchAll проверяет все условия альтернативы
+func matchAll(v semver, alternative string) (bool, error) {
+	fields := strings.FieldsFunc(alternative, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+
+	// ">= 1.2" - оператор отдельно от версии
+	terms := make([]string, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		term := fields[i]
+		if strings.Trim(term, "<>=!^~") == "" && i+1 < len(fields) {
+			term += fields[i+1]
+			i++
+		}
+		terms = append(terms, term)
+	}
+
+	for _, term := range terms {
+		matched, err := matchTerm(v, term)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchTerm проверяет одно условие
+func matchTerm(v semver, term string) (bool, error) {
+	if term == "" || term == "*" || term == "x" {
+		return true, nil
+	}
+
+	op := term[:len(term)-len(strings.TrimLeft(term, "<>=!^~"))]
+	target, specified, err := parseVersion(term[len(op):])
+	if err != nil {
+		return false, err
+	}
+
+	cmp := v.compare(target)
+	switch op {
+	case "", "=", "==":
+		if op == "" && specified < 3 {
+			return matchPrefix(v, target, specified), nil
+		}
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case "^":
+		return cmp >= 0 && v.compare(caretUpper(target, specified)) < 0, nil
+	case "~":
+		return cmp >= 0 && v.compare(tildeUpper(target, specified)) < 0, nil
+	default:
+		return false, fmt.Errorf("unknown version operator %q", op)
+	}
+}
+
+// matchPrefix сравнивает только явно заданные части ("1.2" == "1.2.x")
+func matchPrefix(v, target semver, specified int) bool {
+	for i := 0; i < specified; i++ {
+		if v[i] != target[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// caretUpper верхняя граница для ^: не меняется первая ненулевая часть
+func caretUpper(v semver, specified int) semver {
+	switch {
+	case v[0] > 0 || specified <= 1:
+		return semver{v[0] + 1, 0, 0}
+	case v[1] > 0 || specified == 2:
+		return semver{0, v[1] + 1, 0}
+	default:
+		return semver{0, 0, v[2] + 1}
+	}
+}
+
+// tildeUpper верхняя граница для ~: меняется только патч ("~1" - любая 1.x)
+func tildeUpper(v semver, specified int) semver {
+	if specified <= 1 {
+		return semver{v[0] + 1, 0, 0}
+	}
+	return semver{v[0], v[1] + 1, 0}
+}
@@ -0,0 +1,242 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// dependentModule модуль с версией и зависимостями
+type dependentModule struct {
+	name    string
+	version string
+	deps    []core.ModuleDependency
+}
+
+func (m *dependentModule) Name() string                       { return m.name }
+func (m *dependentModule) Version() string                    { return m.version }
+func (m *dependentModule) Routes() []core.RoutePattern        { return nil }
+func (m *dependentModule) Init(deps core.Dependencies) error  { return nil }
+func (m *dependentModule) Start(ctx context.Context) error    { return nil }
+func (m *dependentModule) Stop(ctx context.Context) error     { return nil }
+func (m *dependentModule) DependsOn() []core.ModuleDependency { return m.deps }
+
+// modulesOf собирает модули по имени
+func modulesOf(list ...*dependentModule) map[string]core.Module {
+	modules := make(map[string]core.Module, len(list))
+	for _, module := range list {
+		if module.version == "" {
+			module.version = "1.0.0"
+		}
+		modules[module.name] = module
+	}
+	return modules
+}
+
+// requires зависимость от модуля с ограничением версии
+func requires(name, version string) core.ModuleDependency {
+	return core.ModuleDependency{Name: name, Version: version}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+		wantErr    bool
+	}{
+		{version: "1.2.3", constraint: "", want: true},
+		{version: "1.2.3", constraint: "*", want: true},
+		{version: "1.2.3", constraint: "1.2.3", want: true},
+		{version: "v1.2.3", constraint: "=1.2.3", want: true},
+		{version: "1.2.4", constraint: "1.2.3", want: false},
+		{version: "1.2.3", constraint: "!=1.2.3", want: false},
+		{version: "1.2.3", constraint: ">1.2.2", want: true},
+		{version: "1.2.3", constraint: ">1.2.3", want: false},
+		{version: "1.2.3", constraint: ">=1.2.3", want: true},
+		{version: "1.2.3", constraint: "<1.2.3", want: false},
+		{version: "1.2.3", constraint: "<=1.2.3", want: true},
+		{version: "1.2.3-beta+build", constraint: "1.2.3", want: true},
+
+		// Шаблоны
+		{version: "1.9.0", constraint: "1.x", want: true},
+		{version: "2.0.0", constraint: "1.x", want: false},
+		{version: "1.2.7", constraint: "1.2.*", want: true},
+		{version: "1.3.0", constraint: "1.2", want: false},
+
+		// ^ не меняет первую ненулевую часть
+		{version: "1.9.9", constraint: "^1.2", want: true},
+		{version: "1.1.0", constraint: "^1.2", want: false},
+		{version: "2.0.0", constraint: "^1.2", want: false},
+		{version: "0.2.9", constraint: "^0.2.3", want: true},
+		{version: "0.3.0", constraint: "^0.2.3", want: false},
+		{version: "0.0.3", constraint: "^0.0.3", want: true},
+		{version: "0.0.4", constraint: "^0.0.3", want: false},
+		{version: "0.9.0", constraint: "^0", want: true},
+
+		// ~ меняет только патч
+		{version: "1.2.9", constraint: "~1.2.3", want: true},
+		{version: "1.3.0", constraint: "~1.2.3", want: false},
+		{version: "1.9.0", constraint: "~1", want: true},
+
+		// Несколько условий и альтернативы
+		{version: "1.5.0", constraint: ">=1.2, <2.0", want: true},
+		{version: "2.0.0", constraint: ">=1.2, <2.0", want: false},
+		{version: "1.5.0", constraint: ">= 1.2 < 2", want: true},
+		{version: "3.1.0", constraint: "^1.2 || ^3.0", want: true},
+		{version: "2.1.0", constraint: "^1.2 || ^3.0", want: false},
+
+		// Ошибки
+		{version: "abc", constraint: "1.0", wantErr: true},
+		{version: "1.0.0", constraint: ">=1.a", wantErr: true},
+		{version: "1.0.0", constraint: "1.2.3.4", wantErr: true},
+		{version: "1.0.0", constraint: "=>1.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			got, err := MatchVersion(tt.version, tt.constraint)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MatchVersion(%q, %q) = %v, want error", tt.version, tt.constraint, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("MatchVersion(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModuleOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules map[string]core.Module
+		want    string
+		wantErr error
+		cycle   string
+	}{
+		{
+			name:    "independent modules by name",
+			modules: modulesOf(&dependentModule{name: "shop"}, &dependentModule{name: "arena"}, &dependentModule{name: "chat"}),
+			want:    "arena,chat,shop",
+		},
+		{
+			name: "dependencies first",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{requires("users", ""), requires("economy", "")}},
+				&dependentModule{name: "economy", deps: []core.ModuleDependency{requires("users", "")}},
+				&dependentModule{name: "users"},
+				&dependentModule{name: "admin"},
+			),
+			want: "admin,users,economy,arena",
+		},
+		{
+			name: "optional dependency present",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{{Name: "stats", Optional: true}}},
+				&dependentModule{name: "stats"},
+			),
+			want: "stats,arena",
+		},
+		{
+			name: "optional dependency missing",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{{Name: "stats", Optional: true}}},
+			),
+			want: "arena",
+		},
+		{
+			name: "version satisfied",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{requires("users", "^1.2")}},
+				&dependentModule{name: "users", version: "1.4.0"},
+			),
+			want: "users,arena",
+		},
+		{
+			name: "missing dependency",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{requires("users", "")}},
+			),
+			wantErr: ErrDependencyMissing,
+		},
+		{
+			name: "version mismatch",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{requires("users", "^2.0")}},
+				&dependentModule{name: "users", version: "1.4.0"},
+			),
+			wantErr: ErrDependencyVersion,
+		},
+		{
+			name: "self dependency",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{requires("arena", "")}},
+			),
+			wantErr: ErrDependencyCycle,
+			cycle:   "arena,arena",
+		},
+		{
+			name: "cycle",
+			modules: modulesOf(
+				&dependentModule{name: "arena", deps: []core.ModuleDependency{requires("economy", "")}},
+				&dependentModule{name: "economy", deps: []core.ModuleDependency{requires("shop", "")}},
+				&dependentModule{name: "shop", deps: []core.ModuleDependency{requires("arena", "")}},
+				&dependentModule{name: "users"},
+			),
+			wantErr: ErrDependencyCycle,
+			cycle:   "arena,economy,shop,arena",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := moduleOrder(tt.modules)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if tt.cycle != "" {
+					var cycleErr *DependencyCycleError
+					if !errors.As(err, &cycleErr) {
+						t.Fatalf("err = %T, want *DependencyCycleError", err)
+					}
+					if got := strings.Join(cycleErr.Cycle, ","); got != tt.cycle {
+						t.Fatalf("cycle = %s, want %s", got, tt.cycle)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(order, ","); got != tt.want {
+				t.Fatalf("order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredBy(t *testing.T) {
+	modules := modulesOf(
+		&dependentModule{name: "arena", deps: []core.ModuleDependency{requires("users", "")}},
+		&dependentModule{name: "shop", deps: []core.ModuleDependency{requires("users", "")}},
+		&dependentModule{name: "stats", deps: []core.ModuleDependency{{Name: "users", Optional: true}}},
+		&dependentModule{name: "users"},
+	)
+
+	if got := strings.Join(requiredBy("users", modules), ","); got != "arena,shop" {
+		t.Fatalf("requiredBy(users) = %s, want arena,shop", got)
+	}
+	if got := requiredBy("arena", modules); len(got) != 0 {
+		t.Fatalf("requiredBy(arena) = %v, want none", got)
+	}
+}
@@ -0,0 +1,93 @@
+package routing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/tracing"
+)
+
+// execute выполняет обработчик маршрута модуля: span обработчика, метрики
+// и событие command.executed (в том числе при панике, которая пробрасывается дальше)
+func (r *Router) execute(ctx core.UniversalContext, tracer *tracing.Tracer, metrics core.Metrics, module core.Module, route string, handler core.HandlerFunc) (response core.Response) {
+	name := module.Name()
+	span, end := startSpan(tracer, ctx, "handler "+name,
+		tracing.Attr("module", name),
+		tracing.Attr("route", route),
+	)
+	defer end()
+
+	start := time.Now()
+	defer func() {
+		p := recover()
+		latency := time.Since(start)
+
+		var err error
+		if failed, ok := response.(core.ErrorResponse); ok && p == nil {
+			err = failed.Err()
+		}
+		if p != nil {
+			span.SetStatus(tracing.StatusError, "panic")
+		} else {
+			span.RecordError(err)
+		}
+
+		observeRoute(metrics, name, route, start)
+		r.publishExecuted(ctx, name, route, latency, p == nil && err == nil, err)
+
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	return r.guard(ctx, module, handler)
+}
+
+// publishExecuted публикует событие command.executed (после запуска роутера)
+func (r *Router) publishExecuted(ctx core.UniversalContext, module, route string, latency time.Duration, success bool, err error) {
+	if r.eventBus == nil {
+		return
+	}
+
+	r.mu.RLock()
+	started := r.started
+	r.mu.RUnlock()
+	if !started {
+		return
+	}
+
+	event := events.NewCommandExecutedEvent(ctx.GetUserID(), commandOf(ctx, route), module, success)
+	event.Route = route
+	event.Latency = latency
+	event.Error = err
+	event.SetChatID(ctx.GetChatID())
+	event.SetData("module", module).
+		SetData("route", route).
+		SetData("command", event.Command).
+		SetData("success", success).
+		SetData("latency_ms", float64(latency.Microseconds())/1000).
+		SetData("source", ctx.GetSource())
+	if err != nil {
+		event.SetData("error", err.Error())
+	}
+	if id := requestID(ctx); id != "" {
+		event.SetData("request_id", id)
+	}
+
+	r.eventBus.PublishAsync(context.WithoutCancel(requestContext(ctx)), event)
+}
+
+// commandOf возвращает команду запроса ("/start") или маршрут для остальных сообщений
+// Текст сообщений в событие не попадает
+func commandOf(ctx core.UniversalContext, route string) string {
+	if ctx.IsCommand() {
+		if fields := strings.Fields(ctx.GetText()); len(fields) > 0 {
+			command, _, _ := strings.Cut(fields[0], "@")
+			return command
+		}
+	}
+	return route
+}
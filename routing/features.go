@@ -0,0 +1,66 @@
+package routing
+
+import (
+	"github.com/andranikuz/botkit/core"
+)
+
+// FeatureGate флаг функциональности маршрута
+type FeatureGate struct {
+	// Flag имя флага
+	Flag string
+
+	// Fallback обработчик для пользователей с выключенным флагом
+	// nil - маршрут пропускается, и запрос обрабатывает следующий подходящий маршрут
+	Fallback core.HandlerFunc
+}
+
+// SetFeatureFlags устанавливает источник флагов функциональности
+// Без источника все маршруты и модули за флагами считаются выключенными
+func (r *Router) SetFeatureFlags(features core.FeatureFlags) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.features = features
+}
+
+// FeatureEnabled проверяет флаг для запроса
+func (r *Router) FeatureEnabled(ctx core.UniversalContext, flag string) bool {
+	r.mu.RLock()
+	features := r.features
+	r.mu.RUnlock()
+
+	return featureEnabled(features, ctx, flag)
+}
+
+// gateRoute проверяет флаги модуля и маршрута
+// Возвращает false, если маршрут нужно пропустить, и запасной обработчик,
+// если флаг маршрута выключен, но задан FeatureFallback
+func gateRoute(features core.FeatureFlags, ctx core.UniversalContext, route *compiledRoute) (core.HandlerFunc, bool) {
+	if !featureEnabled(features, ctx, route.state.feature) {
+		return nil, false
+	}
+
+	gate := route.pattern.Feature
+	if gate == nil || featureEnabled(features, ctx, gate.Flag) {
+		return nil, true
+	}
+	if gate.Fallback != nil {
+		return gate.Fallback, true
+	}
+	return nil, false
+}
+
+// featureEnabled проверяет флаг (пустой флаг всегда включен)
+func featureEnabled(features core.FeatureFlags, ctx core.UniversalContext, flag string) bool {
+	if flag == "" {
+		return true
+	}
+	return features != nil && features.Enabled(ctx, flag)
+}
+
+// featureOf возвращает флаг модуля (core.FeatureGatedModule)
+func featureOf(module core.Module) string {
+	if gated, ok := module.(core.FeatureGatedModule); ok {
+		return gated.FeatureFlag()
+	}
+	return ""
+}
@@ -0,0 +1,229 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+var _ core.Introspector = (*Router)(nil)
+
+// Introspect описывает работающего бота: модули с маршрутами (и RouteMeta),
+// подписками на события, HTTP API, зависимостями и здоровьем, а также цепочку middleware
+//
+// Здоровье - результат последней проверки Health (фоновой или probe), описание
+// не запускает проверку и не публикует module.health. Если проверок еще не было,
+// модули проверяются без записи результата
+func (r *Router) Introspect(ctx context.Context) core.Introspection {
+	health, ok := r.lastHealth()
+	if !ok {
+		health = r.checkHealth(ctx)
+	}
+
+	r.mu.RLock()
+	states := make([]*moduleState, 0, len(r.states))
+	for _, state := range r.states {
+		states = append(states, state)
+	}
+	routes := r.routes
+	middlewares := r.middlewares
+	r.mu.RUnlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].module.Name() < states[j].module.Name()
+	})
+
+	modules := make([]core.ModuleInfo, 0, len(states))
+	for _, state := range states {
+		info := describeModule(state, routes)
+		info.Health = health.Modules[info.Name]
+		modules = append(modules, info)
+	}
+
+	return core.Introspection{
+		Modules:     modules,
+		Middlewares: describeMiddlewares(middlewares),
+		Health:      health,
+		Time:        time.Now().Unix(),
+	}
+}
+
+// DescribeModule описывает один модуль (без проверки здоровья)
+func (r *Router) DescribeModule(name string) (core.ModuleInfo, bool) {
+	r.mu.RLock()
+	state, ok := r.states[name]
+	routes := r.routes
+	r.mu.RUnlock()
+
+	if !ok {
+		return core.ModuleInfo{}, false
+	}
+	return describeModule(state, routes), true
+}
+
+// ModuleHealth проверяет здоровье одного модуля с тем же таймаутом, что и Health
+// Статус не записывается в историю и не публикует module.health
+func (r *Router) ModuleHealth(ctx context.Context, name string) (core.HealthStatus, bool) {
+	r.mu.RLock()
+	module, ok := r.modules[name]
+	r.mu.RUnlock()
+	if !ok {
+		return core.HealthStatus{}, false
+	}
+
+	r.health.mu.Lock()
+	timeout := r.health.timeout
+	r.health.mu.Unlock()
+
+	status, _ := r.moduleHealth(ctx, module, timeout)
+	return status, true
+}
+
+// Middlewares возвращает цепочку middleware в порядке выполнения
+func (r *Router) Middlewares() []core.MiddlewareInfo {
+	r.mu.RLock()
+	middlewares := r.middlewares
+	r.mu.RUnlock()
+
+	return describeMiddlewares(middlewares)
+}
+
+// describeModule строит описание модуля по его состоянию и таблице маршрутов
+func describeModule(state *moduleState, routes []compiledRoute) core.ModuleInfo {
+	module := state.module
+	name := module.Name()
+
+	info := core.ModuleInfo{
+		Name:    name,
+		Version: module.Version(),
+		Routes:  make([]core.RouteDescription, 0),
+		Events:  make([]core.EventInfo, 0),
+		API:     make([]core.APIInfo, 0),
+	}
+
+	if provider, ok := module.(core.MetadataProvider); ok {
+		metadata := provider.Metadata()
+		info.Metadata = &metadata
+		info.Description = metadata.Description
+	}
+
+	if wc, ok := module.(core.WildcardModule); ok && state.wildcard {
+		info.Kinds = append(info.Kinds, "wildcard")
+		priority := wc.Priority()
+		info.WildcardPriority = &priority
+	}
+
+	if apiModule, ok := module.(core.APIModule); ok {
+		info.Kinds = append(info.Kinds, "api")
+		for _, handler := range apiModule.APIHandlers() {
+			info.API = append(info.API, core.APIInfo{
+				Method:      handler.Method,
+				Path:        "/api/v1/" + name + handler.Path,
+				Description: handler.Description,
+				Admin:       handler.Admin,
+			})
+		}
+	}
+
+	if eventAware, ok := module.(core.EventAwareModule); ok {
+		info.Kinds = append(info.Kinds, "event_aware")
+		for _, sub := range eventAware.Events() {
+			info.Events = append(info.Events, core.EventInfo{
+				EventType: sub.EventType,
+				Priority:  sub.Priority,
+				Filtered:  sub.Filter != nil,
+			})
+		}
+	}
+
+	if _, ok := module.(core.ConfigurableModule); ok {
+		info.Kinds = append(info.Kinds, "configurable")
+	}
+
+	if _, ok := module.(core.Lifecycle); ok {
+		info.Kinds = append(info.Kinds, "lifecycle")
+	}
+
+	info.Feature = state.feature
+
+	if deps := dependenciesOf(module); len(deps) > 0 {
+		info.Kinds = append(info.Kinds, "dependent")
+		info.Dependencies = deps
+	}
+
+	if len(info.Kinds) == 0 {
+		info.Kinds = []string{"standard"}
+	}
+
+	// Маршруты в порядке проверки роутером (по приоритету)
+	for _, route := range routes {
+		if route.state == state {
+			info.Routes = append(info.Routes, describeRoute(&route.pattern))
+		}
+	}
+
+	return info
+}
+
+// describeRoute строит описание маршрута
+func describeRoute(pattern *RoutePattern) core.RouteDescription {
+	meta := pattern.Meta
+	security := pattern.Security
+
+	desc := core.RouteDescription{
+		Patterns:    pattern.Patterns,
+		Type:        string(pattern.Type),
+		Priority:    pattern.Priority,
+		Name:        meta.Name,
+		Description: meta.Description,
+		Category:    meta.Category,
+		Tags:        meta.Tags,
+		Hidden:      meta.Hidden,
+		Deprecated:  meta.Deprecated,
+		Version:     meta.Version,
+		Examples:    meta.Examples,
+		Security: core.RouteSecurityInfo{
+			RequireAuth:        security.RequireAuth,
+			RequireRoles:       security.RequireRoles,
+			RequirePermissions: security.RequirePermissions,
+			AllowedSources:     security.AllowedSources,
+			AllowedChatTypes:   security.AllowedChatTypes,
+			RequireChatAdmin:   security.RequireChatAdmin,
+			RequireAddressed:   security.RequireAddressed,
+		},
+	}
+
+	if limit := security.RateLimit; limit != nil && limit.Requests > 0 {
+		strategy := limit.Strategy
+		if strategy == "" {
+			strategy = RateLimitSlidingWindow
+		}
+		desc.Security.RateLimit = fmt.Sprintf("%d/%ds (%s)", limit.Requests, limit.Window, strategy)
+	}
+
+	if policy := pattern.Cache; policy != nil && !policy.Invalidate {
+		desc.CacheTTL = int(policy.TTL.Seconds())
+	}
+
+	if gate := pattern.Feature; gate != nil {
+		desc.Feature = gate.Flag
+		desc.FeatureFallback = gate.Fallback != nil
+	}
+
+	return desc
+}
+
+// describeMiddlewares описывает цепочку middleware
+func describeMiddlewares(middlewares []Middleware) []core.MiddlewareInfo {
+	result := make([]core.MiddlewareInfo, 0, len(middlewares))
+	for _, mw := range middlewares {
+		result = append(result, core.MiddlewareInfo{
+			Name:     mw.Name(),
+			Priority: mw.Priority(),
+		})
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+func TestModuleHealth(t *testing.T) {
+	healthy := &hangingModule{release: make(chan struct{})}
+	close(healthy.release)
+
+	r := &Router{modules: map[string]core.Module{healthy.Name(): healthy}}
+	r.health.timeout = time.Second
+
+	tests := []struct {
+		name    string
+		module  string
+		found   bool
+		healthy bool
+	}{
+		{name: "registered module", module: "payments", found: true, healthy: true},
+		{name: "unknown module", module: "arena", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, found := r.ModuleHealth(context.Background(), tt.module)
+			if found != tt.found || status.Healthy != tt.healthy {
+				t.Fatalf("ModuleHealth(%q) = %+v, %v, want healthy %v, found %v", tt.module, status, found, tt.healthy, tt.found)
+			}
+		})
+	}
+
+	if calls := healthy.calls.Load(); calls != 1 {
+		t.Fatalf("Health() called %d times, want 1", calls)
+	}
+}
+
+func TestIntrospectUsesLastHealth(t *testing.T) {
+	module := &hangingModule{release: make(chan struct{})}
+	close(module.release)
+
+	r := &Router{modules: map[string]core.Module{module.Name(): module}, started: true}
+	r.health.timeout = time.Second
+
+	// Без предыдущих проверок модули проверяются, но результат не записывается
+	if health := r.Introspect(context.Background()).Health; !health.Modules[module.Name()].Healthy {
+		t.Fatalf("health = %+v, want healthy module", health)
+	}
+	if _, recorded := r.lastHealth(); recorded {
+		t.Fatal("Introspect recorded a health report")
+	}
+
+	r.Health(context.Background())
+	calls := module.calls.Load()
+
+	health := r.Introspect(context.Background()).Health
+	if !health.Live || !health.Modules[module.Name()].Healthy {
+		t.Fatalf("health = %+v, want the last report", health)
+	}
+	if module.calls.Load() != calls {
+		t.Fatal("Introspect ran a health check although a report was recorded")
+	}
+}
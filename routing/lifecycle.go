@@ -0,0 +1,404 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+)
+
+var _ core.HealthReporter = (*Router)(nil)
+
+// DefaultHealthCheckInterval период фоновой проверки здоровья модулей
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// DefaultHealthCheckTimeout сколько ждать ответа Health() одного модуля
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// Статусы сводного отчета о здоровье
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusStarting  = "starting"
+	HealthStatusUnhealthy = "unhealthy"
+)
+
+// healthMonitor состояние проверок здоровья роутера
+type healthMonitor struct {
+	mu       sync.Mutex
+	interval time.Duration
+	timeout  time.Duration
+
+	// last последний известный статус модулей (для событий module.health)
+	last map[string]bool
+
+	// report последний сводный отчет Health (для Introspect)
+	report *core.HealthReport
+
+	// running проверки модулей, чей Health() еще не вернулся (в том числе после таймаута)
+	running map[string]*healthCheck
+
+	// stop останавливает фоновую проверку
+	stop chan struct{}
+	done chan struct{}
+}
+
+// healthCheck выполняющийся вызов Health() модуля
+// Его результат получают все, кто запросил проверку, пока он выполнялся
+type healthCheck struct {
+	started time.Time
+
+	// done закрывается после записи status
+	done   chan struct{}
+	status core.HealthStatus
+}
+
+// SetHealthCheckInterval устанавливает период фоновой проверки здоровья
+// (0 - фоновая проверка отключена, статус проверяется только по запросу)
+func (r *Router) SetHealthCheckInterval(interval time.Duration) {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	r.health.interval = interval
+}
+
+// SetHealthCheckTimeout устанавливает время ожидания Health() одного модуля
+func (r *Router) SetHealthCheckTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	r.health.timeout = timeout
+}
+
+// launchModule запускает модуль и вызывает OnStart
+// Если OnStart завершился ошибкой, модуль останавливается
+func (r *Router) launchModule(ctx context.Context, module core.Module) error {
+	if err := module.Start(ctx); err != nil {
+		r.reportError(ctx, module, err)
+		return err
+	}
+
+	if lc, ok := module.(core.Lifecycle); ok {
+		if err := lc.OnStart(ctx); err != nil {
+			r.reportError(ctx, module, err)
+			if stopErr := module.Stop(ctx); stopErr != nil {
+				r.logger.Error("Failed to stop module after OnStart error", "name", module.Name(), "error", stopErr)
+			}
+			return fmt.Errorf("OnStart: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// haltModule вызывает OnStop и останавливает модуль
+func (r *Router) haltModule(ctx context.Context, module core.Module) error {
+	var hookErr error
+	if lc, ok := module.(core.Lifecycle); ok {
+		if err := lc.OnStop(ctx); err != nil {
+			hookErr = fmt.Errorf("OnStop: %w", err)
+		}
+	}
+
+	return errors.Join(hookErr, module.Stop(ctx))
+}
+
+// initModule вызывает OnInit после Init модуля
+func (r *Router) initModule(ctx context.Context, module core.Module) error {
+	lc, ok := module.(core.Lifecycle)
+	if !ok {
+		return nil
+	}
+
+	if err := lc.OnInit(ctx); err != nil {
+		return fmt.Errorf("OnInit: %w", err)
+	}
+	return nil
+}
+
+// reportError передает ошибку модулю (Lifecycle.OnError)
+func (r *Router) reportError(ctx context.Context, module core.Module, err error) {
+	lc, ok := module.(core.Lifecycle)
+	if !ok || err == nil {
+		return
+	}
+
+	// Ошибка в OnError не должна ронять обработку запроса
+	defer func() {
+		if p := recover(); p != nil {
+			r.logger.Error("Panic in OnError", "module", module.Name(), "panic", p)
+		}
+	}()
+
+	lc.OnError(ctx, err)
+}
+
+// guard выполняет обработчик модуля и сообщает модулю об ошибках:
+// панике (паника пробрасывается дальше, в RecoveryMiddleware) и ответе с ошибкой
+func (r *Router) guard(ctx core.UniversalContext, module core.Module, handler core.HandlerFunc) core.Response {
+	if _, ok := module.(core.Lifecycle); !ok {
+		return handler(ctx)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			r.reportError(requestContext(ctx), module, fmt.Errorf("panic in module %s: %v", module.Name(), p))
+			panic(p)
+		}
+	}()
+
+	response := handler(ctx)
+	if failed, ok := response.(core.ErrorResponse); ok && failed.Err() != nil {
+		r.reportError(requestContext(ctx), module, failed.Err())
+	}
+
+	return response
+}
+
+// Health проверяет здоровье модулей
+//
+// Модули без core.Lifecycle считаются здоровыми. Модуль, не ответивший
+// за SetHealthCheckTimeout, считается нездоровым, а процесс - неживым (Live=false).
+// Изменения статуса модулей публикуются событием module.health
+func (r *Router) Health(ctx context.Context) core.HealthReport {
+	report := r.checkHealth(ctx)
+	r.recordHealth(ctx, report)
+	return report
+}
+
+// checkHealth проверяет здоровье модулей, не записывая результат
+func (r *Router) checkHealth(ctx context.Context) core.HealthReport {
+	r.mu.RLock()
+	modules := make([]core.Module, 0, len(r.modules))
+	for _, module := range r.modules {
+		modules = append(modules, module)
+	}
+	started := r.started
+	r.mu.RUnlock()
+
+	r.health.mu.Lock()
+	timeout := r.health.timeout
+	r.health.mu.Unlock()
+
+	report := core.HealthReport{
+		Live:    true,
+		Ready:   started,
+		Modules: make(map[string]core.HealthStatus, len(modules)),
+		Time:    time.Now().Unix(),
+	}
+
+	type result struct {
+		name   string
+		status core.HealthStatus
+		hung   bool
+	}
+
+	results := make(chan result, len(modules))
+	for _, module := range modules {
+		module := module
+		go func() {
+			status, hung := r.moduleHealth(ctx, module, timeout)
+			results <- result{name: module.Name(), status: status, hung: hung}
+		}()
+	}
+
+	for range modules {
+		res := <-results
+		report.Modules[res.name] = res.status
+		if !res.status.Healthy {
+			report.Ready = false
+		}
+		if res.hung {
+			report.Live = false
+		}
+	}
+
+	switch {
+	case report.Ready:
+		report.Status = HealthStatusHealthy
+	case !started:
+		report.Status = HealthStatusStarting
+	default:
+		report.Status = HealthStatusUnhealthy
+	}
+
+	return report
+}
+
+// lastHealth возвращает последний отчет Health (false - проверок еще не было)
+func (r *Router) lastHealth() (core.HealthReport, bool) {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	if r.health.report == nil {
+		return core.HealthReport{}, false
+	}
+	return *r.health.report, true
+}
+
+// moduleHealth вызывает Health() модуля с ограничением по времени
+// Возвращает hung=true, если модуль не ответил за timeout с начала проверки.
+// Пока предыдущий вызов Health() модуля не вернулся, новый не запускается:
+// одновременные проверки ждут результат текущего вызова, а зависший модуль
+// занимает одну горутину, а не по одной на каждую проверку
+func (r *Router) moduleHealth(ctx context.Context, module core.Module, timeout time.Duration) (core.HealthStatus, bool) {
+	lc, ok := module.(core.Lifecycle)
+	if !ok {
+		return core.HealthStatus{Healthy: true, Message: "ok"}, false
+	}
+
+	check := r.startHealthCheck(module.Name(), lc)
+
+	select {
+	case <-check.done:
+		return check.status, false
+	default:
+	}
+
+	timer := time.NewTimer(time.Until(check.started.Add(timeout)))
+	defer timer.Stop()
+
+	select {
+	case <-check.done:
+		return check.status, false
+	case <-timer.C:
+		return core.HealthStatus{Message: "health check timed out"}, true
+	case <-ctx.Done():
+		return core.HealthStatus{Message: "health check cancelled"}, false
+	}
+}
+
+// startHealthCheck возвращает выполняющуюся проверку модуля или запускает новую
+func (r *Router) startHealthCheck(name string, lc core.Lifecycle) *healthCheck {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	if check, ok := r.health.running[name]; ok {
+		return check
+	}
+	if r.health.running == nil {
+		r.health.running = make(map[string]*healthCheck)
+	}
+
+	check := &healthCheck{started: time.Now(), done: make(chan struct{})}
+	r.health.running[name] = check
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				check.status = core.HealthStatus{Message: fmt.Sprintf("health check panic: %v", p)}
+			}
+
+			r.health.mu.Lock()
+			delete(r.health.running, name)
+			r.health.mu.Unlock()
+
+			close(check.done)
+		}()
+		check.status = lc.Health()
+	}()
+
+	return check
+}
+
+// recordHealth запоминает отчет и публикует изменения статусов модулей
+func (r *Router) recordHealth(ctx context.Context, report core.HealthReport) {
+	statuses := report.Modules
+
+	r.health.mu.Lock()
+	r.health.report = &report
+	var changed []string
+	last := make(map[string]bool, len(statuses))
+	for name, status := range statuses {
+		// Новый модуль считается здоровым: событие только при проблеме
+		previous, known := r.health.last[name]
+		if !known {
+			previous = true
+		}
+		if previous != status.Healthy {
+			changed = append(changed, name)
+		}
+		last[name] = status.Healthy
+	}
+	r.health.last = last
+	r.health.mu.Unlock()
+
+	sort.Strings(changed)
+	for _, name := range changed {
+		status := statuses[name]
+		if status.Healthy {
+			r.logger.Info("Module recovered", "name", name)
+		} else {
+			r.logger.Warn("Module unhealthy", "name", name, "message", status.Message)
+		}
+		r.publishHealth(ctx, name, status)
+	}
+}
+
+// publishHealth публикует событие module.health
+func (r *Router) publishHealth(ctx context.Context, module string, status core.HealthStatus) {
+	if r.eventBus == nil {
+		return
+	}
+
+	r.mu.RLock()
+	started := r.started
+	r.mu.RUnlock()
+	if !started {
+		return
+	}
+
+	r.eventBus.PublishAsync(context.WithoutCancel(ctx), events.NewModuleHealthEvent(module, status))
+}
+
+// startHealthMonitor запускает фоновую проверку здоровья
+func (r *Router) startHealthMonitor() {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+
+	if r.health.interval <= 0 || r.health.stop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	r.health.stop = stop
+	r.health.done = done
+
+	go func(interval time.Duration) {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.Health(context.Background())
+			case <-stop:
+				return
+			}
+		}
+	}(r.health.interval)
+}
+
+// stopHealthMonitor останавливает фоновую проверку здоровья
+func (r *Router) stopHealthMonitor() {
+	r.health.mu.Lock()
+	stop, done := r.health.stop, r.health.done
+	r.health.stop, r.health.done = nil, nil
+	r.health.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,135 @@
+package routing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// hangingModule модуль, чей Health() ждет release
+type hangingModule struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (m *hangingModule) Name() string                           { return "payments" }
+func (m *hangingModule) Version() string                        { return "1.0.0" }
+func (m *hangingModule) Routes() []core.RoutePattern            { return nil }
+func (m *hangingModule) Init(deps core.Dependencies) error      { return nil }
+func (m *hangingModule) Start(ctx context.Context) error        { return nil }
+func (m *hangingModule) Stop(ctx context.Context) error         { return nil }
+func (m *hangingModule) OnInit(ctx context.Context) error       { return nil }
+func (m *hangingModule) OnStart(ctx context.Context) error      { return nil }
+func (m *hangingModule) OnStop(ctx context.Context) error       { return nil }
+func (m *hangingModule) OnError(ctx context.Context, err error) {}
+
+func (m *hangingModule) Health() core.HealthStatus {
+	m.calls.Add(1)
+	<-m.release
+	return core.HealthStatus{Healthy: true, Message: "ok"}
+}
+
+func TestModuleHealthSkipsRunningCheck(t *testing.T) {
+	r := &Router{}
+	module := &hangingModule{release: make(chan struct{})}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		release bool
+		healthy bool
+		hung    bool
+		calls   int32
+	}{
+		{name: "first check times out", hung: true, calls: 1},
+		{name: "hung check is not repeated", hung: true, calls: 1},
+		{name: "check runs again after release", release: true, healthy: true, calls: 2},
+	}
+
+	for _, tt := range tests {
+		if tt.release {
+			close(module.release)
+			deadline := time.Now().Add(time.Second)
+			for {
+				r.health.mu.Lock()
+				running := r.health.running[module.Name()]
+				r.health.mu.Unlock()
+				if running == nil || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		status, hung := r.moduleHealth(ctx, module, 20*time.Millisecond)
+		if status.Healthy != tt.healthy || hung != tt.hung {
+			t.Fatalf("%s: status = %+v, hung = %v, want healthy %v, hung %v", tt.name, status, hung, tt.healthy, tt.hung)
+		}
+		if calls := module.calls.Load(); calls != tt.calls {
+			t.Fatalf("%s: Health() called %d times, want %d", tt.name, calls, tt.calls)
+		}
+	}
+}
+
+func TestHealthSharesRunningCheck(t *testing.T) {
+	module := &hangingModule{release: make(chan struct{})}
+	r := &Router{modules: map[string]core.Module{module.Name(): module}, started: true}
+	r.health.timeout = time.Second
+
+	const callers = 8
+	reports := make([]core.HealthReport, callers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reports[i] = r.Health(context.Background())
+		}(i)
+	}
+
+	// Модуль отвечает быстрее таймаута, пока остальные проверки ждут его
+	time.Sleep(50 * time.Millisecond)
+	close(module.release)
+	wg.Wait()
+
+	for i, report := range reports {
+		if !report.Live || !report.Ready || !report.Modules[module.Name()].Healthy {
+			t.Fatalf("report %d = %+v, want live and ready", i, report)
+		}
+	}
+	if calls := module.calls.Load(); calls != 1 {
+		t.Fatalf("Health() called %d times, want 1", calls)
+	}
+	if healthy, ok := r.health.last[module.Name()]; !ok || !healthy {
+		t.Fatalf("recorded status = %v, %v, want healthy", healthy, ok)
+	}
+}
+
+func TestModuleHealthTimeoutCountsFromStart(t *testing.T) {
+	module := &hangingModule{release: make(chan struct{})}
+	defer close(module.release)
+	r := &Router{}
+
+	// Первая проверка занимает модуль, вторая присоединяется к ней
+	go r.moduleHealth(context.Background(), module, time.Hour)
+	for module.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	_, hung := r.moduleHealth(context.Background(), module, 100*time.Millisecond)
+	if !hung {
+		t.Fatal("check is not reported as hung after the timeout")
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Fatalf("joined check gave up after %s, before the timeout", waited)
+	}
+	if calls := module.calls.Load(); calls != 1 {
+		t.Fatalf("Health() called %d times, want 1", calls)
+	}
+}
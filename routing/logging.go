@@ -0,0 +1,68 @@
+package routing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/tracing"
+)
+
+// attachRequestLogger заполняет идентификатор запроса и логгер запроса (core.LoggerKey)
+// Middleware и обработчики получают его через core.LoggerFrom
+func (r *Router) attachRequestLogger(ctx core.UniversalContext) {
+	if requestID(ctx) == "" {
+		ctx.Set(core.RequestIDKey, newRequestID())
+	}
+	ctx.Set(core.LoggerKey, r.requestLogger(ctx))
+}
+
+// attachRouteLogger добавляет в логгер запроса модуль и маршрут
+// Поля строятся от логгера роутера: после перенаправления остаются только поля нового маршрута
+func (r *Router) attachRouteLogger(ctx core.UniversalContext, module, route string) core.Logger {
+	logger := r.requestLogger(ctx).WithFields(map[string]interface{}{
+		"module": module,
+		"route":  route,
+	})
+	ctx.Set(core.LoggerKey, logger)
+	return logger
+}
+
+// requestLogger логгер роутера с полями запроса (и trace_id, если адаптер начал трассу)
+func (r *Router) requestLogger(ctx core.UniversalContext) core.Logger {
+	fields := map[string]interface{}{
+		"request_id": requestID(ctx),
+		"user_id":    ctx.GetUserID(),
+		"chat_id":    ctx.GetChatID(),
+	}
+	if source := ctx.GetSource(); source != "" {
+		fields["source"] = source
+	}
+	if sc := tracing.SpanContextFromContext(requestContext(ctx)); sc.IsValid() {
+		fields["trace_id"] = sc.TraceID.String()
+	}
+	return r.logger.WithFields(fields)
+}
+
+// requestID возвращает идентификатор запроса из контекста
+func requestID(ctx core.UniversalContext) string {
+	if value, ok := ctx.Get(core.RequestIDKey); ok {
+		if id, ok := value.(string); ok {
+			return id
+		}
+		if value != nil {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// newRequestID генерирует идентификатор запроса
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
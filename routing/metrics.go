@@ -0,0 +1,29 @@
+package routing
+
+import (
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// SetMetrics включает метрики маршрутизации:
+//
+//   - router.routes_matched - счетчик совпавших маршрутов (метки module, route)
+//   - router.handler_duration_seconds - время обработчика в секундах (метки module, route)
+//
+// Wildcard обработчики учитываются с маршрутом "*"
+func (r *Router) SetMetrics(metrics core.Metrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = metrics
+}
+
+// observeRoute записывает метрики выполненного маршрута
+func observeRoute(metrics core.Metrics, module, route string, start time.Time) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.Counter("router.routes_matched", 1, "module", module, "route", route)
+	metrics.Histogram("router.handler_duration_seconds", time.Since(start).Seconds(), "module", module, "route", route)
+}
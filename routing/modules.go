@@ -0,0 +1,492 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+)
+
+// Стадии жизненного цикла модуля в событиях module.lifecycle
+const (
+	LifecycleRegistered   = "registered"
+	LifecycleStarted      = "started"
+	LifecycleDraining     = "draining"
+	LifecycleStopped      = "stopped"
+	LifecycleUnregistered = "unregistered"
+	LifecycleReplaced     = "replaced"
+	LifecycleError        = "error"
+)
+
+// moduleState состояние зарегистрированного модуля
+type moduleState struct {
+	module core.Module
+
+	// owner владелец подписок на события (уникален для каждой регистрации)
+	owner string
+
+	// wildcard модуль зарегистрирован как wildcard обработчик
+	wildcard bool
+
+	// feature флаг модуля (core.FeatureGatedModule), пустой - модуль доступен всем
+	feature string
+
+	// active подписки модуля активны (для шин без OwnedSubscriber)
+	active atomic.Bool
+
+	// inflight учет выполняющихся запросов
+	inflight moduleTracker
+}
+
+// moduleTracker считает выполняющиеся запросы модуля
+// После close новые запросы не принимаются, idle закрывается, когда выполнятся текущие
+type moduleTracker struct {
+	mu       sync.Mutex
+	active   int
+	closed   bool
+	idle     chan struct{}
+	idleOnce sync.Once
+}
+
+// acquire учитывает начало запроса (false - модуль выгружается)
+func (t *moduleTracker) acquire() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+	t.active++
+	return true
+}
+
+// release учитывает завершение запроса
+func (t *moduleTracker) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active--
+	if t.closed && t.active == 0 {
+		t.idleOnce.Do(func() { close(t.idle) })
+	}
+}
+
+// drain запрещает новые запросы и ждет завершения текущих
+func (t *moduleTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	if t.active == 0 {
+		t.idleOnce.Do(func() { close(t.idle) })
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("module still has in-flight requests: %w", ctx.Err())
+	}
+}
+
+// preparedModule модуль, готовый к установке в таблицы роутера
+type preparedModule struct {
+	state  *moduleState
+	routes []compiledRoute
+}
+
+// prepareModule инициализирует модуль и строит его маршруты (без блокировки роутера)
+func (r *Router) prepareModule(ctx context.Context, module core.Module, wildcard bool) (*preparedModule, error) {
+	name := module.Name()
+	owner := fmt.Sprintf("%s#%d", name, r.generation.Add(1))
+
+	// Заполняем и проверяем схему конфигурации модуля до Init
+	if err := r.bindModuleConfig(module); err != nil {
+		return nil, fmt.Errorf("invalid config of module %s: %w", name, err)
+	}
+
+	// Инициализируем модуль (со своим дочерним контейнером, если зависимости его выдают)
+	if deps := r.moduleDependencies(owner); deps != nil {
+		if err := module.Init(deps); err != nil {
+			r.releaseDependencies(owner)
+			return nil, fmt.Errorf("failed to init module %s: %w", name, err)
+		}
+	}
+	if err := r.initModule(ctx, module); err != nil {
+		r.releaseDependencies(owner)
+		r.reportError(ctx, module, err)
+		return nil, fmt.Errorf("failed to init module %s: %w", name, err)
+	}
+
+	state := &moduleState{
+		module:   module,
+		owner:    owner,
+		wildcard: wildcard,
+		feature:  featureOf(module),
+	}
+	state.inflight.idle = make(chan struct{})
+
+	prepared := &preparedModule{state: state}
+
+	for _, iPattern := range module.Routes() {
+		// Приводим к нашему типу RoutePattern
+		pattern, ok := iPattern.(RoutePattern)
+		if !ok {
+			continue
+		}
+
+		// Создаем ограничитель скорости маршрута
+		if cfg := pattern.Security.RateLimit; cfg != nil && cfg.Requests > 0 {
+			pattern.limiter = NewRateLimiterWithBackend(cfg, r.limitBackend())
+			pattern.limiter.scope = fmt.Sprintf("%s:%s:", name, strings.Join(pattern.Patterns, "|"))
+		}
+
+		pattern.Module = name
+		pattern.eventBus = r.eventBus
+
+		if err := pattern.Compile(); err != nil {
+			r.logger.Error("Failed to compile route", "error", err, "module", name)
+		}
+
+		prepared.routes = append(prepared.routes, compiledRoute{
+			pattern: pattern,
+			module:  name,
+			state:   state,
+		})
+	}
+
+	return prepared, nil
+}
+
+// bindModuleConfig заполняет схему конфигурации модуля его секцией "modules.<name>"
+// Модуль со схемой не регистрируется, если конфигурация роутера не поддерживает секции
+func (r *Router) bindModuleConfig(module core.Module) error {
+	configurable, ok := module.(core.ConfigurableModule)
+	if !ok {
+		return nil
+	}
+
+	schema := configurable.ConfigSchema()
+	if schema == nil {
+		return nil
+	}
+
+	// Привязку к структуре умеет только конфигурация с секциями - ее передает вызывающий
+	sectioned, ok := r.config.(core.SectionedConfig)
+	if !ok {
+		return fmt.Errorf("module %s declares a config schema, but router config does not implement core.SectionedConfig", module.Name())
+	}
+	return sectioned.Bind(core.ModulesConfigKey+"."+module.Name(), schema)
+}
+
+// moduleDependencies возвращает зависимости для Init регистрации модуля
+func (r *Router) moduleDependencies(owner string) core.Dependencies {
+	if scoped, ok := r.dependencies.(core.ModuleScopedDependencies); ok {
+		return scoped.ForModule(owner)
+	}
+	return r.dependencies
+}
+
+// releaseDependencies освобождает зависимости регистрации модуля
+func (r *Router) releaseDependencies(owner string) {
+	if scoped, ok := r.dependencies.(core.ModuleScopedDependencies); ok {
+		scoped.ReleaseModule(owner)
+	}
+}
+
+// validateDependencies проверяет, что объявленные модулями зависимости зарегистрированы
+func (r *Router) validateDependencies() error {
+	validated, ok := r.dependencies.(core.ValidatedDependencies)
+	if !ok {
+		return nil
+	}
+	if err := validated.Validate(); err != nil {
+		return fmt.Errorf("invalid dependencies: %w", err)
+	}
+	return nil
+}
+
+// limitBackend возвращает общее хранилище счетчиков лимитов
+func (r *Router) limitBackend() RateLimitBackend {
+	r.backendOnce.Do(func() {
+		if r.rateLimitBackend == nil {
+			r.rateLimitBackend = NewMemoryRateLimitBackend(DefaultRateLimitCleanupInterval)
+		}
+	})
+	return r.rateLimitBackend
+}
+
+// installLocked добавляет модуль в таблицы роутера (под r.mu)
+// Таблицы пересоздаются, чтобы выполняющиеся запросы работали со своей копией
+func (r *Router) installLocked(prepared *preparedModule) {
+	state := prepared.state
+	name := state.module.Name()
+
+	r.modules[name] = state.module
+	r.states[name] = state
+
+	routes := make([]compiledRoute, 0, len(r.routes)+len(prepared.routes))
+	routes = append(routes, r.routes...)
+	routes = append(routes, prepared.routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].pattern.Priority > routes[j].pattern.Priority
+	})
+	r.routes = routes
+
+	if wc, ok := state.module.(core.WildcardModule); ok && state.wildcard {
+		wildcards := make([]wildcardHandler, 0, len(r.wildcards)+1)
+		wildcards = append(wildcards, r.wildcards...)
+		wildcards = append(wildcards, wildcardHandler{
+			module:   wc,
+			priority: wc.Priority(),
+			state:    state,
+		})
+
+		// Сортируем по приоритету (больше = выше)
+		sort.SliceStable(wildcards, func(i, j int) bool {
+			return wildcards[i].priority > wildcards[j].priority
+		})
+		r.wildcards = wildcards
+	}
+}
+
+// removeLocked убирает модуль из таблиц роутера (под r.mu)
+func (r *Router) removeLocked(name string) {
+	delete(r.modules, name)
+	delete(r.states, name)
+
+	routes := make([]compiledRoute, 0, len(r.routes))
+	for _, route := range r.routes {
+		if route.module != name {
+			routes = append(routes, route)
+		}
+	}
+	r.routes = routes
+
+	wildcards := make([]wildcardHandler, 0, len(r.wildcards))
+	for _, wc := range r.wildcards {
+		if wc.module.Name() != name {
+			wildcards = append(wildcards, wc)
+		}
+	}
+	r.wildcards = wildcards
+}
+
+// subscribe подписывает модуль на его события
+// Обработчики оборачиваются: после выгрузки модуля они перестают вызываться
+// даже если шина не умеет снимать подписки (core.OwnedSubscriber)
+func (r *Router) subscribe(state *moduleState) error {
+	state.active.Store(true)
+
+	eventAware, ok := state.module.(core.EventAwareModule)
+	if !ok || r.eventBus == nil {
+		return nil
+	}
+
+	owned, isOwned := r.eventBus.(core.OwnedSubscriber)
+
+	for _, sub := range eventAware.Events() {
+		handler := sub.Handler
+		wrapped := func(ctx context.Context, event core.Event) error {
+			if !state.active.Load() {
+				return nil
+			}
+			return handler(ctx, event)
+		}
+
+		var err error
+		if isOwned {
+			err = owned.SubscribeOwned(state.owner, sub.EventType, wrapped)
+		} else {
+			err = r.eventBus.Subscribe(sub.EventType, wrapped)
+		}
+		if err != nil {
+			r.unsubscribe(state)
+			return fmt.Errorf("failed to subscribe to event %s: %w", sub.EventType, err)
+		}
+	}
+
+	return nil
+}
+
+// unsubscribe снимает подписки модуля
+func (r *Router) unsubscribe(state *moduleState) {
+	state.active.Store(false)
+
+	if owned, ok := r.eventBus.(core.OwnedSubscriber); ok {
+		if err := owned.UnsubscribeOwner(state.owner); err != nil {
+			r.logger.Error("Failed to unsubscribe module", "module", state.module.Name(), "error", err)
+		}
+	}
+}
+
+// UnregisterModule выгружает модуль во время работы
+//
+// Маршруты, wildcard и подписки модуля снимаются сразу, затем роутер ждет
+// завершения выполняющихся запросов модуля (не дольше ctx) и останавливает модуль.
+// HTTP API модуля становится недоступным вместе с ним
+func (r *Router) UnregisterModule(ctx context.Context, name string) error {
+	r.mu.Lock()
+	state, ok := r.states[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("module %s not registered", name)
+	}
+	if dependents := requiredBy(name, r.modules); len(dependents) > 0 {
+		r.mu.Unlock()
+		return fmt.Errorf("cannot unregister module %s: %w: %s", name, ErrModuleRequired, strings.Join(dependents, ", "))
+	}
+	r.removeLocked(name)
+	started := r.started
+	r.mu.Unlock()
+
+	r.unsubscribe(state)
+	r.retire(ctx, state, started)
+	r.releaseDependencies(state.owner)
+
+	r.publishLifecycle(ctx, name, LifecycleUnregistered, "ok")
+	r.logger.Info("Module unregistered", "name", name)
+
+	return nil
+}
+
+// ReplaceModule заменяет модуль с тем же именем новой версией во время работы
+//
+// Новая версия инициализируется и запускается до переключения, переключение
+// маршрутов атомарное: каждый запрос обрабатывается либо старой, либо новой версией.
+// Старая версия останавливается после завершения своих запросов
+func (r *Router) ReplaceModule(ctx context.Context, module core.Module) error {
+	name := module.Name()
+
+	r.mu.RLock()
+	old, ok := r.states[name]
+	started := r.started
+	var depErr error
+	if ok {
+		depErr = r.checkGraphLocked(module)
+	}
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("module %s not registered", name)
+	}
+
+	// Новая версия должна подходить зависящим модулям, а ее зависимости - быть на месте
+	if depErr != nil {
+		return fmt.Errorf("cannot replace module %s: %w", name, depErr)
+	}
+
+	prepared, err := r.prepareModule(ctx, module, old.wildcard)
+	if err != nil {
+		r.publishLifecycle(ctx, name, LifecycleError, err.Error())
+		return err
+	}
+
+	replaced := false
+	defer func() {
+		if !replaced {
+			r.releaseDependencies(prepared.state.owner)
+		}
+	}()
+
+	if started {
+		if err := r.validateDependencies(); err != nil {
+			r.publishLifecycle(ctx, name, LifecycleError, err.Error())
+			return fmt.Errorf("cannot replace module %s: %w", name, err)
+		}
+		if err := r.launchModule(ctx, module); err != nil {
+			r.publishLifecycle(ctx, name, LifecycleError, err.Error())
+			return fmt.Errorf("failed to start module %s: %w", name, err)
+		}
+	}
+
+	r.mu.Lock()
+	if r.states[name] != old {
+		r.mu.Unlock()
+		r.stopModule(ctx, module, started)
+		return fmt.Errorf("module %s was changed concurrently", name)
+	}
+	r.removeLocked(name)
+	r.installLocked(prepared)
+	r.mu.Unlock()
+
+	replaced = true
+
+	r.unsubscribe(old)
+	if err := r.subscribe(prepared.state); err != nil {
+		r.logger.Error("Failed to subscribe replaced module", "name", name, "error", err)
+		r.publishLifecycle(ctx, name, LifecycleError, err.Error())
+	}
+
+	r.retire(ctx, old, started)
+	r.releaseDependencies(old.owner)
+
+	r.publishLifecycle(ctx, name, LifecycleReplaced, module.Version())
+	r.logger.Info("Module replaced", "name", name, "from", old.module.Version(), "to", module.Version())
+
+	return nil
+}
+
+// checkGraphLocked проверяет граф зависимостей с модулем module
+// (добавленным или заменившим модуль с тем же именем) под r.mu
+func (r *Router) checkGraphLocked(module core.Module) error {
+	modules := make(map[string]core.Module, len(r.modules)+1)
+	for name, m := range r.modules {
+		modules[name] = m
+	}
+	modules[module.Name()] = module
+
+	_, err := moduleOrder(modules)
+	return err
+}
+
+// retire дожидается завершения запросов выгружаемой версии модуля и останавливает ее
+func (r *Router) retire(ctx context.Context, state *moduleState, started bool) {
+	name := state.module.Name()
+
+	r.publishLifecycle(ctx, name, LifecycleDraining, "ok")
+	if err := state.inflight.drain(ctx); err != nil {
+		r.logger.Warn("Module drain timed out", "name", name, "error", err)
+	}
+
+	r.stopModule(ctx, state.module, started)
+}
+
+// stopModule останавливает модуль, если роутер запущен
+func (r *Router) stopModule(ctx context.Context, module core.Module, started bool) {
+	if !started {
+		return
+	}
+
+	if err := r.haltModule(ctx, module); err != nil {
+		r.logger.Error("Failed to stop module", "name", module.Name(), "error", err)
+		r.publishLifecycle(ctx, module.Name(), LifecycleError, err.Error())
+		return
+	}
+	r.publishLifecycle(ctx, module.Name(), LifecycleStopped, "ok")
+}
+
+// publishLifecycle публикует событие module.lifecycle
+// События публикуются только работающему роутеру (шина запущена)
+func (r *Router) publishLifecycle(ctx context.Context, module, lifecycle, status string) {
+	if r.eventBus == nil {
+		return
+	}
+
+	r.mu.RLock()
+	started := r.started
+	r.mu.RUnlock()
+	if !started {
+		return
+	}
+
+	event := events.NewModuleLifecycleEvent(module, lifecycle, status)
+	event.SetData("module", module).
+		SetData("lifecycle", lifecycle).
+		SetData("status", status)
+
+	r.eventBus.PublishAsync(context.WithoutCancel(ctx), event)
+}
@@ -0,0 +1,82 @@
+package routing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// plainConfig core.Config без секций и привязки к структурам
+type plainConfig map[string]interface{}
+
+func (c plainConfig) Get(key string) interface{}                { return c[key] }
+func (c plainConfig) GetString(key string) string               { s, _ := c[key].(string); return s }
+func (c plainConfig) GetInt(key string) int                     { n, _ := c[key].(int); return n }
+func (c plainConfig) GetBool(key string) bool                   { b, _ := c[key].(bool); return b }
+func (c plainConfig) GetStringSlice(key string) []string        { return nil }
+func (c plainConfig) GetStringMap(key string) map[string]string { return nil }
+func (c plainConfig) Set(key string, value interface{})         { c[key] = value }
+func (c plainConfig) IsSet(key string) bool                     { _, ok := c[key]; return ok }
+
+// sectionedConfig plainConfig с Bind, запоминающим ключ секции
+type sectionedConfig struct {
+	plainConfig
+	bound string
+}
+
+func (c *sectionedConfig) Section(key string) core.Config { return c.plainConfig }
+func (c *sectionedConfig) Bind(key string, target interface{}) error {
+	c.bound = key
+	return nil
+}
+
+// configurableModule модуль со схемой конфигурации
+type configurableModule struct {
+	config struct {
+		MaxOpponents int `config:"max_opponents" default:"5"`
+	}
+}
+
+func (m *configurableModule) Name() string                      { return "arena" }
+func (m *configurableModule) Version() string                   { return "1.0.0" }
+func (m *configurableModule) Routes() []core.RoutePattern       { return nil }
+func (m *configurableModule) Init(deps core.Dependencies) error { return nil }
+func (m *configurableModule) Start(ctx context.Context) error   { return nil }
+func (m *configurableModule) Stop(ctx context.Context) error    { return nil }
+func (m *configurableModule) ConfigSchema() interface{}         { return &m.config }
+
+func TestBindModuleConfig(t *testing.T) {
+	sectioned := &sectionedConfig{plainConfig: plainConfig{}}
+
+	tests := []struct {
+		name    string
+		config  core.Config
+		wantErr string
+	}{
+		{name: "sectioned config", config: sectioned},
+		{name: "plain config", config: plainConfig{}, wantErr: "does not implement core.SectionedConfig"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{config: tt.config}
+
+			err := r.bindModuleConfig(&configurableModule{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if sectioned.bound != "modules.arena" {
+		t.Fatalf("bound section %q, want modules.arena", sectioned.bound)
+	}
+}
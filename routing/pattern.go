@@ -4,6 +4,7 @@ import (
 	"github.com/andranikuz/botkit/core"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // RoutePattern описывает паттерн маршрута
@@ -24,6 +25,12 @@ type RoutePattern struct {
 	// Security правила безопасности
 	Security SecurityRule
 
+	// Cache политика кеширования ответа (nil - не кешировать)
+	Cache *CachePolicy
+
+	// Feature флаг функциональности маршрута (nil - маршрут доступен всем)
+	Feature *FeatureGate
+
 	// Meta метаданные маршрута
 	Meta RouteMeta
 
@@ -32,6 +39,12 @@ type RoutePattern struct {
 
 	// compiled скомпилированные регулярные выражения
 	compiled []*regexp.Regexp
+
+	// limiter ограничитель скорости маршрута (создается при регистрации)
+	limiter *RateLimiter
+
+	// eventBus шина для событий security.denied (устанавливается при регистрации)
+	eventBus core.EventBus
 }
 
 // RouteType тип маршрута
@@ -121,6 +134,8 @@ func (r *RoutePattern) Compile() error {
 }
 
 // Match проверяет соответствие текста паттерну
+// Параметры: именованные плейсхолдеры, позиционные "1", "2"... и "_pattern" -
+// сработавший паттерн (в том числе для паттернов без плейсхолдеров)
 func (r *RoutePattern) Match(text string) (bool, map[string]string) {
 	text = strings.TrimSpace(strings.ToLower(text))
 
@@ -139,6 +154,7 @@ func (r *RoutePattern) Match(text string) (bool, map[string]string) {
 
 			// Если это простой паттерн без групп
 			if len(matches) == 1 {
+				params["_pattern"] = r.Patterns[i]
 				return true, params
 			}
 
@@ -174,6 +190,7 @@ func (r *RoutePattern) patternToRegex(pattern string) string {
 	// {name} -> (?P<name>\w+)
 	// {text} -> (?P<text>.+)
 
+	// Ключи совпадают с результатом QuoteMeta: {id} -> \{id\}
 	replacements := map[string]string{
 		`\{id\}`:     `(?P<id>\d+)`,
 		`\{user\}`:   `(?P<user>\d+)`,
@@ -184,7 +201,7 @@ func (r *RoutePattern) patternToRegex(pattern string) string {
 	}
 
 	for old, new := range replacements {
-		pattern = regexp.MustCompile(old).ReplaceAllString(pattern, new)
+		pattern = strings.ReplaceAll(pattern, old, new)
 	}
 
 	// Добавляем якоря начала и конца
@@ -216,13 +233,39 @@ func (r *RoutePattern) CheckSecurity(ctx core.UniversalContext) error {
 func (r *RoutePattern) Execute(ctx core.UniversalContext) core.Response {
 	// Проверяем безопасность
 	if err := r.CheckSecurity(ctx); err != nil {
-		return core.NewMessage("🚫 " + err.Error())
+		return r.deny(ctx, err)
 	}
 
-	// Выполняем обработчик
+	// Проверяем ограничение скорости маршрута
+	if r.limiter != nil {
+		state := r.limiter.Take(ctx)
+		ctx.Set(core.RateLimitStateKey, state)
+		if !state.Allowed {
+			return r.deny(ctx, &RateLimitError{State: state})
+		}
+	}
+
+	// Выполняем обработчик (через кеш ответов, если задана политика)
+	if r.Cache != nil {
+		return r.cached(ctx)
+	}
 	return r.Handler(ctx)
 }
 
+// deny публикует событие и возвращает ответ об отказе
+func (r *RoutePattern) deny(ctx core.UniversalContext, err error) core.Response {
+	publishSecurityDenied(r.eventBus, ctx, r.Module, r.routeName(), err)
+	return r.Security.HandleFailure(ctx, err)
+}
+
+// routeName имя маршрута для событий и ключей (Meta.Name или первый паттерн)
+func (r *RoutePattern) routeName() string {
+	if r.Meta.Name != "" || len(r.Patterns) == 0 {
+		return r.Meta.Name
+	}
+	return r.Patterns[0]
+}
+
 // RouteBuilder построитель маршрутов
 type RouteBuilder struct {
 	pattern *RoutePattern
@@ -277,6 +320,46 @@ func (b *RouteBuilder) RequirePermissions(perms ...string) *RouteBuilder {
 	return b
 }
 
+// ChatTypes ограничивает маршрут типами чатов
+func (b *RouteBuilder) ChatTypes(types ...core.ChatType) *RouteBuilder {
+	b.pattern.Security.AllowedChatTypes = types
+	return b
+}
+
+// PrivateOnly разрешает маршрут только в личных сообщениях
+func (b *RouteBuilder) PrivateOnly() *RouteBuilder {
+	return b.ChatTypes(core.ChatTypePrivate)
+}
+
+// GroupOnly разрешает маршрут только в группах
+func (b *RouteBuilder) GroupOnly() *RouteBuilder {
+	return b.ChatTypes(core.ChatTypeGroup, core.ChatTypeSupergroup)
+}
+
+// GroupAdminsOnly разрешает маршрут только администраторам групп
+func (b *RouteBuilder) GroupAdminsOnly() *RouteBuilder {
+	b.pattern.Security.RequireChatAdmin = true
+	return b.GroupOnly()
+}
+
+// RequireAddressed требует обращения к боту (упоминание, ответ или /команда@бот)
+func (b *RouteBuilder) RequireAddressed() *RouteBuilder {
+	b.pattern.Security.RequireAddressed = true
+	return b
+}
+
+// OnDenied устанавливает обработчик отказа в доступе
+func (b *RouteBuilder) OnDenied(handler SecurityFailureHandler) *RouteBuilder {
+	b.pattern.Security.OnFailure = handler
+	return b
+}
+
+// AlertOnDenied показывает отказ всплывающим alert при нажатии кнопки
+func (b *RouteBuilder) AlertOnDenied() *RouteBuilder {
+	b.pattern.Security.AlertOnCallback = true
+	return b
+}
+
 // RateLimit устанавливает ограничение скорости
 func (b *RouteBuilder) RateLimit(requests, window int) *RouteBuilder {
 	b.pattern.Security.RateLimit = &RateLimitConfig{
@@ -286,6 +369,94 @@ func (b *RouteBuilder) RateLimit(requests, window int) *RouteBuilder {
 	return b
 }
 
+// RateLimitWith устанавливает ограничение скорости с полной конфигурацией
+func (b *RouteBuilder) RateLimitWith(config RateLimitConfig) *RouteBuilder {
+	b.pattern.Security.RateLimit = &config
+	return b
+}
+
+// TokenBucket устанавливает ограничение корзиной токенов с допустимым всплеском
+func (b *RouteBuilder) TokenBucket(requests, window, burst int) *RouteBuilder {
+	return b.RateLimitWith(RateLimitConfig{
+		Requests:  requests,
+		Window:    window,
+		BurstSize: burst,
+		Strategy:  RateLimitTokenBucket,
+	})
+}
+
+// RateLimitKey устанавливает функцию ключа лимита (пользователь, чат, глобально)
+func (b *RouteBuilder) RateLimitKey(key RateLimitKeyFunc) *RouteBuilder {
+	if b.pattern.Security.RateLimit == nil {
+		b.pattern.Security.RateLimit = &RateLimitConfig{}
+	}
+	b.pattern.Security.RateLimit.Key = key
+	return b
+}
+
+// Cache кеширует ответ маршрута на ttl (ключ - параметры маршрута, пользователь и чат)
+// Требует middleware.CachingMiddleware, без него маршрут выполняется как обычно
+func (b *RouteBuilder) Cache(ttl time.Duration) *RouteBuilder {
+	if b.pattern.Cache == nil {
+		b.pattern.Cache = &CachePolicy{}
+	}
+	b.pattern.Cache.TTL = ttl
+	return b
+}
+
+// CacheWith устанавливает политику кеширования целиком
+func (b *RouteBuilder) CacheWith(policy CachePolicy) *RouteBuilder {
+	b.pattern.Cache = &policy
+	return b
+}
+
+// CacheKey задает, из чего строится ключ кеша (параметры, пользователь, чат, локаль)
+// Пользователь и чат добавляются к ключу, пока ответ не объявлен общим (CacheShared)
+func (b *RouteBuilder) CacheKey(parts ...CacheKeyPart) *RouteBuilder {
+	if b.pattern.Cache == nil {
+		b.pattern.Cache = &CachePolicy{}
+	}
+	b.pattern.Cache.KeyBy = parts
+	return b
+}
+
+// CacheShared отдает один закешированный ответ всем пользователям и чатам
+// Только для ответов без персональных данных
+func (b *RouteBuilder) CacheShared() *RouteBuilder {
+	if b.pattern.Cache == nil {
+		b.pattern.Cache = &CachePolicy{}
+	}
+	b.pattern.Cache.Shared = true
+	return b
+}
+
+// InvalidatesCache помечает маршрут как изменяющий состояние:
+// его ответ не кешируется, а после выполнения сбрасывается кеш модуля
+func (b *RouteBuilder) InvalidatesCache() *RouteBuilder {
+	b.pattern.Cache = &CachePolicy{Invalidate: true}
+	return b
+}
+
+// Feature открывает маршрут только пользователям с включенным флагом
+// Для остальных маршрут пропускается: запрос обработает следующий подходящий
+// маршрут (например, прежняя версия команды с меньшим приоритетом)
+func (b *RouteBuilder) Feature(flag string) *RouteBuilder {
+	if b.pattern.Feature == nil {
+		b.pattern.Feature = &FeatureGate{}
+	}
+	b.pattern.Feature.Flag = flag
+	return b
+}
+
+// FeatureFallback задает обработчик для пользователей с выключенным флагом маршрута
+func (b *RouteBuilder) FeatureFallback(handler core.HandlerFunc) *RouteBuilder {
+	if b.pattern.Feature == nil {
+		b.pattern.Feature = &FeatureGate{}
+	}
+	b.pattern.Feature.Fallback = handler
+	return b
+}
+
 // Meta устанавливает метаданные
 func (b *RouteBuilder) Meta(name, description string) *RouteBuilder {
 	b.pattern.Meta.Name = name
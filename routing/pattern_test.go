@@ -0,0 +1,102 @@
+package routing
+
+import (
+	"testing"
+)
+
+func TestRoutePatternMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		text     string
+		match    bool
+		params   map[string]string
+	}{
+		{
+			name:     "plain command",
+			patterns: []string{"/maintenance"},
+			text:     "/maintenance",
+			match:    true,
+			params:   map[string]string{"_pattern": "/maintenance"},
+		},
+		{
+			name:     "second plain pattern",
+			patterns: []string{"/start", "/help"},
+			text:     "/HELP",
+			match:    true,
+			params:   map[string]string{"_pattern": "/help"},
+		},
+		{
+			name:     "id placeholder",
+			patterns: []string{"/profile {id}"},
+			text:     "/profile 42",
+			match:    true,
+			params:   map[string]string{"id": "42", "1": "42", "_pattern": "/profile {id}"},
+		},
+		{
+			name:     "id must be numeric",
+			patterns: []string{"/profile {id}"},
+			text:     "/profile abc",
+			match:    false,
+		},
+		{
+			name:     "user and amount",
+			patterns: []string{"/ban {user} {amount}"},
+			text:     "/ban 7 30",
+			match:    true,
+			params:   map[string]string{"user": "7", "amount": "30", "1": "7", "2": "30", "_pattern": "/ban {user} {amount}"},
+		},
+		{
+			name:     "name placeholder",
+			patterns: []string{"/feature on {name}"},
+			text:     "/feature on new_arena",
+			match:    true,
+			params:   map[string]string{"name": "new_arena", "1": "new_arena", "_pattern": "/feature on {name}"},
+		},
+		{
+			name:     "name is a single word",
+			patterns: []string{"/feature on {name}"},
+			text:     "/feature on new arena",
+			match:    false,
+		},
+		{
+			name:     "text placeholder",
+			patterns: []string{"/say {text}"},
+			text:     "/say Hello World",
+			match:    true,
+			params:   map[string]string{"text": "hello world", "1": "hello world", "_pattern": "/say {text}"},
+		},
+		{
+			name:     "regex characters are literal",
+			patterns: []string{"/price? {id}"},
+			text:     "/price? 5",
+			match:    true,
+			params:   map[string]string{"id": "5", "1": "5", "_pattern": "/price? {id}"},
+		},
+		{
+			name:     "anchored",
+			patterns: []string{"/ban {user}"},
+			text:     "/ban 7 now",
+			match:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := RoutePattern{Patterns: tt.patterns}
+
+			match, params := route.Match(tt.text)
+			if match != tt.match {
+				t.Fatalf("Match(%q) = %v, want %v", tt.text, match, tt.match)
+			}
+			if len(params) != len(tt.params) {
+				t.Fatalf("params = %v, want %v", params, tt.params)
+			}
+			for key, want := range tt.params {
+				if params[key] != want {
+					t.Errorf("params[%q] = %q, want %q", key, params[key], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,477 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// Стратегии ограничения скорости
+const (
+	// RateLimitSlidingWindow скользящее окно (взвешенная сумма текущего и предыдущего окна)
+	RateLimitSlidingWindow = "sliding_window"
+
+	// RateLimitFixedWindow фиксированное окно
+	RateLimitFixedWindow = "fixed_window"
+
+	// RateLimitTokenBucket корзина токенов с поддержкой всплесков (BurstSize)
+	RateLimitTokenBucket = "token_bucket"
+)
+
+// RateLimitKeyFunc функция построения ключа лимита
+type RateLimitKeyFunc func(ctx core.UniversalContext) string
+
+// RateLimitKeyUser лимит на пользователя
+func RateLimitKeyUser(ctx core.UniversalContext) string {
+	return fmt.Sprintf("user:%d", ctx.GetUserID())
+}
+
+// RateLimitKeyChat лимит на чат
+func RateLimitKeyChat(ctx core.UniversalContext) string {
+	return fmt.Sprintf("chat:%d", ctx.GetChatID())
+}
+
+// RateLimitKeyUserRoute лимит на пользователя в рамках маршрута
+func RateLimitKeyUserRoute(ctx core.UniversalContext) string {
+	route, _ := ctx.GetParam("_pattern")
+	return fmt.Sprintf("user:%d:route:%v", ctx.GetUserID(), route)
+}
+
+// RateLimitKeyGlobal общий лимит на всех
+func RateLimitKeyGlobal(ctx core.UniversalContext) string {
+	return "global"
+}
+
+// RateLimitState состояние лимита для ключа
+type RateLimitState struct {
+	// Allowed запрос разрешен
+	Allowed bool
+
+	// Limit максимальное количество запросов
+	Limit int
+
+	// Remaining оставшееся количество запросов
+	Remaining int
+
+	// ResetAt время полного восстановления лимита
+	ResetAt time.Time
+
+	// RetryAfter через сколько можно повторить запрос (0 - можно сейчас)
+	RetryAfter time.Duration
+}
+
+// RetryAfterSeconds возвращает время ожидания в секундах (с округлением вверх)
+func (s RateLimitState) RetryAfterSeconds() int {
+	return int(math.Ceil(s.RetryAfter.Seconds()))
+}
+
+// RateLimitError ошибка превышения лимита с состоянием
+type RateLimitError struct {
+	State RateLimitState
+}
+
+// Error возвращает текст ошибки
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: retry after %ds", ErrRateLimitExceeded, e.State.RetryAfterSeconds())
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrRateLimitExceeded)
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimitExceeded
+}
+
+// RateLimiter реализация ограничителя скорости
+// Счетчики хранятся в RateLimitBackend: в памяти процесса или в общем кеше
+type RateLimiter struct {
+	// config текущие настройки (заменяются атомарно в Update)
+	config  atomic.Pointer[RateLimitConfig]
+	backend RateLimitBackend
+	keyFunc RateLimitKeyFunc
+
+	// scope префикс ключей (разделяет лимиты маршрутов в общем backend)
+	scope string
+
+	// owned backend создан ограничителем и закрывается в Close
+	owned bool
+}
+
+// NewRateLimiter создает новый ограничитель
+// Если storage реализует core.Cache, счетчики хранятся в нем и разделяются
+// между репликами, иначе - в памяти процесса
+func NewRateLimiter(config *RateLimitConfig, storage core.Storage) *RateLimiter {
+	if cache, ok := storage.(core.Cache); ok {
+		return NewCacheRateLimiter(config, cache)
+	}
+
+	limiter := NewRateLimiterWithBackend(config, NewMemoryRateLimitBackend(DefaultRateLimitCleanupInterval))
+	limiter.owned = true
+	return limiter
+}
+
+// NewCacheRateLimiter создает ограничитель со счетчиками в кеше
+func NewCacheRateLimiter(config *RateLimitConfig, cache core.Cache) *RateLimiter {
+	return NewRateLimiterWithBackend(config, NewCacheRateLimitBackend(cache))
+}
+
+// NewRateLimiterWithBackend создает ограничитель с указанным хранилищем счетчиков
+func NewRateLimiterWithBackend(config *RateLimitConfig, backend RateLimitBackend) *RateLimiter {
+	if config.Strategy == "" {
+		config.Strategy = RateLimitSlidingWindow
+	}
+
+	keyFunc := config.Key
+	if keyFunc == nil {
+		keyFunc = RateLimitKeyUser
+	}
+
+	limiter := &RateLimiter{
+		backend: backend,
+		keyFunc: keyFunc,
+	}
+	limiter.config.Store(config)
+	return limiter
+}
+
+// Config возвращает текущие настройки ограничителя
+func (r *RateLimiter) Config() RateLimitConfig {
+	return *r.config.Load()
+}
+
+// Update заменяет лимиты без пересоздания ограничителя (например, при перезагрузке конфигурации)
+// Функция ключа (Key) задается при создании и не меняется
+func (r *RateLimiter) Update(config RateLimitConfig) {
+	if config.Strategy == "" {
+		config.Strategy = RateLimitSlidingWindow
+	}
+	config.Key = r.config.Load().Key
+	r.config.Store(&config)
+}
+
+// Allow проверяет, разрешен ли запрос
+func (r *RateLimiter) Allow(ctx core.UniversalContext) bool {
+	return r.Take(ctx).Allowed
+}
+
+// Take учитывает запрос и возвращает состояние лимита
+func (r *RateLimiter) Take(ctx core.UniversalContext) RateLimitState {
+	return r.apply(requestContext(ctx), r.keyFunc(ctx), true)
+}
+
+// State возвращает состояние лимита без учета запроса
+func (r *RateLimiter) State(ctx core.UniversalContext) RateLimitState {
+	return r.apply(requestContext(ctx), r.keyFunc(ctx), false)
+}
+
+// Reset сбрасывает лимит для пользователя
+func (r *RateLimiter) Reset(userID int64) error {
+	return r.ResetKey(fmt.Sprintf("user:%d", userID))
+}
+
+// ResetKey сбрасывает лимит для ключа
+func (r *RateLimiter) ResetKey(key string) error {
+	base := r.baseKey(key)
+	window := r.window()
+	start := time.Now().Truncate(window)
+
+	keys := []string{
+		windowKey(base, start),
+		windowKey(base, start.Add(-window)),
+		base + ":start",
+		base + ":used",
+	}
+
+	return r.backend.Delete(context.Background(), keys...)
+}
+
+// GetLimit возвращает текущий лимит
+func (r *RateLimiter) GetLimit(userID int64) (current, max int, resetAt int64) {
+	state := r.apply(context.Background(), fmt.Sprintf("user:%d", userID), false)
+	if state.ResetAt.IsZero() {
+		return 0, state.Limit, 0
+	}
+
+	return state.Limit - state.Remaining, state.Limit, state.ResetAt.Unix()
+}
+
+// Close освобождает ресурсы ограничителя
+func (r *RateLimiter) Close() {
+	if memory, ok := r.backend.(*MemoryRateLimitBackend); ok && r.owned {
+		memory.Close()
+	}
+}
+
+// apply применяет стратегию к счетчикам ключа
+func (r *RateLimiter) apply(ctx context.Context, key string, consume bool) RateLimitState {
+	base := r.baseKey(key)
+	now := time.Now()
+
+	var state RateLimitState
+	var err error
+	switch r.config.Load().Strategy {
+	case RateLimitFixedWindow:
+		state, err = r.fixedWindow(ctx, base, now, consume)
+	case RateLimitTokenBucket:
+		state, err = r.tokenBucket(ctx, base, now, consume)
+	default:
+		state, err = r.slidingWindow(ctx, base, now, consume)
+	}
+
+	// Хранилище недоступно - не блокируем пользователей
+	if err != nil {
+		return RateLimitState{
+			Allowed:   true,
+			Limit:     r.limit(),
+			Remaining: r.limit(),
+		}
+	}
+
+	return state
+}
+
+// fixedWindow фиксированное окно: не более Requests запросов за окно
+// Окна выровнены по времени, поэтому совпадают на всех репликах
+func (r *RateLimiter) fixedWindow(ctx context.Context, base string, now time.Time, consume bool) (RateLimitState, error) {
+	window := r.window()
+	start := now.Truncate(window)
+	key := windowKey(base, start)
+	limit := int64(r.config.Load().Requests)
+
+	count, err := r.count(ctx, key, window, consume)
+	if err != nil {
+		return RateLimitState{}, err
+	}
+
+	state := RateLimitState{
+		Limit:   int(limit),
+		ResetAt: start.Add(window),
+	}
+
+	if count >= limit {
+		state.RetryAfter = state.ResetAt.Sub(now)
+		return state, nil
+	}
+
+	if consume {
+		count++
+	}
+	state.Allowed = true
+	state.Remaining = int(limit - count)
+	return state, nil
+}
+
+// slidingWindow скользящее окно: число запросов оценивается как
+// запросы текущего окна плюс доля запросов предыдущего окна
+func (r *RateLimiter) slidingWindow(ctx context.Context, base string, now time.Time, consume bool) (RateLimitState, error) {
+	window := r.window()
+	start := now.Truncate(window)
+	currKey := windowKey(base, start)
+	limit := int64(r.config.Load().Requests)
+
+	prev, err := r.backend.Get(ctx, windowKey(base, start.Add(-window)))
+	if err != nil {
+		return RateLimitState{}, err
+	}
+
+	count, err := r.count(ctx, currKey, 2*window, consume)
+	if err != nil {
+		return RateLimitState{}, err
+	}
+
+	weight := 1 - float64(now.Sub(start))/float64(window)
+	estimated := float64(prev)*weight + float64(count)
+
+	state := RateLimitState{
+		Limit:   int(limit),
+		ResetAt: start.Add(window),
+	}
+	if count > 0 || consume {
+		state.ResetAt = start.Add(2 * window)
+	}
+
+	if estimated+1 > float64(limit) {
+		// Отказанный запрос не должен занимать место в окне
+		if consume {
+			if _, err := r.backend.Increment(ctx, currKey, -1, 2*window); err != nil {
+				return RateLimitState{}, err
+			}
+		}
+		state.RetryAfter = slidingRetryAfter(prev, count, start, now, window, limit)
+		return state, nil
+	}
+
+	if consume {
+		estimated++
+	}
+	state.Allowed = true
+	state.Remaining = int(math.Max(0, math.Floor(float64(limit)-estimated)))
+	return state, nil
+}
+
+// slidingRetryAfter вычисляет, когда оценка опустится ниже лимита
+func slidingRetryAfter(prev, count int64, start, now time.Time, window time.Duration, limit int64) time.Duration {
+	windowEnd := start.Add(window)
+
+	// Внутри текущего окна освобождается только доля предыдущего
+	if prev > 0 && count+1 <= limit {
+		// prev*(1 - t/window) + count + 1 <= limit
+		need := 1 - float64(limit-count-1)/float64(prev)
+		at := start.Add(time.Duration(need * float64(window)))
+		if at.After(now) {
+			return at.Sub(now)
+		}
+	}
+
+	// В следующем окне текущие запросы становятся предыдущими
+	if count+1 <= limit {
+		return windowEnd.Sub(now)
+	}
+	need := 1 - float64(limit-1)/float64(count)
+	return windowEnd.Add(time.Duration(need * float64(window))).Sub(now)
+}
+
+// tokenBucket корзина токенов: скорость Requests/Window, емкость BurstSize
+// Хранит время создания корзины и число израсходованных милли-токенов,
+// доступные токены = емкость + пополнение с момента создания - израсходовано
+func (r *RateLimiter) tokenBucket(ctx context.Context, base string, now time.Time, consume bool) (RateLimitState, error) {
+	const token = 1000
+
+	capacity := int64(r.limit()) * token
+	rate := float64(r.config.Load().Requests) * token / float64(r.window().Milliseconds())
+	ttl := time.Duration(float64(capacity)/rate)*time.Millisecond + r.window()
+	usedKey := base + ":used"
+
+	origin, err := r.bucketOrigin(ctx, base+":start", now, ttl, consume)
+	if err != nil {
+		return RateLimitState{}, err
+	}
+
+	// Израсходовано до текущего запроса
+	var used, cost int64
+	if consume {
+		cost = token
+		used, err = r.backend.Increment(ctx, usedKey, cost, ttl)
+		used -= cost
+	} else {
+		used, err = r.backend.Get(ctx, usedKey)
+	}
+	if err != nil {
+		return RateLimitState{}, err
+	}
+
+	available := capacity + int64(float64(now.Sub(origin).Milliseconds())*rate) - used
+
+	// Корзина не может быть полнее емкости: списываем лишнее пополнение
+	if available > capacity {
+		if consume {
+			if _, err := r.backend.Increment(ctx, usedKey, available-capacity, ttl); err != nil {
+				return RateLimitState{}, err
+			}
+		}
+		available = capacity
+	}
+
+	state := RateLimitState{Limit: r.limit()}
+
+	if available < token {
+		if consume {
+			if _, err := r.backend.Increment(ctx, usedKey, -cost, ttl); err != nil {
+				return RateLimitState{}, err
+			}
+		}
+		state.RetryAfter = time.Duration(float64(token-available)/rate) * time.Millisecond
+		state.ResetAt = now.Add(time.Duration(float64(capacity-available)/rate) * time.Millisecond)
+		return state, nil
+	}
+
+	available -= cost
+	state.Allowed = true
+	state.Remaining = int(available / token)
+	state.ResetAt = now.Add(time.Duration(float64(capacity-available)/rate) * time.Millisecond)
+	return state, nil
+}
+
+// bucketOrigin возвращает время создания корзины, создавая ее при необходимости
+// Начало устанавливается инкрементом: если две реплики создают корзину
+// одновременно, вторая откатывает свой инкремент
+func (r *RateLimiter) bucketOrigin(ctx context.Context, key string, now time.Time, ttl time.Duration, create bool) (time.Time, error) {
+	if !create {
+		value, err := r.backend.Get(ctx, key)
+		if err != nil || value == 0 {
+			return now, err
+		}
+		return time.UnixMilli(value), nil
+	}
+
+	value, err := r.backend.Increment(ctx, key, 0, ttl)
+	if err != nil {
+		return now, err
+	}
+
+	if value == 0 {
+		ms := now.UnixMilli()
+		if value, err = r.backend.Increment(ctx, key, ms, ttl); err != nil {
+			return now, err
+		}
+		if value != ms {
+			if value, err = r.backend.Increment(ctx, key, -ms, ttl); err != nil {
+				return now, err
+			}
+		}
+	}
+
+	return time.UnixMilli(value), nil
+}
+
+// count возвращает значение счетчика до текущего запроса
+// При consume счетчик атомарно увеличивается
+func (r *RateLimiter) count(ctx context.Context, key string, ttl time.Duration, consume bool) (int64, error) {
+	if !consume {
+		return r.backend.Get(ctx, key)
+	}
+
+	value, err := r.backend.Increment(ctx, key, 1, ttl)
+	if err != nil {
+		return 0, err
+	}
+	return value - 1, nil
+}
+
+// baseKey строит ключ счетчика
+func (r *RateLimiter) baseKey(key string) string {
+	return "ratelimit:" + r.scope + key
+}
+
+// windowKey строит ключ счетчика окна
+func windowKey(base string, start time.Time) string {
+	return fmt.Sprintf("%s:%d", base, start.Unix())
+}
+
+// requestContext возвращает context.Context запроса
+func requestContext(ctx core.UniversalContext) context.Context {
+	if c := ctx.Context(); c != nil {
+		return c
+	}
+	return context.Background()
+}
+
+// limit возвращает максимальное количество запросов с учетом всплеска
+func (r *RateLimiter) limit() int {
+	config := r.config.Load()
+	if config.Strategy == RateLimitTokenBucket && config.BurstSize > 0 {
+		return config.BurstSize
+	}
+	return config.Requests
+}
+
+// window возвращает длительность окна
+func (r *RateLimiter) window() time.Duration {
+	window := r.config.Load().Window
+	if window <= 0 {
+		return time.Second
+	}
+	return time.Duration(window) * time.Second
+}
@@ -0,0 +1,249 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// DefaultRateLimitCleanupInterval интервал очистки просроченных счетчиков в памяти
+const DefaultRateLimitCleanupInterval = time.Minute
+
+// RateLimitBackend хранилище счетчиков rate limiting
+// Все стратегии выражены через атомарные инкременты, поэтому один backend
+// может разделяться несколькими репликами бота
+type RateLimitBackend interface {
+	// Increment атомарно увеличивает счетчик на delta, продлевает TTL
+	// и возвращает новое значение
+	Increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+
+	// Get возвращает значение счетчика (0, если счетчика нет)
+	Get(ctx context.Context, key string) (int64, error)
+
+	// Delete удаляет счетчики
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// MemoryRateLimitBackend хранит счетчики в памяти процесса
+type MemoryRateLimitBackend struct {
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// memoryCounter счетчик в памяти
+type memoryCounter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryRateLimitBackend создает backend в памяти
+// Просроченные счетчики удаляются в фоне каждые cleanupInterval
+func NewMemoryRateLimitBackend(cleanupInterval time.Duration) *MemoryRateLimitBackend {
+	if cleanupInterval <= 0 {
+		cleanupInterval = DefaultRateLimitCleanupInterval
+	}
+
+	b := &MemoryRateLimitBackend{
+		counters: make(map[string]*memoryCounter),
+		stop:     make(chan struct{}),
+	}
+	go b.cleanupLoop(cleanupInterval)
+
+	return b
+}
+
+// Increment увеличивает счетчик
+func (b *MemoryRateLimitBackend) Increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	counter, exists := b.counters[key]
+	if !exists || now.After(counter.expiresAt) {
+		counter = &memoryCounter{}
+		b.counters[key] = counter
+	}
+
+	counter.value += delta
+	counter.expiresAt = now.Add(ttl)
+
+	return counter.value, nil
+}
+
+// Get возвращает значение счетчика
+func (b *MemoryRateLimitBackend) Get(ctx context.Context, key string) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	counter, exists := b.counters[key]
+	if !exists || time.Now().After(counter.expiresAt) {
+		return 0, nil
+	}
+	return counter.value, nil
+}
+
+// Delete удаляет счетчики
+func (b *MemoryRateLimitBackend) Delete(ctx context.Context, keys ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range keys {
+		delete(b.counters, key)
+	}
+	return nil
+}
+
+// Len возвращает количество счетчиков
+func (b *MemoryRateLimitBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.counters)
+}
+
+// Close останавливает фоновую очистку
+func (b *MemoryRateLimitBackend) Close() {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+}
+
+// cleanupLoop периодически удаляет просроченные счетчики
+func (b *MemoryRateLimitBackend) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.evictExpired()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// evictExpired удаляет просроченные счетчики
+func (b *MemoryRateLimitBackend) evictExpired() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for key, counter := range b.counters {
+		if now.After(counter.expiresAt) {
+			delete(b.counters, key)
+		}
+	}
+}
+
+// CacheRateLimitBackend хранит счетчики в core.Cache (Redis, Memcached и т.п.)
+// Если кеш реализует core.CounterCache, инкременты атомарны между репликами.
+// Иначе используется Get+Set, атомарный только в пределах процесса
+type CacheRateLimitBackend struct {
+	cache core.Cache
+	mu    sync.Mutex
+}
+
+// NewCacheRateLimitBackend создает backend поверх кеша
+func NewCacheRateLimitBackend(cache core.Cache) *CacheRateLimitBackend {
+	return &CacheRateLimitBackend{cache: cache}
+}
+
+// Increment увеличивает счетчик
+func (b *CacheRateLimitBackend) Increment(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	seconds := ttlSeconds(ttl)
+
+	if counter, ok := b.cache.(core.CounterCache); ok {
+		value, err := counter.Increment(ctx, key, delta, seconds)
+		if err != nil {
+			return 0, fmt.Errorf("failed to increment %s: %w", key, err)
+		}
+		return value, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	value, err := b.get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	value += delta
+	if err := b.cache.Set(ctx, key, value, seconds); err != nil {
+		return 0, fmt.Errorf("failed to set %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// Get возвращает значение счетчика
+func (b *CacheRateLimitBackend) Get(ctx context.Context, key string) (int64, error) {
+	return b.get(ctx, key)
+}
+
+// Delete удаляет счетчики
+func (b *CacheRateLimitBackend) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := b.cache.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// get читает счетчик из кеша
+func (b *CacheRateLimitBackend) get(ctx context.Context, key string) (int64, error) {
+	exists, err := b.cache.Exists(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check %s: %w", key, err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	raw, err := b.cache.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+
+	return toInt64(raw)
+}
+
+// ttlSeconds переводит TTL в секунды (с округлением вверх)
+func ttlSeconds(ttl time.Duration) int {
+	seconds := int(math.Ceil(ttl.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// toInt64 приводит значение из кеша к int64
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected counter type %T", value)
+	}
+}
@@ -0,0 +1,122 @@
+package routing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// rateStep запрос через offset после начала окна
+type rateStep struct {
+	offset    time.Duration
+	allowed   bool
+	remaining int
+}
+
+func TestRateLimitStrategies(t *testing.T) {
+	// Начало минутного окна: окна выровнены по времени
+	t0 := time.Unix(1700000040, 0)
+
+	tests := []struct {
+		name   string
+		config RateLimitConfig
+		steps  []rateStep
+	}{
+		{
+			name:   "fixed window",
+			config: RateLimitConfig{Requests: 3, Window: 60, Strategy: RateLimitFixedWindow},
+			steps: []rateStep{
+				{offset: 0, allowed: true, remaining: 2},
+				{offset: time.Second, allowed: true, remaining: 1},
+				{offset: 2 * time.Second, allowed: true, remaining: 0},
+				{offset: 59 * time.Second, allowed: false},
+				{offset: 60 * time.Second, allowed: true, remaining: 2},
+			},
+		},
+		{
+			name:   "sliding window",
+			config: RateLimitConfig{Requests: 4, Window: 60, Strategy: RateLimitSlidingWindow},
+			steps: []rateStep{
+				{offset: 0, allowed: true, remaining: 3},
+				{offset: 0, allowed: true, remaining: 2},
+				{offset: 0, allowed: true, remaining: 1},
+				{offset: 0, allowed: true, remaining: 0},
+				{offset: 30 * time.Second, allowed: false},
+				// Середина следующего окна: предыдущее окно весит половину (4 * 0.5 = 2)
+				{offset: 90 * time.Second, allowed: true, remaining: 1},
+				{offset: 90 * time.Second, allowed: true, remaining: 0},
+				{offset: 90 * time.Second, allowed: false},
+			},
+		},
+		{
+			name:   "token bucket burst and refill",
+			config: RateLimitConfig{Requests: 1, Window: 1, BurstSize: 3, Strategy: RateLimitTokenBucket},
+			steps: []rateStep{
+				{offset: 0, allowed: true, remaining: 2},
+				{offset: 0, allowed: true, remaining: 1},
+				{offset: 0, allowed: true, remaining: 0},
+				{offset: 0, allowed: false},
+				{offset: time.Second, allowed: true, remaining: 0},
+				{offset: time.Second, allowed: false},
+				// Пополнение не превышает емкость
+				{offset: time.Minute, allowed: true, remaining: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMemoryRateLimitBackend(time.Minute)
+			defer backend.Close()
+
+			config := tt.config
+			limiter := NewRateLimiterWithBackend(&config, backend)
+			base := limiter.baseKey("user:1")
+
+			for i, step := range tt.steps {
+				now := t0.Add(step.offset)
+
+				var state RateLimitState
+				var err error
+				switch config.Strategy {
+				case RateLimitFixedWindow:
+					state, err = limiter.fixedWindow(context.Background(), base, now, true)
+				case RateLimitSlidingWindow:
+					state, err = limiter.slidingWindow(context.Background(), base, now, true)
+				case RateLimitTokenBucket:
+					state, err = limiter.tokenBucket(context.Background(), base, now, true)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if state.Allowed != step.allowed {
+					t.Fatalf("request %d at +%s: allowed = %v, want %v", i+1, step.offset, state.Allowed, step.allowed)
+				}
+				if step.allowed && state.Remaining != step.remaining {
+					t.Fatalf("request %d at +%s: remaining = %d, want %d", i+1, step.offset, state.Remaining, step.remaining)
+				}
+				if !step.allowed && state.RetryAfter <= 0 {
+					t.Fatalf("request %d at +%s: denied without RetryAfter", i+1, step.offset)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitFixedWindowRetryAfter(t *testing.T) {
+	backend := NewMemoryRateLimitBackend(time.Minute)
+	defer backend.Close()
+
+	limiter := NewRateLimiterWithBackend(&RateLimitConfig{Requests: 1, Window: 60, Strategy: RateLimitFixedWindow}, backend)
+	base := limiter.baseKey("user:1")
+	t0 := time.Unix(1700000040, 0)
+
+	if state, _ := limiter.fixedWindow(context.Background(), base, t0, true); !state.Allowed {
+		t.Fatal("first request denied")
+	}
+	state, _ := limiter.fixedWindow(context.Background(), base, t0.Add(20*time.Second), true)
+	if state.Allowed || state.RetryAfter != 40*time.Second || state.RetryAfterSeconds() != 40 {
+		t.Fatalf("state = %+v, want denied with RetryAfter 40s", state)
+	}
+}
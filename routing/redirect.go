@@ -0,0 +1,63 @@
+package routing
+
+import (
+	"strings"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// maxRedirects максимальное количество перенаправлений за один запрос
+const maxRedirects = 5
+
+// redirectContext контекст перенаправленного запроса
+// Подменяет текст и тип сообщения, остальное берет из исходного контекста
+type redirectContext struct {
+	core.UniversalContext
+	target *core.RedirectTarget
+}
+
+// newRedirectContext создает контекст для перенаправления
+func newRedirectContext(ctx core.UniversalContext, target *core.RedirectTarget) *redirectContext {
+	for key, value := range target.Params {
+		ctx.SetParam(key, value)
+	}
+
+	return &redirectContext{
+		UniversalContext: ctx,
+		target:           target,
+	}
+}
+
+// GetText возвращает цель перенаправления
+func (c *redirectContext) GetText() string {
+	return c.target.Target
+}
+
+// GetData возвращает данные исходного контекста с подмененной callback data
+func (c *redirectContext) GetData() map[string]interface{} {
+	data := make(map[string]interface{}, len(c.UniversalContext.GetData())+1)
+	for k, v := range c.UniversalContext.GetData() {
+		data[k] = v
+	}
+	if c.target.AsCallback {
+		data["callback_data"] = c.target.Target
+	} else {
+		delete(data, "callback_data")
+	}
+	return data
+}
+
+// IsCommand проверяет, является ли цель командой
+func (c *redirectContext) IsCommand() bool {
+	return !c.target.AsCallback && strings.HasPrefix(c.target.Target, "/")
+}
+
+// IsCallback проверяет, является ли цель callback'ом
+func (c *redirectContext) IsCallback() bool {
+	return c.target.AsCallback
+}
+
+// IsMessage проверяет, является ли цель обычным сообщением
+func (c *redirectContext) IsMessage() bool {
+	return !c.IsCommand() && !c.IsCallback()
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/tracing"
 	"sort"
 	"sync"
+	"sync/atomic"
 )
 
 // Router основная реализация роутера
@@ -34,6 +36,28 @@ type Router struct {
 	// dependencies зависимости для модулей
 	dependencies core.Dependencies
 
+	// rateLimitBackend хранилище счетчиков лимитов маршрутов
+	rateLimitBackend RateLimitBackend
+	backendOnce      sync.Once
+
+	// states состояние модулей (учет запросов, подписки на события)
+	states map[string]*moduleState
+
+	// generation счетчик регистраций модулей
+	generation atomic.Int64
+
+	// health проверки здоровья модулей
+	health healthMonitor
+
+	// features флаги функциональности маршрутов и модулей
+	features core.FeatureFlags
+
+	// metrics метрики маршрутизации (router.routes_matched, router.handler_duration_seconds)
+	metrics core.Metrics
+
+	// tracer трассировка middleware, поиска маршрута и обработчика
+	tracer *tracing.Tracer
+
 	// started флаг запуска
 	started bool
 
@@ -43,27 +67,33 @@ type Router struct {
 
 // compiledRoute скомпилированный маршрут
 type compiledRoute struct {
-	pattern  RoutePattern
-	module   string
-	compiled bool
+	pattern RoutePattern
+	module  string
+	state   *moduleState
 }
 
 // wildcardHandler обработчик wildcard
 type wildcardHandler struct {
 	module   core.WildcardModule
 	priority int
+	state    *moduleState
 }
 
 // NewRouter создает новый роутер
 func NewRouter(eventBus core.EventBus, logger core.Logger, config core.Config) *Router {
 	return &Router{
 		modules:     make(map[string]core.Module),
+		states:      make(map[string]*moduleState),
 		routes:      make([]compiledRoute, 0),
 		wildcards:   make([]wildcardHandler, 0),
 		middlewares: make([]Middleware, 0),
 		eventBus:    eventBus,
 		logger:      logger,
 		config:      config,
+		health: healthMonitor{
+			interval: DefaultHealthCheckInterval,
+			timeout:  DefaultHealthCheckTimeout,
+		},
 	}
 }
 
@@ -72,83 +102,107 @@ func (r *Router) SetDependencies(deps core.Dependencies) {
 	r.dependencies = deps
 }
 
-// RegisterModule регистрирует модуль
-func (r *Router) RegisterModule(module core.Module) error {
+// SetRateLimitBackend устанавливает хранилище счетчиков лимитов маршрутов
+// Должен вызываться до регистрации модулей. Для нескольких реплик используйте
+// общий кеш: SetRateLimitBackend(NewCacheRateLimitBackend(cache))
+func (r *Router) SetRateLimitBackend(backend RateLimitBackend) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if r.started {
-		return fmt.Errorf("cannot register module after router started")
+	r.rateLimitBackend = backend
+}
+
+// RegisterModule регистрирует модуль
+// После запуска роутера модуль можно зарегистрировать во время работы:
+// он будет инициализирован и запущен до того, как начнет получать запросы
+func (r *Router) RegisterModule(module core.Module) error {
+	return r.registerModule(module, false)
+}
+
+// RegisterWildcard регистрирует wildcard обработчик
+func (r *Router) RegisterWildcard(module core.WildcardModule) error {
+	if err := r.registerModule(module, true); err != nil {
+		return err
 	}
 
+	r.logger.Info("Wildcard module registered", "name", module.Name(), "priority", module.Priority())
+
+	return nil
+}
+
+// registerModule регистрирует модуль (и wildcard обработчик)
+func (r *Router) registerModule(module core.Module, wildcard bool) error {
 	name := module.Name()
-	if _, exists := r.modules[name]; exists {
-		return fmt.Errorf("module %s already registered", name)
+
+	r.mu.RLock()
+	_, exists := r.modules[name]
+	started := r.started
+	var depErr error
+	if started && !exists {
+		depErr = r.checkGraphLocked(module)
 	}
+	r.mu.RUnlock()
 
-	// Инициализируем модуль
-	if r.dependencies != nil {
-		if err := module.Init(r.dependencies); err != nil {
-			return fmt.Errorf("failed to init module %s: %w", name, err)
-		}
+	if exists {
+		return fmt.Errorf("module %s already registered", name)
 	}
 
-	// Сохраняем модуль
-	r.modules[name] = module
-
-	// Регистрируем маршруты
-	for _, iPattern := range module.Routes() {
-		// Приводим к нашему типу RoutePattern
-		if pattern, ok := iPattern.(RoutePattern); ok {
-			route := compiledRoute{
-				pattern: pattern,
-				module:  name,
-			}
-			// pattern.Module = name // TODO: добавить поле Module в RoutePattern если нужно
-			r.routes = append(r.routes, route)
-		}
+	// Во время работы зависимости должны быть уже зарегистрированы (и запущены)
+	if depErr != nil {
+		return fmt.Errorf("cannot register module %s: %w", name, depErr)
 	}
 
-	// Регистрируем события если модуль поддерживает
-	if eventAware, ok := module.(core.EventAwareModule); ok {
-		for _, sub := range eventAware.Events() {
-			if err := r.eventBus.Subscribe(sub.EventType, sub.Handler); err != nil {
-				return fmt.Errorf("failed to subscribe to event %s: %w", sub.EventType, err)
-			}
-		}
+	ctx := context.Background()
+	prepared, err := r.prepareModule(ctx, module, wildcard)
+	if err != nil {
+		return err
 	}
 
-	r.logger.Info("Module registered", "name", name, "routes", len(module.Routes()))
+	registered := false
+	defer func() {
+		if !registered {
+			r.releaseDependencies(prepared.state.owner)
+		}
+	}()
 
-	return nil
-}
+	// Во время работы модуль запускается до подключения маршрутов
+	if started {
+		if err := r.validateDependencies(); err != nil {
+			r.publishLifecycle(ctx, name, LifecycleError, err.Error())
+			return fmt.Errorf("cannot register module %s: %w", name, err)
+		}
+		if err := r.launchModule(ctx, module); err != nil {
+			r.publishLifecycle(ctx, name, LifecycleError, err.Error())
+			return fmt.Errorf("failed to start module %s: %w", name, err)
+		}
+	}
 
-// RegisterWildcard регистрирует wildcard обработчик
-func (r *Router) RegisterWildcard(module core.WildcardModule) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if r.started {
-		return fmt.Errorf("cannot register wildcard after router started")
+	if _, exists := r.modules[name]; exists {
+		r.mu.Unlock()
+		r.stopModule(ctx, module, started)
+		return fmt.Errorf("module %s already registered", name)
 	}
+	r.installLocked(prepared)
+	r.mu.Unlock()
 
-	// Регистрируем как обычный модуль
-	if err := r.RegisterModule(module); err != nil {
+	// Регистрируем события если модуль поддерживает
+	if err := r.subscribe(prepared.state); err != nil {
+		r.mu.Lock()
+		r.removeLocked(name)
+		r.mu.Unlock()
+		r.stopModule(ctx, module, started)
 		return err
 	}
 
-	// Добавляем в wildcard обработчики
-	r.wildcards = append(r.wildcards, wildcardHandler{
-		module:   module,
-		priority: module.Priority(),
-	})
+	registered = true
 
-	// Сортируем по приоритету (больше = выше)
-	sort.Slice(r.wildcards, func(i, j int) bool {
-		return r.wildcards[i].priority > r.wildcards[j].priority
-	})
+	r.publishLifecycle(ctx, name, LifecycleRegistered, module.Version())
+	if started {
+		r.publishLifecycle(ctx, name, LifecycleStarted, "ok")
+	}
 
-	r.logger.Info("Wildcard module registered", "name", module.Name(), "priority", module.Priority())
+	r.logger.Info("Module registered", "name", name, "routes", len(prepared.routes))
 
 	return nil
 }
@@ -158,27 +212,39 @@ func (r *Router) RegisterMiddleware(mw Middleware) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.middlewares = append(r.middlewares, mw)
+	// Новый срез: выполняющиеся запросы работают со своей копией цепочки
+	middlewares := make([]Middleware, 0, len(r.middlewares)+1)
+	middlewares = append(middlewares, r.middlewares...)
+	middlewares = append(middlewares, mw)
 
 	// Сортируем по приоритету
-	sort.Slice(r.middlewares, func(i, j int) bool {
-		return r.middlewares[i].Priority() > r.middlewares[j].Priority()
+	sort.SliceStable(middlewares, func(i, j int) bool {
+		return middlewares[i].Priority() > middlewares[j].Priority()
 	})
+	r.middlewares = middlewares
 
 	r.logger.Info("Middleware registered", "name", mw.Name(), "priority", mw.Priority())
 }
 
 // Route маршрутизирует сообщение
+// Блокировка держится только на время чтения таблиц, обработчики выполняются без нее
 func (r *Router) Route(ctx core.UniversalContext) core.Response {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
+	middlewares := r.middlewares
+	tracer := r.tracer
+	r.mu.RUnlock()
+
+	// Логгер запроса с полями пользователя, чата и источника
+	r.attachRequestLogger(ctx)
 
 	// Применяем middleware
 	handler := r.routeInternal
-	for i := len(r.middlewares) - 1; i >= 0; i-- {
-		mw := r.middlewares[i]
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		mw := middlewares[i]
 		nextHandler := handler
 		handler = func(c core.UniversalContext) core.Response {
+			_, end := startSpan(tracer, c, "middleware "+mw.Name())
+			defer end()
 			return mw.Process(c, nextHandler)
 		}
 	}
@@ -186,8 +252,49 @@ func (r *Router) Route(ctx core.UniversalContext) core.Response {
 	return handler(ctx)
 }
 
-// routeInternal внутренняя маршрутизация
+// routeInternal внутренняя маршрутизация с обработкой перенаправлений
 func (r *Router) routeInternal(ctx core.UniversalContext) core.Response {
+	response := r.dispatch(ctx, "")
+
+	visited := make(map[string]bool)
+	for hops := 0; response != nil && response.Type() == core.ResponseTypeRedirect; hops++ {
+		redirect, ok := response.(core.RedirectResponse)
+		if !ok || redirect.Redirect() == nil {
+			r.logger.Error("Redirect response without target", "user", ctx.GetUserID())
+			return core.NewSilentResponse()
+		}
+
+		target := redirect.Redirect()
+		key := target.Module + "|" + target.Target
+
+		// Защита от циклических перенаправлений
+		if visited[key] || hops >= maxRedirects {
+			r.logger.Error("Redirect loop detected",
+				"target", target.Target,
+				"module", target.Module,
+				"hops", hops,
+				"user", ctx.GetUserID(),
+			)
+			return core.NewMessage("❌ Произошла внутренняя ошибка. Попробуйте позже.")
+		}
+		visited[key] = true
+
+		r.logger.Debug("Redirecting",
+			"target", target.Target,
+			"module", target.Module,
+			"user", ctx.GetUserID(),
+		)
+
+		ctx = newRedirectContext(ctx, target)
+		response = r.dispatch(ctx, target.Module)
+	}
+
+	return response
+}
+
+// dispatch находит маршрут и выполняет обработчик
+// Если module не пустой, поиск ограничивается маршрутами этого модуля
+func (r *Router) dispatch(ctx core.UniversalContext, module string) core.Response {
 	// Получаем текст для матчинга
 	text := ctx.GetText()
 	if ctx.IsCallback() {
@@ -196,83 +303,106 @@ func (r *Router) routeInternal(ctx core.UniversalContext) core.Response {
 		}
 	}
 
-	// Компилируем маршруты при первом использовании
-	r.compileRoutesOnce()
+	// Таблицы не изменяются на месте - достаточно взять текущие
+	// (маршруты уже скомпилированы и отсортированы по приоритету)
+	r.mu.RLock()
+	routes := r.routes
+	wildcards := r.wildcards
+	features := r.features
+	metrics := r.metrics
+	tracer := r.tracer
+	r.mu.RUnlock()
 
 	// Ищем подходящий маршрут
+	matchSpan, endMatch := startSpan(tracer, ctx, "router.match")
+
 	var matchedRoute *compiledRoute
 	var matchedParams map[string]string
-
-	// Сортируем маршруты по приоритету
-	routes := make([]compiledRoute, len(r.routes))
-	copy(routes, r.routes)
-	sort.Slice(routes, func(i, j int) bool {
-		return routes[i].pattern.Priority > routes[j].pattern.Priority
-	})
+	var matchedFallback core.HandlerFunc
 
 	// Проверяем маршруты
 	for _, route := range routes {
+		if module != "" && route.module != module {
+			continue
+		}
+
 		// Проверяем тип маршрута
 		if !route.pattern.MatchType(ctx) {
 			continue
 		}
 
 		// Проверяем паттерн
-		if matched, params := route.pattern.Match(text); matched {
+		matched, params := route.pattern.Match(text)
+		if !matched {
+			continue
+		}
+
+		// Маршрут за выключенным флагом пропускается или отвечает запасным обработчиком
+		// (модуль в процессе выгрузки новые запросы не принимает)
+		if fallback, allowed := gateRoute(features, ctx, &route); allowed && route.state.inflight.acquire() {
 			matchedRoute = &route
 			matchedParams = params
+			matchedFallback = fallback
 			break
 		}
 	}
 
 	// Если маршрут найден
 	if matchedRoute != nil {
+		defer matchedRoute.state.inflight.release()
+
 		// Устанавливаем параметры в контекст
 		for key, value := range matchedParams {
 			ctx.SetParam(key, value)
 		}
 
-		// Логируем
-		r.logger.Debug("Route matched",
-			"module", matchedRoute.module,
-			"pattern", matchedParams["_pattern"],
-			"user", ctx.GetUserID(),
-		)
+		// Логгер запроса дополняется модулем и маршрутом
+		logger := r.attachRouteLogger(ctx, matchedRoute.module, matchedParams["_pattern"])
+		logger.Debug("Route matched")
+
+		// Флаг маршрута выключен - запасной обработчик (с теми же проверками, без кеша)
+		execute := matchedRoute.pattern.Execute
+		if matchedFallback != nil {
+			fallback := matchedRoute.pattern
+			fallback.Handler = matchedFallback
+			fallback.Cache = nil
+			execute = fallback.Execute
+		}
+
+		matchSpan.SetAttributes(tracing.Attr("module", matchedRoute.module), tracing.Attr("route", matchedParams["_pattern"]))
+		endMatch()
 
-		// Выполняем обработчик
-		return matchedRoute.pattern.Execute(ctx)
+		// Выполняем обработчик (ошибки и паники передаются в OnError модуля)
+		return r.execute(ctx, tracer, metrics, matchedRoute.state.module, matchedParams["_pattern"], execute)
 	}
 
 	// Проверяем wildcard обработчики
-	for _, wc := range r.wildcards {
-		if wc.module.ShouldHandle(ctx) {
-			r.logger.Debug("Wildcard matched",
-				"module", wc.module.Name(),
-				"user", ctx.GetUserID(),
-			)
-			return wc.module.HandleWildcard(ctx)
+	for _, wc := range wildcards {
+		if module != "" && wc.module.Name() != module {
+			continue
 		}
-	}
+		if !featureEnabled(features, ctx, wc.state.feature) {
+			continue
+		}
+		if wc.module.ShouldHandle(ctx) && wc.state.inflight.acquire() {
+			defer wc.state.inflight.release()
 
-	// Маршрут не найден
-	r.logger.Debug("No route matched",
-		"text", text,
-		"user", ctx.GetUserID(),
-	)
+			logger := r.attachRouteLogger(ctx, wc.module.Name(), "*")
+			logger.Debug("Wildcard matched")
 
-	return core.NewSilentResponse()
-}
+			matchSpan.SetAttributes(tracing.Attr("module", wc.module.Name()), tracing.Attr("route", "*"))
+			endMatch()
 
-// compileRoutesOnce компилирует маршруты один раз
-func (r *Router) compileRoutesOnce() {
-	for i := range r.routes {
-		if !r.routes[i].compiled {
-			if err := r.routes[i].pattern.Compile(); err != nil {
-				r.logger.Error("Failed to compile route", "error", err, "module", r.routes[i].module)
-			}
-			r.routes[i].compiled = true
+			return r.execute(ctx, tracer, metrics, wc.module, "*", wc.module.HandleWildcard)
 		}
 	}
+
+	// Маршрут не найден
+	matchSpan.SetAttributes(tracing.Attr("matched", false))
+	endMatch()
+	core.LoggerFrom(ctx, r.logger).Debug("No route matched", "text", text)
+
+	return core.NewSilentResponse()
 }
 
 // GetModule получает модуль по имени
@@ -306,9 +436,21 @@ func (r *Router) Start(ctx context.Context) error {
 		return fmt.Errorf("router already started")
 	}
 
-	// Запускаем все модули
-	for name, module := range r.modules {
-		if err := module.Start(ctx); err != nil {
+	// Зависимости запускаются раньше зависящих от них модулей
+	order, err := moduleOrder(r.modules)
+	if err != nil {
+		return fmt.Errorf("invalid module dependencies: %w", err)
+	}
+
+	// Все зависимости, объявленные модулями в Init, должны быть зарегистрированы
+	if err := r.validateDependencies(); err != nil {
+		return err
+	}
+
+	// Запускаем все модули, при ошибке останавливаем уже запущенные
+	for i, name := range order {
+		if err := r.launchModule(ctx, r.modules[name]); err != nil {
+			r.rollbackStart(ctx, order[:i])
 			return fmt.Errorf("failed to start module %s: %w", name, err)
 		}
 		r.logger.Info("Module started", "name", name)
@@ -317,11 +459,13 @@ func (r *Router) Start(ctx context.Context) error {
 	// Запускаем шину событий
 	if r.eventBus != nil {
 		if err := r.eventBus.Start(ctx); err != nil {
+			r.rollbackStart(ctx, order)
 			return fmt.Errorf("failed to start event bus: %w", err)
 		}
 	}
 
 	r.started = true
+	r.startHealthMonitor()
 	r.logger.Info("Router started", "modules", len(r.modules))
 
 	return nil
@@ -329,6 +473,9 @@ func (r *Router) Start(ctx context.Context) error {
 
 // Stop останавливает роутер и все модули
 func (r *Router) Stop(ctx context.Context) error {
+	// Фоновая проверка здоровья читает таблицы роутера - останавливаем до блокировки
+	r.stopHealthMonitor()
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -336,9 +483,20 @@ func (r *Router) Stop(ctx context.Context) error {
 		return nil
 	}
 
-	// Останавливаем все модули
-	for name, module := range r.modules {
-		if err := module.Stop(ctx); err != nil {
+	// Останавливаем модули в порядке, обратном запуску
+	order, err := moduleOrder(r.modules)
+	if err != nil {
+		r.logger.Error("Invalid module dependencies, stopping in name order", "error", err)
+		order = make([]string, 0, len(r.modules))
+		for name := range r.modules {
+			order = append(order, name)
+		}
+		sort.Strings(order)
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+		if err := r.haltModule(ctx, r.modules[name]); err != nil {
 			r.logger.Error("Failed to stop module", "name", name, "error", err)
 		} else {
 			r.logger.Info("Module stopped", "name", name)
@@ -358,6 +516,18 @@ func (r *Router) Stop(ctx context.Context) error {
 	return nil
 }
 
+// rollbackStart останавливает запущенные модули в обратном порядке
+func (r *Router) rollbackStart(ctx context.Context, started []string) {
+	for i := len(started) - 1; i >= 0; i-- {
+		name := started[i]
+		if err := r.haltModule(ctx, r.modules[name]); err != nil {
+			r.logger.Error("Failed to stop module during rollback", "name", name, "error", err)
+		} else {
+			r.logger.Info("Module stopped (rollback)", "name", name)
+		}
+	}
+}
+
 // GetRoutes возвращает все зарегистрированные маршруты (для отладки)
 func (r *Router) GetRoutes() []RouteInfo {
 	r.mu.RLock()
@@ -1,11 +1,10 @@
 package routing
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"github.com/andranikuz/botkit/core"
-	"sync"
-	"time"
+	"github.com/andranikuz/botkit/events"
 )
 
 // SecurityRule правила безопасности для маршрута
@@ -25,6 +24,16 @@ type SecurityRule struct {
 	// AllowedSources разрешенные источники (telegram, api, websocket)
 	AllowedSources []string
 
+	// AllowedChatTypes разрешенные типы чатов (private, group, supergroup, channel)
+	AllowedChatTypes []core.ChatType
+
+	// RequireChatAdmin требует, чтобы отправитель был администратором чата
+	RequireChatAdmin bool
+
+	// RequireAddressed требует, чтобы сообщение было адресовано боту
+	// (в группах: упоминание, ответ на сообщение бота или /команда@бот)
+	RequireAddressed bool
+
 	// RateLimit ограничение скорости
 	RateLimit *RateLimitConfig
 
@@ -33,6 +42,9 @@ type SecurityRule struct {
 
 	// OnFailure обработчик ошибки безопасности
 	OnFailure SecurityFailureHandler
+
+	// AlertOnCallback отвечать на нажатие кнопки всплывающим alert вместо сообщения в чат
+	AlertOnCallback bool
 }
 
 // RateLimitConfig конфигурация ограничения скорости
@@ -48,6 +60,9 @@ type RateLimitConfig struct {
 
 	// Strategy стратегия (sliding_window, fixed_window, token_bucket)
 	Strategy string
+
+	// Key функция построения ключа (по умолчанию RateLimitKeyUser)
+	Key RateLimitKeyFunc
 }
 
 // ValidatorFunc функция валидации
@@ -58,6 +73,24 @@ type SecurityFailureHandler func(ctx core.UniversalContext, err error) core.Resp
 
 // Check проверяет правила безопасности
 func (s *SecurityRule) Check(ctx core.UniversalContext) error {
+	// Проверяем адресацию (в группах сообщения не для бота игнорируются)
+	if s.RequireAddressed && !ctx.IsAddressedToBot() {
+		return ErrNotAddressed
+	}
+
+	// Проверяем тип чата
+	if len(s.AllowedChatTypes) > 0 {
+		chatType := ctx.GetChatType()
+		if !containsChatType(s.AllowedChatTypes, chatType) {
+			return &ChatTypeError{ChatType: chatType, Allowed: s.AllowedChatTypes}
+		}
+	}
+
+	// Проверяем права администратора чата
+	if s.RequireChatAdmin && !ctx.IsChatAdmin() {
+		return ErrChatAdminRequired
+	}
+
 	// Проверяем аутентификацию
 	if s.RequireAuth && !ctx.IsAuthenticated() {
 		return ErrNotAuthenticated
@@ -72,7 +105,7 @@ func (s *SecurityRule) Check(ctx core.UniversalContext) error {
 	if len(s.RequireRoles) > 0 {
 		userRoles := ctx.GetRoles()
 		if !hasAnyRole(userRoles, s.RequireRoles) {
-			return ErrInsufficientRole
+			return &RoleError{Required: s.RequireRoles, Actual: userRoles}
 		}
 	}
 
@@ -80,7 +113,7 @@ func (s *SecurityRule) Check(ctx core.UniversalContext) error {
 	if len(s.RequirePermissions) > 0 {
 		for _, perm := range s.RequirePermissions {
 			if !ctx.HasPermission(perm) {
-				return fmt.Errorf("%w: %s", ErrInsufficientPermission, perm)
+				return &PermissionError{Permission: perm}
 			}
 		}
 	}
@@ -89,14 +122,14 @@ func (s *SecurityRule) Check(ctx core.UniversalContext) error {
 	if len(s.AllowedSources) > 0 {
 		source := ctx.GetSource()
 		if !contains(s.AllowedSources, source) {
-			return fmt.Errorf("%w: %s", ErrSourceNotAllowed, source)
+			return &SourceError{Source: source, Allowed: s.AllowedSources}
 		}
 	}
 
 	// Проверяем кастомную валидацию
 	if s.ValidateFunc != nil {
 		if err := s.ValidateFunc(ctx); err != nil {
-			return err
+			return &ValidationError{Err: err}
 		}
 	}
 
@@ -105,12 +138,29 @@ func (s *SecurityRule) Check(ctx core.UniversalContext) error {
 
 // HandleFailure обрабатывает ошибку безопасности
 func (s *SecurityRule) HandleFailure(ctx core.UniversalContext, err error) core.Response {
+	handler := SecurityFailureHandler(defaultSecurityFailureHandler)
 	if s.OnFailure != nil {
-		return s.OnFailure(ctx, err)
+		handler = s.OnFailure
 	}
 
-	// Дефолтный обработчик
-	return defaultSecurityFailureHandler(ctx, err)
+	if s.AlertOnCallback {
+		handler = CallbackAlertFailureHandler(handler)
+	}
+
+	return handler(ctx, err)
+}
+
+// CallbackAlertFailureHandler оборачивает обработчик: для callback запросов
+// текстовый ответ показывается всплывающим alert вместо сообщения в чат
+func CallbackAlertFailureHandler(handler SecurityFailureHandler) SecurityFailureHandler {
+	return func(ctx core.UniversalContext, err error) core.Response {
+		response := handler(ctx, err)
+		if !ctx.IsCallback() || response == nil || response.Type() != core.ResponseTypeMessage {
+			return response
+		}
+
+		return core.NewCallbackAnswer(response.Content().Text, true)
+	}
 }
 
 // Errors
@@ -122,128 +172,38 @@ var (
 	ErrSourceNotAllowed       = errors.New("source not allowed")
 	ErrRateLimitExceeded      = errors.New("rate limit exceeded")
 	ErrValidationFailed       = errors.New("validation failed")
+	ErrChatTypeNotAllowed     = errors.New("chat type not allowed")
+	ErrChatAdminRequired      = errors.New("chat admin required")
+	ErrNotAddressed           = errors.New("message not addressed to bot")
 )
 
 // defaultSecurityFailureHandler дефолтный обработчик ошибок безопасности
 func defaultSecurityFailureHandler(ctx core.UniversalContext, err error) core.Response {
-	var message string
-
-	switch {
-	case errors.Is(err, ErrNotAuthenticated):
-		message = "❌ Требуется авторизация"
-	case errors.Is(err, ErrProfileRequired):
-		message = "❌ Требуется регистрация"
-	case errors.Is(err, ErrInsufficientRole):
-		message = "❌ Недостаточно прав для выполнения действия"
-	case errors.Is(err, ErrInsufficientPermission):
-		message = "❌ Нет доступа к этой функции"
-	case errors.Is(err, ErrSourceNotAllowed):
-		message = "❌ Действие недоступно из этого источника"
-	case errors.Is(err, ErrRateLimitExceeded):
-		message = "⏱ Слишком много запросов. Попробуйте позже"
-	default:
-		message = fmt.Sprintf("❌ Ошибка безопасности: %v", err)
+	// Сообщения, адресованные не боту, молча игнорируем
+	if errors.Is(err, ErrNotAddressed) {
+		return core.NewSilentResponse()
 	}
 
-	return core.NewMessage(message)
-}
-
-// RateLimiter реализация ограничителя скорости
-type RateLimiter struct {
-	config   *RateLimitConfig
-	storage  core.Storage
-	mu       sync.RWMutex
-	counters map[string]*rateLimitCounter
-}
-
-// rateLimitCounter счетчик для rate limiting
-type rateLimitCounter struct {
-	Count     int
-	ResetAt   time.Time
-	UpdatedAt time.Time
+	return core.NewMessage(SecurityFailureMessage(ctx, err))
 }
 
-// NewRateLimiter создает новый ограничитель
-func NewRateLimiter(config *RateLimitConfig, storage core.Storage) *RateLimiter {
-	if config.Strategy == "" {
-		config.Strategy = "sliding_window"
+// publishSecurityDenied публикует событие security.denied
+// Подписчики обрабатывают его асинхронно, поэтому отмена запроса на них не влияет
+func publishSecurityDenied(bus core.EventBus, ctx core.UniversalContext, module, route string, err error) {
+	if bus == nil || errors.Is(err, ErrNotAddressed) {
+		return
 	}
 
-	return &RateLimiter{
-		config:   config,
-		storage:  storage,
-		counters: make(map[string]*rateLimitCounter),
-	}
-}
-
-// Allow проверяет, разрешен ли запрос
-func (r *RateLimiter) Allow(ctx core.UniversalContext) bool {
-	key := r.getKey(ctx)
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	counter, exists := r.counters[key]
-	now := time.Now()
-
-	// Создаем новый счетчик если не существует
-	if !exists || now.After(counter.ResetAt) {
-		r.counters[key] = &rateLimitCounter{
-			Count:     1,
-			ResetAt:   now.Add(time.Duration(r.config.Window) * time.Second),
-			UpdatedAt: now,
-		}
-		return true
-	}
-
-	// Проверяем лимит
-	if counter.Count >= r.config.Requests {
-		return false
-	}
-
-	// Увеличиваем счетчик
-	counter.Count++
-	counter.UpdatedAt = now
-
-	return true
-}
-
-// Reset сбрасывает лимит для пользователя
-func (r *RateLimiter) Reset(userID int64) error {
-	key := fmt.Sprintf("user:%d", userID)
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	delete(r.counters, key)
-	return nil
-}
-
-// GetLimit возвращает текущий лимит
-func (r *RateLimiter) GetLimit(userID int64) (current, max int, resetAt int64) {
-	key := fmt.Sprintf("user:%d", userID)
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	counter, exists := r.counters[key]
-	if !exists {
-		return 0, r.config.Requests, 0
-	}
-
-	return counter.Count, r.config.Requests, counter.ResetAt.Unix()
-}
-
-// getKey получает ключ для rate limiting
-func (r *RateLimiter) getKey(ctx core.UniversalContext) string {
-	// Используем комбинацию user_id и chat_id для ключа
-	return fmt.Sprintf("user:%d:chat:%d", ctx.GetUserID(), ctx.GetChatID())
+	event := events.NewSecurityDeniedEvent(ctx.GetUserID(), ctx.GetChatID(), module, route, securityReason(err), err.Error())
+	event.SetData("source", ctx.GetSource())
+	bus.PublishAsync(context.WithoutCancel(requestContext(ctx)), event)
 }
 
 // SecurityMiddleware middleware для проверки безопасности
 type SecurityMiddleware struct {
 	rule        SecurityRule
 	rateLimiter *RateLimiter
+	eventBus    core.EventBus
 }
 
 // NewSecurityMiddleware создает новый middleware безопасности
@@ -259,6 +219,11 @@ func NewSecurityMiddleware(rule SecurityRule) *SecurityMiddleware {
 	}
 }
 
+// SetEventBus включает публикацию событий security.denied
+func (m *SecurityMiddleware) SetEventBus(bus core.EventBus) {
+	m.eventBus = bus
+}
+
 // Name возвращает имя middleware
 func (m *SecurityMiddleware) Name() string {
 	return "security"
@@ -272,19 +237,27 @@ func (m *SecurityMiddleware) Priority() int {
 // Process обрабатывает запрос
 func (m *SecurityMiddleware) Process(ctx core.UniversalContext, next core.HandlerFunc) core.Response {
 	// Проверяем rate limit
-	if m.rateLimiter != nil && !m.rateLimiter.Allow(ctx) {
-		return m.rule.HandleFailure(ctx, ErrRateLimitExceeded)
+	if m.rateLimiter != nil {
+		if state := m.rateLimiter.Take(ctx); !state.Allowed {
+			return m.deny(ctx, &RateLimitError{State: state})
+		}
 	}
 
 	// Проверяем правила безопасности
 	if err := m.rule.Check(ctx); err != nil {
-		return m.rule.HandleFailure(ctx, err)
+		return m.deny(ctx, err)
 	}
 
 	// Продолжаем цепочку
 	return next(ctx)
 }
 
+// deny публикует событие и возвращает ответ об отказе
+func (m *SecurityMiddleware) deny(ctx core.UniversalContext, err error) core.Response {
+	publishSecurityDenied(m.eventBus, ctx, "", "", err)
+	return m.rule.HandleFailure(ctx, err)
+}
+
 // Helper functions
 
 func hasAnyRole(userRoles, requiredRoles []string) bool {
@@ -298,6 +271,15 @@ func hasAnyRole(userRoles, requiredRoles []string) bool {
 	return false
 }
 
+func containsChatType(types []core.ChatType, t core.ChatType) bool {
+	for _, allowed := range types {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
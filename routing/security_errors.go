@@ -0,0 +1,262 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// RoleError не хватает роли
+type RoleError struct {
+	// Required требуемые роли (достаточно любой)
+	Required []string
+
+	// Actual роли пользователя
+	Actual []string
+}
+
+// Error возвращает текст ошибки
+func (e *RoleError) Error() string {
+	return fmt.Sprintf("%s: requires one of [%s]", ErrInsufficientRole, strings.Join(e.Required, ", "))
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrInsufficientRole)
+func (e *RoleError) Unwrap() error {
+	return ErrInsufficientRole
+}
+
+// PermissionError не хватает права
+type PermissionError struct {
+	// Permission отсутствующее право
+	Permission string
+}
+
+// Error возвращает текст ошибки
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInsufficientPermission, e.Permission)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrInsufficientPermission)
+func (e *PermissionError) Unwrap() error {
+	return ErrInsufficientPermission
+}
+
+// SourceError запрос из неразрешенного источника
+type SourceError struct {
+	// Source источник запроса
+	Source string
+
+	// Allowed разрешенные источники
+	Allowed []string
+}
+
+// Error возвращает текст ошибки
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSourceNotAllowed, e.Source)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrSourceNotAllowed)
+func (e *SourceError) Unwrap() error {
+	return ErrSourceNotAllowed
+}
+
+// ChatTypeError запрос из неразрешенного типа чата
+type ChatTypeError struct {
+	// ChatType тип чата запроса
+	ChatType core.ChatType
+
+	// Allowed разрешенные типы чатов
+	Allowed []core.ChatType
+}
+
+// Error возвращает текст ошибки
+func (e *ChatTypeError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrChatTypeNotAllowed, e.ChatType)
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrChatTypeNotAllowed)
+func (e *ChatTypeError) Unwrap() error {
+	return ErrChatTypeNotAllowed
+}
+
+// ValidationError ошибка кастомной валидации
+type ValidationError struct {
+	Err error
+}
+
+// Error возвращает текст ошибки
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrValidationFailed, e.Err)
+}
+
+// Unwrap позволяет проверять как ErrValidationFailed, так и исходную ошибку
+func (e *ValidationError) Unwrap() []error {
+	return []error{ErrValidationFailed, e.Err}
+}
+
+// SecurityMessages тексты ответов при отказе в доступе
+type SecurityMessages struct {
+	NotAuthenticated       string
+	ProfileRequired        string
+	InsufficientRole       string
+	InsufficientPermission string
+	SourceNotAllowed       string
+	GroupOnly              string
+	PrivateOnly            string
+	ChatAdminRequired      string
+
+	// RateLimited без времени ожидания
+	RateLimited string
+
+	// RateLimitedRetry с временем ожидания (%d - секунды)
+	RateLimitedRetry string
+
+	// ValidationFailed ошибка валидации (%v - текст ошибки)
+	ValidationFailed string
+
+	// Generic прочие ошибки (%v - текст ошибки)
+	Generic string
+}
+
+// DefaultSecurityMessages тексты по умолчанию
+var DefaultSecurityMessages = SecurityMessages{
+	NotAuthenticated:       "❌ Требуется авторизация",
+	ProfileRequired:        "❌ Требуется регистрация",
+	InsufficientRole:       "❌ Недостаточно прав для выполнения действия",
+	InsufficientPermission: "❌ Нет доступа к этой функции",
+	SourceNotAllowed:       "❌ Действие недоступно из этого источника",
+	GroupOnly:              "❌ Команда доступна только в группах",
+	PrivateOnly:            "❌ Команда доступна только в личных сообщениях",
+	ChatAdminRequired:      "❌ Команда доступна только администраторам чата",
+	RateLimited:            "⏱ Слишком много запросов. Попробуйте позже",
+	RateLimitedRetry:       "⏱ Слишком много запросов. Попробуйте через %d сек.",
+	ValidationFailed:       "❌ %v",
+	Generic:                "❌ Ошибка безопасности: %v",
+}
+
+// EnglishSecurityMessages английские тексты
+var EnglishSecurityMessages = SecurityMessages{
+	NotAuthenticated:       "❌ Authentication required",
+	ProfileRequired:        "❌ Registration required",
+	InsufficientRole:       "❌ You don't have enough rights for this action",
+	InsufficientPermission: "❌ You don't have access to this feature",
+	SourceNotAllowed:       "❌ This action is not available from here",
+	GroupOnly:              "❌ This command is only available in groups",
+	PrivateOnly:            "❌ This command is only available in private messages",
+	ChatAdminRequired:      "❌ This command is only available to chat admins",
+	RateLimited:            "⏱ Too many requests. Please try again later",
+	RateLimitedRetry:       "⏱ Too many requests. Try again in %d s.",
+	ValidationFailed:       "❌ %v",
+	Generic:                "❌ Security error: %v",
+}
+
+var (
+	securityMessagesMu sync.RWMutex
+	securityMessages   = map[string]SecurityMessages{
+		"ru": DefaultSecurityMessages,
+		"en": EnglishSecurityMessages,
+	}
+)
+
+// RegisterSecurityMessages регистрирует тексты для локали (например, "de" или "pt-BR")
+func RegisterSecurityMessages(locale string, messages SecurityMessages) {
+	securityMessagesMu.Lock()
+	defer securityMessagesMu.Unlock()
+
+	securityMessages[strings.ToLower(locale)] = messages
+}
+
+// SecurityMessagesFor возвращает тексты для локали
+// Сначала ищется точная локаль, затем язык ("en-US" -> "en"), иначе тексты по умолчанию
+func SecurityMessagesFor(locale string) SecurityMessages {
+	securityMessagesMu.RLock()
+	defer securityMessagesMu.RUnlock()
+
+	locale = strings.ToLower(locale)
+	if messages, ok := securityMessages[locale]; ok {
+		return messages
+	}
+
+	if idx := strings.IndexAny(locale, "-_"); idx > 0 {
+		if messages, ok := securityMessages[locale[:idx]]; ok {
+			return messages
+		}
+	}
+
+	return DefaultSecurityMessages
+}
+
+// SecurityFailureMessage возвращает локализованный текст отказа для ошибки
+func SecurityFailureMessage(ctx core.UniversalContext, err error) string {
+	messages := SecurityMessagesFor(ctx.GetLocale())
+
+	var (
+		limitErr      *RateLimitError
+		chatTypeErr   *ChatTypeError
+		validationErr *ValidationError
+	)
+
+	switch {
+	case errors.Is(err, ErrNotAuthenticated):
+		return messages.NotAuthenticated
+	case errors.Is(err, ErrProfileRequired):
+		return messages.ProfileRequired
+	case errors.Is(err, ErrInsufficientRole):
+		return messages.InsufficientRole
+	case errors.Is(err, ErrInsufficientPermission):
+		return messages.InsufficientPermission
+	case errors.Is(err, ErrSourceNotAllowed):
+		return messages.SourceNotAllowed
+	case errors.As(err, &chatTypeErr):
+		if containsChatType(chatTypeErr.Allowed, core.ChatTypePrivate) {
+			return messages.PrivateOnly
+		}
+		return messages.GroupOnly
+	case errors.Is(err, ErrChatTypeNotAllowed):
+		if ctx.GetChatType() == core.ChatTypePrivate {
+			return messages.GroupOnly
+		}
+		return messages.PrivateOnly
+	case errors.Is(err, ErrChatAdminRequired):
+		return messages.ChatAdminRequired
+	case errors.As(err, &limitErr) && limitErr.State.RetryAfter > 0:
+		return fmt.Sprintf(messages.RateLimitedRetry, limitErr.State.RetryAfterSeconds())
+	case errors.Is(err, ErrRateLimitExceeded):
+		return messages.RateLimited
+	case errors.As(err, &validationErr):
+		return fmt.Sprintf(messages.ValidationFailed, validationErr.Err)
+	default:
+		return fmt.Sprintf(messages.Generic, err)
+	}
+}
+
+// securityReason возвращает код причины отказа для событий
+func securityReason(err error) string {
+	switch {
+	case errors.Is(err, ErrNotAuthenticated):
+		return "not_authenticated"
+	case errors.Is(err, ErrProfileRequired):
+		return "profile_required"
+	case errors.Is(err, ErrInsufficientRole):
+		return "insufficient_role"
+	case errors.Is(err, ErrInsufficientPermission):
+		return "insufficient_permission"
+	case errors.Is(err, ErrSourceNotAllowed):
+		return "source_not_allowed"
+	case errors.Is(err, ErrChatTypeNotAllowed):
+		return "chat_type_not_allowed"
+	case errors.Is(err, ErrChatAdminRequired):
+		return "chat_admin_required"
+	case errors.Is(err, ErrNotAddressed):
+		return "not_addressed"
+	case errors.Is(err, ErrRateLimitExceeded):
+		return "rate_limited"
+	case errors.Is(err, ErrValidationFailed):
+		return "validation_failed"
+	default:
+		return "unknown"
+	}
+}
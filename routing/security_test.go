@@ -0,0 +1,57 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// contextBus core.EventBus, запоминающий контексты асинхронных публикаций
+type contextBus struct {
+	mu       sync.Mutex
+	contexts []context.Context
+}
+
+func (b *contextBus) Subscribe(eventType string, handler core.EventHandlerFunc) error   { return nil }
+func (b *contextBus) Unsubscribe(eventType string, handler core.EventHandlerFunc) error { return nil }
+func (b *contextBus) Start(ctx context.Context) error                                   { return nil }
+func (b *contextBus) Stop(ctx context.Context) error                                    { return nil }
+func (b *contextBus) Publish(ctx context.Context, event core.Event) error               { return nil }
+
+func (b *contextBus) PublishAsync(ctx context.Context, event core.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.contexts = append(b.contexts, ctx)
+}
+
+func TestPublishSecurityDeniedOutlivesRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		published bool
+	}{
+		{name: "denied", err: errors.New("forbidden"), published: true},
+		{name: "not addressed", err: ErrNotAddressed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestCtx, cancel := context.WithCancel(context.Background())
+			ctx := core.NewBaseContext(requestCtx)
+			cancel()
+
+			bus := &contextBus{}
+			publishSecurityDenied(bus, ctx, "arena", "/duel", tt.err)
+
+			if published := len(bus.contexts) == 1; published != tt.published {
+				t.Fatalf("published = %v, want %v", published, tt.published)
+			}
+			if tt.published && bus.contexts[0].Err() != nil {
+				t.Fatalf("event context: %v", bus.contexts[0].Err())
+			}
+		})
+	}
+}
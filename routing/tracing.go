@@ -0,0 +1,32 @@
+package routing
+
+import (
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/tracing"
+)
+
+// SetTracer включает трассировку запросов: span каждого middleware ("middleware <name>"),
+// поиска маршрута ("router.match") и обработчика ("handler <module>")
+// Span становятся дочерними для span адаптера из ctx.Context()
+func (r *Router) SetTracer(tracer *tracing.Tracer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tracer = tracer
+}
+
+// startSpan начинает дочерний span запроса и делает его текущим в ctx.Context()
+// Возвращает функцию завершения, которая восстанавливает прежний контекст
+func startSpan(tracer *tracing.Tracer, ctx core.UniversalContext, name string, attrs ...tracing.Attribute) (*tracing.Span, func()) {
+	if tracer == nil {
+		return nil, func() {}
+	}
+
+	parent := ctx.Context()
+	spanCtx, span := tracer.Start(requestContext(ctx), name, tracing.WithAttributes(attrs...))
+	ctx.WithContext(spanCtx)
+
+	return span, func() {
+		span.End()
+		ctx.WithContext(parent)
+	}
+}
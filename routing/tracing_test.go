@@ -0,0 +1,110 @@
+package routing
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/andranikuz/botkit/core"
+	"github.com/andranikuz/botkit/events"
+	"github.com/andranikuz/botkit/logging"
+	"github.com/andranikuz/botkit/tracing"
+)
+
+// pingModule модуль, публикующий асинхронное событие из обработчика
+type pingModule struct {
+	bus core.EventBus
+}
+
+func (m *pingModule) Name() string                      { return "arena" }
+func (m *pingModule) Version() string                   { return "1.0.0" }
+func (m *pingModule) Init(deps core.Dependencies) error { return nil }
+func (m *pingModule) Start(ctx context.Context) error   { return nil }
+func (m *pingModule) Stop(ctx context.Context) error    { return nil }
+
+func (m *pingModule) Routes() []core.RoutePattern {
+	return []core.RoutePattern{
+		RoutePattern{
+			Patterns: []string{"/ping"},
+			Type:     RouteTypeCommand,
+			Handler: func(ctx core.UniversalContext) core.Response {
+				m.bus.PublishAsync(ctx.Context(), events.NewEvent("arena.pinged", "arena"))
+				return core.NewMessage("pong")
+			},
+		},
+	}
+}
+
+func TestRequestTrace(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	tracer := tracing.NewTracer("test", exporter)
+	logger := logging.New(logging.Options{Output: io.Discard})
+
+	bus := events.NewEventBus(logger, nil)
+	bus.SetTracer(tracer)
+
+	handled := make(chan struct{})
+	if err := bus.Subscribe("arena.pinged", func(ctx context.Context, event core.Event) error {
+		close(handled)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouter(bus, logger, nil)
+	r.SetTracer(tracer)
+	if err := r.RegisterModule(&pingModule{bus: bus}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Span адаптера - корень трассы запроса
+	adapterCtx, adapterSpan := tracer.Start(context.Background(), "http.execute")
+	ctx := core.NewBaseContext(adapterCtx)
+	ctx.SetText("/ping")
+	ctx.SetIsCommand(true)
+	r.Route(ctx)
+	adapterSpan.End()
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("event subscriber was not called")
+	}
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := make(map[string]tracing.SpanData)
+	for _, span := range exporter.Trace(adapterSpan.SpanContext().TraceID) {
+		spans[span.Name] = span
+	}
+
+	tests := []struct {
+		span   string
+		parent string
+	}{
+		{span: "router.match", parent: "http.execute"},
+		{span: "handler arena", parent: "http.execute"},
+		{span: "event arena.pinged", parent: "handler arena"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.span, func(t *testing.T) {
+			span, ok := spans[tt.span]
+			if !ok {
+				t.Fatalf("span %q is not in the request trace", tt.span)
+			}
+			if parent := spans[tt.parent].SpanContext; span.Parent != parent {
+				t.Fatalf("span %q parent = %+v, want %q %+v", tt.span, span.Parent, tt.parent, parent)
+			}
+		})
+	}
+
+	if route, _ := spans["router.match"].Attribute("route"); route != "/ping" {
+		t.Fatalf("router.match route = %v, want /ping", route)
+	}
+}
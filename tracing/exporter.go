@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/andranikuz/botkit/core"
+)
+
+// InMemoryExporter хранит завершенные span в памяти (для тестов и отладки)
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter создает экспортер в память
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpans сохраняет span
+func (e *InMemoryExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// Spans возвращает сохраненные span в порядке завершения
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Trace возвращает span одной трассы в порядке завершения
+func (e *InMemoryExporter) Trace(id TraceID) []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var result []SpanData
+	for _, span := range e.spans {
+		if span.SpanContext.TraceID == id {
+			result = append(result, span)
+		}
+	}
+	return result
+}
+
+// Reset удаляет сохраненные span
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// LogExporter пишет завершенные span в лог (уровень Debug)
+type LogExporter struct {
+	logger core.Logger
+}
+
+// NewLogExporter создает экспортер в лог
+func NewLogExporter(logger core.Logger) *LogExporter {
+	return &LogExporter{logger: logger}
+}
+
+// ExportSpans пишет span в лог
+func (e *LogExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	for _, span := range spans {
+		fields := []interface{}{
+			"trace_id", span.SpanContext.TraceID.String(),
+			"span_id", span.SpanContext.SpanID.String(),
+			"duration_ms", float64(span.Duration().Microseconds()) / 1000,
+			"status", span.Status.String(),
+		}
+		if span.Parent.IsValid() {
+			fields = append(fields, "parent_id", span.Parent.SpanID.String())
+		}
+		for _, attr := range span.Attributes {
+			fields = append(fields, attr.Key, attr.Value)
+		}
+		e.logger.Debug("Span "+span.Name, fields...)
+	}
+	return nil
+}
+
+// TraceparentHeader заголовок W3C Trace Context
+const TraceparentHeader = "traceparent"
+
+// Traceparent возвращает значение заголовка traceparent для текущего span
+// ("" - трассы нет): 00-<trace id>-<span id>-01
+func Traceparent(ctx context.Context) string {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID, sc.SpanID)
+}
+
+// ContextWithTraceparent возвращает контекст с внешним родителем из заголовка traceparent
+// Некорректный заголовок игнорируется
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// ParseTraceparent разбирает заголовок traceparent
+func ParseTraceparent(header string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", header)
+	}
+
+	var sc SpanContext
+	if len(parts[1]) != 32 || !decodeHex(sc.TraceID[:], parts[1]) {
+		return SpanContext{}, fmt.Errorf("invalid trace id in traceparent %q", header)
+	}
+	if len(parts[2]) != 16 || !decodeHex(sc.SpanID[:], parts[2]) {
+		return SpanContext{}, fmt.Errorf("invalid span id in traceparent %q", header)
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", header)
+	}
+
+	sc.Remote = true
+	return sc, nil
+}
+
+// decodeHex декодирует hex-строку длины 2*len(dst)
+func decodeHex(dst []byte, s string) bool {
+	n, err := hex.Decode(dst, []byte(s))
+	return err == nil && n == len(dst)
+}
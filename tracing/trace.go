@@ -0,0 +1,363 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TraceID идентификатор трассы (16 байт, как в OpenTelemetry и W3C Trace Context)
+type TraceID [16]byte
+
+// String возвращает идентификатор в hex
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid проверяет, что идентификатор не нулевой
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// SpanID идентификатор span (8 байт)
+type SpanID [8]byte
+
+// String возвращает идентификатор в hex
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid проверяет, что идентификатор не нулевой
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext идентификаторы span, передаваемые между компонентами и процессами
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+
+	// Remote контекст получен извне (заголовок traceparent)
+	Remote bool
+}
+
+// IsValid проверяет, что контекст содержит трассу и span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// StatusCode статус span
+type StatusCode int
+
+const (
+	// StatusUnset статус не задан (по умолчанию)
+	StatusUnset StatusCode = iota
+	// StatusOK операция завершилась успешно
+	StatusOK
+	// StatusError операция завершилась ошибкой
+	StatusError
+)
+
+// String возвращает название статуса
+func (c StatusCode) String() string {
+	switch c {
+	case StatusOK:
+		return "ok"
+	case StatusError:
+		return "error"
+	}
+	return "unset"
+}
+
+// Attribute атрибут span или события
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr создает атрибут
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Event событие внутри span (например, ошибка)
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []Attribute
+}
+
+// SpanData завершенный span, передаваемый экспортеру
+type SpanData struct {
+	Name          string
+	SpanContext   SpanContext
+	Parent        SpanContext
+	Tracer        string
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    []Attribute
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Duration возвращает длительность span
+func (d SpanData) Duration() time.Duration {
+	return d.EndTime.Sub(d.StartTime)
+}
+
+// Attribute возвращает значение атрибута
+func (d SpanData) Attribute(key string) (interface{}, bool) {
+	for i := len(d.Attributes) - 1; i >= 0; i-- {
+		if d.Attributes[i].Key == key {
+			return d.Attributes[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Exporter получает завершенные span (аналог SpanExporter в OpenTelemetry)
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+}
+
+// Tracer создает span и передает завершенные span экспортеру
+//
+// Методы nil-трейсера безопасны: Start возвращает исходный контекст и nil-span,
+// методы nil-span ничего не делают. Компоненты без SetTracer не тратят время на трассировку
+type Tracer struct {
+	name     string
+	exporter Exporter
+}
+
+// NewTracer создает трейсер
+// name - имя инструментирующего компонента (например, "botkit")
+func NewTracer(name string, exporter Exporter) *Tracer {
+	return &Tracer{
+		name:     name,
+		exporter: exporter,
+	}
+}
+
+// SpanOption параметр нового span
+type SpanOption func(*Span)
+
+// WithAttributes задает атрибуты нового span
+func WithAttributes(attrs ...Attribute) SpanOption {
+	return func(s *Span) {
+		s.data.Attributes = append(s.data.Attributes, attrs...)
+	}
+}
+
+// Start создает span - дочерний для span из ctx (или новую трассу)
+// и возвращает контекст с ним. Span нужно завершить вызовом End
+func (t *Tracer) Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:      name,
+			Parent:    parent,
+			Tracer:    t.name,
+			StartTime: time.Now(),
+		},
+	}
+
+	span.data.SpanContext.SpanID = newSpanID()
+	if parent.TraceID.IsValid() {
+		span.data.SpanContext.TraceID = parent.TraceID
+	} else {
+		span.data.SpanContext.TraceID = newTraceID()
+	}
+
+	for _, opt := range opts {
+		opt(span)
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Span операция в трассе
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext возвращает идентификаторы span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// IsRecording проверяет, что span записывается (не nil и не завершен)
+func (s *Span) IsRecording() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+// SetName изменяет имя span
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttributes добавляет атрибуты (атрибут с тем же ключом заменяется)
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+
+	for _, attr := range attrs {
+		replaced := false
+		for i := range s.data.Attributes {
+			if s.data.Attributes[i].Key == attr.Key {
+				s.data.Attributes[i].Value = attr.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.data.Attributes = append(s.data.Attributes, attr)
+		}
+	}
+}
+
+// AddEvent добавляет событие
+func (s *Span) AddEvent(name string, attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attributes: attrs})
+}
+
+// RecordError добавляет событие "exception" и устанавливает статус ошибки
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.AddEvent("exception", Attr("exception.message", err.Error()), Attr("exception.type", fmt.Sprintf("%T", err)))
+	s.SetStatus(StatusError, err.Error())
+}
+
+// SetStatus устанавливает статус span
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+
+	s.data.Status = code
+	s.data.StatusMessage = message
+}
+
+// End завершает span и передает его экспортеру (повторные вызовы игнорируются)
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.tracer.exporter != nil {
+		_ = s.tracer.exporter.ExportSpans(context.Background(), []SpanData{data})
+	}
+}
+
+// spanKey ключ span в context.Context
+type spanKey struct{}
+
+// remoteKey ключ внешнего SpanContext в context.Context
+type remoteKey struct{}
+
+// ContextWithSpan возвращает контекст с span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// ContextWithRemoteSpanContext возвращает контекст с внешним родителем
+// (следующий span продолжит трассу другого процесса)
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanFromContext возвращает текущий span (nil, если его нет)
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext возвращает идентификаторы текущего span
+// или внешнего родителя (пустой SpanContext, если трассы нет)
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// newTraceID генерирует идентификатор трассы
+func newTraceID() TraceID {
+	var id TraceID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+// newSpanID генерирует идентификатор span
+func newSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		valid  bool
+		trace  string
+		span   string
+	}{
+		{
+			name:   "valid",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			valid:  true,
+			trace:  "4bf92f3577b34da6a3ce929d0e0e4736",
+			span:   "00f067aa0ba902b7",
+		},
+		{
+			name:   "surrounding spaces",
+			header: " 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00 ",
+			valid:  true,
+			trace:  "4bf92f3577b34da6a3ce929d0e0e4736",
+			span:   "00f067aa0ba902b7",
+		},
+		{name: "empty", header: ""},
+		{name: "forbidden version", header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "missing flags", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"},
+		{name: "short trace id", header: "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01"},
+		{name: "short span id", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01"},
+		{name: "not hex", header: "00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "zero trace id", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "zero span id", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.header)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ParseTraceparent(%q) error = %v, want valid %v", tt.header, err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+			if sc.TraceID.String() != tt.trace || sc.SpanID.String() != tt.span || !sc.Remote {
+				t.Fatalf("ParseTraceparent(%q) = %+v", tt.header, sc)
+			}
+		})
+	}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer("test", exporter)
+
+	ctx, span := tracer.Start(context.Background(), "http.execute")
+	header := Traceparent(ctx)
+	span.End()
+
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatalf("ParseTraceparent(%q): %v", header, err)
+	}
+	if sc.TraceID != span.SpanContext().TraceID || sc.SpanID != span.SpanContext().SpanID {
+		t.Fatalf("ParseTraceparent(%q) = %+v, want %+v", header, sc, span.SpanContext())
+	}
+
+	// Другой процесс продолжает трассу из заголовка
+	remote := ContextWithTraceparent(context.Background(), header)
+	_, child := tracer.Start(remote, "telegram.update")
+	child.End()
+
+	data := exporter.Spans()[1]
+	if data.SpanContext.TraceID != sc.TraceID || data.Parent.SpanID != sc.SpanID || !data.Parent.Remote {
+		t.Fatalf("child of remote parent = %+v, want parent %+v", data, sc)
+	}
+
+	if got := Traceparent(context.Background()); got != "" {
+		t.Fatalf("Traceparent without trace = %q, want empty", got)
+	}
+	if got := ContextWithTraceparent(context.Background(), "invalid"); SpanContextFromContext(got).IsValid() {
+		t.Fatal("invalid header produced a parent")
+	}
+}
+
+func TestTracerParentChain(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer("test", exporter)
+
+	rootCtx, root := tracer.Start(context.Background(), "root")
+	childCtx, child := tracer.Start(rootCtx, "child")
+	_, grandchild := tracer.Start(childCtx, "grandchild")
+	grandchild.End()
+	child.End()
+	root.End()
+	root.End()
+
+	spans := exporter.Trace(root.SpanContext().TraceID)
+	if len(spans) != 3 {
+		t.Fatalf("trace has %d spans, want 3 (End is idempotent)", len(spans))
+	}
+
+	parents := map[string]SpanContext{
+		"grandchild": child.SpanContext(),
+		"child":      root.SpanContext(),
+		"root":       {},
+	}
+	for _, span := range spans {
+		if span.Parent != parents[span.Name] {
+			t.Fatalf("span %q parent = %+v, want %+v", span.Name, span.Parent, parents[span.Name])
+		}
+	}
+
+	_, other := tracer.Start(context.Background(), "other")
+	if other.SpanContext().TraceID == root.SpanContext().TraceID {
+		t.Fatal("span without parent joined an existing trace")
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+
+	ctx := context.Background()
+	spanCtx, span := tracer.Start(ctx, "noop")
+	if spanCtx != ctx || span != nil {
+		t.Fatalf("nil tracer Start = %v, %v, want original context and nil span", spanCtx, span)
+	}
+
+	span.SetAttributes(Attr("key", "value"))
+	span.AddEvent("event")
+	span.RecordError(nil)
+	span.SetStatus(StatusError, "failed")
+	span.End()
+
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Fatal("nil span is recording")
+	}
+}